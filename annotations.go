@@ -0,0 +1,206 @@
+package main
+
+import (
+	"go/ast"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+type annotationSpec struct {
+	// Value annotation needs a value, like the type of @callback
+	Value bool
+	// Repeated annotation may be used many times, like @validate for every parameter
+	Repeated bool
+}
+
+// annotationSpecs known annotations, the same names work as //wand: directives
+var annotationSpecs = map[string]annotationSpec{
+	"subscription": {Value: true},
+	"get":          {Value: true},
+	"update":       {Value: true},
+	"callback":     {Value: true},
+	"stream":       {Value: true},
+	"validate":     {Value: true, Repeated: true},
+	"default":      {Value: true, Repeated: true},
+	"export":       {},
+	"ignore":       {},
+}
+
+// parameterAnnotations annotations of the function which name the parameter by the first word
+var parameterAnnotations = []string{"validate", "default"}
+
+const directivePrefix = "//wand:"
+
+// annotationPattern "@name", "@name: value" or "@name key=value" line of the doc comment
+var annotationPattern = regexp.MustCompile(`^@([A-Za-z][\w-]*)(:?)(.*)$`)
+
+// argumentPattern key=value argument of the annotation
+var argumentPattern = regexp.MustCompile(`^([A-Za-z_]\w*)=(.*)$`)
+
+// parseAnnotations splits the doc comment into text lines and annotations,
+// annotations and directives may be on any line
+func parseAnnotations(diag *diagnostics, group *ast.CommentGroup) ([]string, []generator.Annotation) {
+	comments := make([]string, 0, 6)
+	annotations := make([]generator.Annotation, 0, 2)
+	if group == nil {
+		return comments, annotations
+	}
+
+	for _, comment := range group.List {
+		var name, value, line string
+
+		if strings.HasPrefix(comment.Text, directivePrefix) {
+			fields := strings.SplitN(strings.TrimPrefix(comment.Text, directivePrefix), " ", 2)
+			name = fields[0]
+			if len(fields) > 1 {
+				value = strings.TrimSpace(fields[1])
+			}
+		} else {
+			line = strings.TrimLeft(strings.TrimPrefix(comment.Text, "//"), " ")
+			match := annotationPattern.FindStringSubmatch(line)
+			if match == nil {
+				comments = append(comments, line)
+				continue
+			}
+
+			name, value = match[1], strings.TrimSpace(match[3])
+			if match[2] == "" && value != "" && !strings.HasPrefix(match[3], " ") {
+				// "@name" is followed by other text, like an email
+				comments = append(comments, line)
+				continue
+			}
+		}
+
+		spec, ok := annotationSpecs[name]
+		if !ok {
+			diag.warnf(comment.Pos(), generator.DiagUnknownAnnotation, "unknown annotation @%s%s", name, suggestAnnotation(name))
+			if line != "" {
+				comments = append(comments, line)
+			}
+			continue
+		}
+
+		if spec.Value && value == "" {
+			diag.warnf(comment.Pos(), generator.DiagWrongAnnotation, "@%s is ignored, expected \"@%s: value\"", name, name)
+			continue
+		}
+
+		if !spec.Repeated && findAnnotation(annotations, name) != nil {
+			diag.warnf(comment.Pos(), generator.DiagWrongAnnotation, "@%s is repeated, the first one is used", name)
+			continue
+		}
+
+		annotations = append(annotations, generator.Annotation{
+			Name:  name,
+			Value: value,
+			Args:  parseArguments(value),
+		})
+	}
+
+	return comments, annotations
+}
+
+// parseArguments key=value words of the annotation value
+func parseArguments(value string) map[string]string {
+	var args map[string]string
+
+	for _, field := range strings.Fields(value) {
+		match := argumentPattern.FindStringSubmatch(field)
+		if match == nil {
+			continue
+		}
+
+		if args == nil {
+			args = make(map[string]string)
+		}
+		args[match[1]] = match[2]
+	}
+
+	return args
+}
+
+func findAnnotation(list []generator.Annotation, name string) *generator.Annotation {
+	for index := range list {
+		if list[index].Name == name {
+			return &list[index]
+		}
+	}
+
+	return nil
+}
+
+// addParameterAnnotations copies annotations of the function to the parameters they
+// name, like "@validate: id required" or any annotation with "param=id" argument
+func addParameterAnnotations(function *generator.FunctionData) {
+	for _, annotation := range function.Annotation {
+		name := annotation.Args["param"]
+		for _, parameterAnnotation := range parameterAnnotations {
+			if annotation.Name == parameterAnnotation {
+				name = strings.SplitN(annotation.Value, " ", 2)[0]
+			}
+		}
+
+		for i, param := range function.Params {
+			if name != "" && param.Name == name {
+				function.Params[i].Annotation = append(function.Params[i].Annotation, annotation)
+			}
+		}
+	}
+}
+
+// suggestAnnotation " (did you mean @x?)" for a typo of a known annotation
+func suggestAnnotation(name string) string {
+	names := make([]string, 0, len(annotationSpecs))
+	for annotation := range annotationSpecs {
+		names = append(names, annotation)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, annotation := range names {
+		distance := editDistance(name, annotation)
+		if distance < bestDistance {
+			best, bestDistance = annotation, distance
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return " (did you mean @" + best + "?)"
+}
+
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -6,19 +6,32 @@ import (
 	"github.com/jessevdk/go-assets"
 )
 
-var _Assets98270d80a614210b33d5a2aec48a956c8db8b424 = "\ntype {{ .Name }}Prop = {\n  {{range $index, $item := .Props}}\n  {{ $item.Name }}: {{ $item.Type }},{{end}}\n}\n\nexport function with{{ .Name }}<Props: {} & {{ .Name }}Prop>(\n  WrappedComponent : ComponentType<{{ .Name }}Prop & { {{ .VarName }} : {{ .Name }} }>\n) : ComponentType<Props> {\n  return class {{ .Name }}DataView extends Component<Props, *> {\n    {{ if .Update }}subscription = undefined {{end}}\n\n    constructor(props: Props) {\n      super(props);\n      this.state = {  {{ .VarName }} :  undefined }\n    }\n\n    componentDidMount() {\n      {{ $length := len .Get.Params }}\n      {{ if gt $length 0 }}\n      const { {{range $index, $item := .Get.Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}}} = this.props\n      {{ end }}\n\n      {{ if .Get }}\n      {{ .Get.Name }}({{range $index, $item := .Get.Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}})\n        .then(this.onValue)\n      {{ end }}\n      {{ if .Update }}\n      this.subscription = {{ .Update.Name  }}({{range $index, $item := .Get.Params}}{{ $item.Name }},{{end}}this.onValue)\n      {{ end }}\n    }\n\n    onValue = ({{ .VarName }}: {{ .Name }}) => {\n      this.setState({ {{ .VarName }} })\n    }\n\n    componentWillUnmount() {\n     {{ if .Update }}cancelSubscriptionApiCall(this.subscription)\n     {{ end }}\n    }\n\n    render() {\n      const {  {{ .VarName }}  } = this.state;\n\n      return <WrappedComponent\n                {{ .VarName }}={  {{ .VarName }}  }\n                {...this.props} />\n    }\n  }\n}\n"
-var _Assets382e2ce7c28616d04646a0aad561f67e2a6e1c39 = "/**\n * GoCall library binding\n * flow\n */\n\nimport {\n  NativeModules,\n  DeviceEventEmitter,\n  EmitterSubscription\n} from 'react-native';\n\ntype GoSubscription = {\n   subscription: EmitterSubscription,\n   name: string,\n   args: any[],\n   devId: number,\n};\n\n{{if .Dev }}\nclass RemoveDev {\n  server = \"\"\n  requestId = 1\n  call = {}\n  event = {}\n  ws: WebSocket\n  pendingList = []\n\n  constructor(server : string) {\n    this.server = server\n    this.connect()\n  }\n\n  connect = () => {\n    const ws = new WebSocket(this.server)\n    ws.onmessage = this.onMessage\n\n    this.ws = ws\n    this.ws.onopen = this.sendPending\n    ws.onclose = () => {\n       // Try to reconnect in 5 seconds\n       setTimeout(() => { this.connect()  }, 1000);\n   };\n  }\n\n  onMessage = (message: any) => {\n    const messages = message.data.split(\"\\n\")\n    messages.forEach((content: string) => {\n      const response = JSON.parse(content)\n\n      if (this.call[response.ID]) {\n        this.call[response.ID](response)\n      } else  if (response.EventName) {\n        if (this.event[response.EventName]) {\n          this.notify(this.event[response.EventName], response)\n        }\n      }\n    })\n  }\n\n  notify(subscribers, response) {\n      const keys = Object.keys(subscribers)\n      keys.forEach(key => {\n         subscribers[key](response)\n      })\n\n  }\n\n  sendPending = () => {\n    this.pendingList.forEach(it => this.ws.send(it))\n  }\n\n  callMethod = (name: string, args :any[]) : Promise<any> => {\n    return new Promise((resolve, reject) => {\n      const callData = {\n        args,\n        method: name,\n      }\n\n      const requestID = this.requestId++\n\n      this.call[requestID] = (response: any) => {\n        if (response.Success !== undefined && response.Success !== null) {\n          resolve(JSON.stringify(response.Success))\n        } else {\n          reject(JSON.stringify(response.Error))\n        }\n\n        this.call[requestID] = null\n      }\n\n      const body = JSON.stringify({id: requestID, call: callData })\n      try  {\n        this.ws.send(body)\n      } catch (err) {\n        this.pendingList = [...this.pendingList, body]\n      }\n    })\n  }\n\n  cancel = (name: string, args :any[], eventName, requestId) : GoSubscriptions => {\n    const callData = {\n      args,\n      event: name,\n    }\n\n    delete this.event[eventName][requestId]\n\n    const body = JSON.stringify({id: this.requestId, cancel: callData })\n    try  {\n      this.ws.send(body)\n    } catch (err) {\n      this.pendingList = [...this.pendingList, body]\n    }\n\n    return { name, name, devId: this.requestId }\n  }\n\n  subscribe = (name: string, args :any[], eventName: string, callback: (json : String) => void) : GoSubscriptions => {\n    const callData = {\n      args,\n      event: name,\n    }\n\n    const requestID = this.requestId++\n\n    if (!this.event[eventName]) {\n      this.event[eventName] = {}\n    }\n\n    this.event[eventName][requestID] = (response: any) => {\n      if (response.Body) {\n        callback(JSON.stringify(response.Body))\n      }\n    }\n\n    const body = JSON.stringify({id: requestID, subscribe: callData })\n    try  {\n      this.ws.send(body)\n    } catch (err) {\n      this.pendingList = [...this.pendingList, body]\n    }\n\n    return { args, name, eventName, devId: requestID }\n  }\n}\n\nconst devCall = new RemoveDev(\"ws://localhost:{{.Port}}/ws\");\n\n{{end}}\n\nfunction getName(name: string, args :any[]) : string {\n   body = args.reduce((acc:string, value: any) => {\n      if (acc == \"\") {\n         return value\n      }\n\n      return acc + \":\" + value\n   }, \"\")\n\n   return `${name}:${body}`\n}\n\nexport function subribeApiCall(name: string, args :any[], callback: (json : String) => void) : GoSubscription {\n   {{if .Dev}}\n   const subscriptionName = getName(name, args)\n   return devCall.subscribe(name, args, subscriptionName, callback)\n   {{else}}\n   const subscriptionName = getName(name, args)\n   subscription = DeviceEventEmitter.addListener(subscriptionName, callback);\n\n   const callData = JSON.stringify({\n      args,\n      event: name,\n   })\n\n   NativeModules.GoCall.subscribe(callData)\n   \n   return {\n      args,\n      name,\n      subscription,\n   }\n   {{end}}\n}\n\nexport async function cancelSubscriptionApiCall(subs : GoSubscription) : Promise<any> {\n  {{if .Dev}} \n  const {name, args, requestId, eventName, subscription} = subs\n  return devCall.cancel(name, args, eventName, requestId)\n\n  {{else}}\n  const {name, args, subscription} = subs\n\n  const callData = JSON.stringify({\n    args,\n    event: name,\n  })\n\n  subscription.remove()\n\n  return NativeModules.GoCall.cancel(callData)\n  {{end}}\n}\n\nexport async function runApiCall(name: string, args :any[]) : Promise<any> {\n   {{if .Dev}} \n    return devCall.callMethod(name, args)\n   {{else}}\n    const callData = JSON.stringify({\n      args,\n      method: name,\n    })\n\n    return NativeModules.GoCall.callMethod(callData)\n   {{end}}\n}"
-var _Assets2b7ef9c09e8e120a968348f6a0414b24b022fd53 = "\nfunc init() {\n    {{range $_, $item := .Functions}}\n    {{ if $item.Subscription }}registry.RegisterSubscription(\"{{ $item.CallName }}\", subscribeTo{{ $item.Name }}, subscriptionTypes{{ $item.Name }} ){{ else }}registry.RegisterFunction(\"{{ $item.CallName }}\", callAdapterFor{{ $item.Name }}){{ end }}{{end}}\n}\n"
-var _Assets1ad8fcf1a012f1e976cdf59512da19e040972051 = "/** {{range $_, $item := .Comments}}\n * {{ $item }}{{end}}\n */\nexport type {{ .Name }} = { {{range $_, $item := .Field}} {{range $_, $comment := $item.Comment }}\n    // {{ $comment }} {{end}}\n    {{ $item.Name }}: {{ $item.Type }}, {{end}}\n}\n"
+var _Assets2b7ef9c09e8e120a968348f6a0414b24b022fd53 = "\nfunc init() {\n    {{range $_, $item := .Functions}}\n    {{ if $item.Subscription }}registry.RegisterNamedSubscription(\"{{ $item.CallName }}\", subscribeTo{{ $item.Name }}, subscriptionTypes{{ $item.Name }} ){{ else if $item.Stream }}registry.RegisterStream(\"{{ $item.CallName }}\", streamAdapterFor{{ $item.Name }}){{ else }}registry.RegisterFunction(\"{{ $item.CallName }}\", callAdapterFor{{ $item.Name }}){{ end }}{{end}}\n    {{range $_, $item := .Pure}}\n    registry.RegisterFunction(\"{{ $item.CallName }}\", callAdapterFor{{ $item.Name }}){{end}}\n}\n"
+var _Assets3b4dd3b006d667b8c4ecbff30ccd3c1161228224 = "\n{{define \"typeCast\"}}\n   {{if eq .Type \"string\" -}} \n      if arg == nil {\n         return \"\", errors.New(\"wrong type\") \n      }  \n         \n      str, ok := arg.(string)\n      if !ok {\n         num, ok := arg.(float64) \n         if ok {\n            return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil\n         }\n\n         return \"\", errors.New(\"wrong type, string is expected ({{ .Name  }})\")\n      }\n\n      return str, nil\n   {{- else if eq .Type \"int\" -}} \n      if arg == nil {\n         return 0, errors.New(\"wrong type\") \n      }\n            \n      str, ok := arg.(string) \n      if ok {\n         return strconv.Atoi(str)\n      }\n      fl, ok := arg.(float64)\n      if ok {\n         return int(fl), nil\n      }\n\n      return arg.(int), nil\n   {{- else if eq .Type \"float32\" -}} \n      if arg == nil {\n         return 0, errors.New(\"wrong type\") \n      }\n\n      str, ok := arg.(string) \n      if ok {\n         fl, err := strconv.ParseFloat(str, 32) \n         if err != nil {\n            return 0, errors.New(\"invalid data\")\n         }\n         return float32(fl), nil\n      }\n\n      fl, ok := arg.(float64)\n      if ok {\n         return float32(fl), nil\n      }\n\n      return arg.(float32), nil\n   {{- else -}}  \n      var obj {{ .RichType.Elem }}\n      err := mapstructure.Decode(arg, &obj)\n\n      return obj, err\n   {{- end -}}\n{{end -}}\n\n{{define \"atomTypeCast\"}}\n            {{if eq .SimpleType \"string\"}}\n               str, ok := arg.(string)\n               if !ok {\n                  num, ok := arg.(float64) \n                  if ok {\n                     return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil\n                  }\n\n                  return \"\", errors.New(\"wrong type, string is expected for ({{ .Name  }})\")\n               }\n\n               return str, nil\n            {{else if eq .SimpleType \"int\"}} \n               if arg == nil {\n                  return 0, errors.New(\"wrong type\") \n               }\n\n               str, ok := arg.(string) \n               if ok {\n                  return strconv.Atoi(str)\n               }\n\n               fl, ok := arg.(float64)\n               if ok {\n                  return int(fl), nil\n               }\n\n               return arg.(int), nil\n            {{else if eq .SimpleType \"float32\"}} \n               if arg == nil {\n                  return 0, errors.New(\"wrong type\") \n               }\n\n               str, ok := arg.(string) \n               if ok {\n                  fl, err := strconv.ParseFloat(str, 32) \n                  if err != nil {\n                     return 0, errors.New(\"invalid data\")\n                  }\n                  return float32(fl), nil\n               }\n\n               fl, ok := arg.(float64)\n               if ok {\n                  return float32(fl), nil\n               }\n\n               return arg.(float32), nil\n            {{else if eq .SimpleType \"int32\"}} \n               if arg == nil {\n                  return 0, errors.New(\"wrong type\") \n               }\n\n               str, ok := arg.(string) \n               if ok {\n                  val, err := strconv.Atoi(str)                  \n                  return int32(val), err\n               }\n\n               fl, ok := arg.(float64)\n               if ok {\n                  return int32(fl), nil\n               }\n\n               return arg.(int32), nil\n            {{else}}\n               var obj {{ .Elem }}\n               err := mapstructure.Decode(arg, &obj)\n\n               return obj, err\n            {{end}}\n{{end}}\n\n{{define \"argCast\" }}\n{{if .RichType.Bytes -}}\n      return goapi.DecodeBytes(arg)\n{{- else if .RichType.Array -}} \n      argsSlice := arg.([]interface{}) \n      outArray := make([]{{ .RichType.Elem }}, 0, len(argsSlice))\n\n      for _, element := range argsSlice {\n         item, err := func(arg interface{}) ({{ .RichType.Elem }}, error) {\n            {{template \"atomTypeCast\" .RichType}}\n         }(element)\n\n         if err != nil {\n            return outArray, err\n         }\n\n         outArray = append(outArray, item)         \n      }\n\n      return outArray, nil\n{{else -}}\n{{template \"typeCast\" . -}}\n{{end -}}\n{{end -}}\n\n{{define \"validateArgs\" -}}\n{{ if .Validate }}\n   ________validator := goapi.NewValidator()\n   {{- range $_, $item := .Params }}{{ if $item.Validate }}\n   ________validator.Check({{ printf \"%q\" $item.Name }}, {{ $item.Name }}, {{ printf \"%q\" $item.Rules }}){{ end }}{{ end }}\n   if err := ________validator.Err(); err != nil {\n      return {{ if $.Subscription }}nil, {{ end }}err\n   }\n{{ end -}}\n{{end -}}\n\n{{define \"decodeArgs\" -}}\n   {{ if .Params }}________args{{ else }}_{{ end }}, err := goapi.NewArguments(callData, []goapi.Param{\n   {{- range $_, $item := .Params }}\n      {Name: {{ printf \"%q\" $item.Name }}, Optional: {{ $item.Optional }}, Default: {{ printf \"%q\" $item.Default }}},\n   {{- end }}\n   })\n   if err != nil {\n      return {{ if .Subscription }}nil, {{ end }}err\n   }\n   {{ range $index, $item := .Params }}\n   var {{ $item.Name }} {{ $item.Type }}\n   if ________arg, ________ok := ________args.Get({{ $index }}); ________ok {\n      {{ $item.Name }}, err = func(arg interface{}) ({{ $item.Type }}, error) { {{ template \"argCast\" $item }}\n      }(________arg)\n      if err != nil {\n         return {{ if $.Subscription }}nil, {{ end }}err\n      }\n   }\n   {{ end }}\n{{- end }}\n\n{{- if .Subscription }}\n{{ docComment .Comments }}\nfunc subscribeTo{{ .Name }}(callData map[string]interface{}, event goapi.EventCallback) (goapi.Subscription, error) {\n   {{ template \"decodeArgs\" . }}\n   {{ template \"validateArgs\" . }}\n   return {{ .Package}}.{{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }},{{end}}event)\n}\n\n{{ docComment .Comments }}\nfunc subscriptionTypes{{ .Name }}(callData map[string]interface{}) ([]interface{}, error) {\n   {{ template \"decodeArgs\" . }}\n   result := make([]interface{}, 0, {{ len .Params }})\n   {{ range $index, $item := .Params -}}\n   if ________args.Passed({{ $index }}) {\n      result = append(result, {{ $item.Name }})\n   }\n   {{ end }}\n   return result, nil\n}\n{{- else }}\nfunc callAdapterFor{{ .Name }}(callData map[string]interface{}, callback goapi.JsCallback) error {\n   {{ template \"decodeArgs\" . }}\n   {{ template \"validateArgs\" . }}\n   {{ .Package }}.{{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }}, {{end}} callback)\n   return nil\n}\n{{- end }}\n"
+var _Assets693a5f743ee831585a375e293545870ad9775df1 = "{{ if .Subscription }}{{/* subscriptions take positional arguments, they build the name of the events */}}\n{{ docComment .Comments }}\nexport function {{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }}: {{ $item.Type }},{{end}}callback: (e: {{ .Subscription }}) => void ) : GoSubscription {\n   {{ if .Validate }}{{ template \"jsValidate\" .Params }}\n   {{ end -}}\n   try {\n      return subribeApiCall('{{ .Name }}', [{{ template \"jsArgs\" .Params }}], (json: string) => { callback(JSON.parse(json, reviveBinary)) })\n   } catch(error) {\n      console.warn(\"Call of {{ .Name }} failed\", error)\n   }\n\n   return undefined\n}\n{{ else if .Stream }}\n{{ docComment .Comments }}\nexport function {{ .Name }}({{ template \"jsParams\" .Params }}) : AsyncIterable<{{ .Stream }}> {\n   {{ if .Validate }}{{ template \"jsValidate\" .Params }}\n   {{ end -}}\n   return streamApiCall('{{ .Name }}', {{ template \"jsCallArgs\" .Params }})\n}\n{{ else }}\n{{ docComment .Comments }}\nexport async function {{ .Name }}({{ template \"jsParams\" .Params }}) : Promise<{{ .ReturnType }}> {\n   {{ if .Validate }}{{ template \"jsValidate\" .Params }}\n   {{ end -}}\n   try {\n        const jsonString = await {{ if .Pure }}runPureApiCall{{ else }}runApiCall{{ end }}('{{ .Name }}', {{ template \"jsCallArgs\" .Params }})\n        return await parseResult(jsonString)\n   } catch(error) {\n        console.warn(\"Call of {{ .Name }} failed\", error)\n        throw error\n   }\n}\n{{ end }}\n"
+var _Assets0533dda96e7fff99871c332d671b47636555c60c = "package {{.Package}}\n\nimport (\n\t\"context\"\n\t\"encoding/json\"\n\t\"log\"\n\t\"errors\"\n\t\"strconv\"\n\t{{if .Dev -}}\n\t\"net/http\"\n\t\"fmt\"\n\t\"os\"{{end}}\n\t\"gitlab.vmassive.ru/wand/goapi\"\n\t\"github.com/mitchellh/mapstructure\"\n\t\"{{ .SourcePackage }}\"\n\t{{if .Dev}}\"gitlab.vmassive.ru/wand/goapi/remgo\"{{end}}\n)\n\n// Registry for all calls\nvar registry goapi.JsRegistry = goapi.NewJsRegistry()\n\n// Registry - the registry of the functions, for go tests with goapi/testbridge\nfunc Registry() *goapi.JsRegistry {\n\treturn &registry\n}\n\n// Use installs middlewares of calls from JS, call it at startup before the first call\nfunc Use(middleware ...goapi.Middleware) {\n\tregistry.Use(middleware...)\n}\n\n// UseSubscription installs middlewares of subscriptions and their cancellations\nfunc UseSubscription(middleware ...goapi.SubscriptionMiddleware) {\n\tregistry.UseSubscription(middleware...)\n}\n\n// SetPanicHandler sets the handler of panics in calls, subscriptions and events\nfunc SetPanicHandler(handler goapi.PanicHandler) {\n\tregistry.SetPanicHandler(handler)\n}\n\n// binaryDir directory of the files with large byte slices of the results, see SetBinaryDir\nvar binaryDir string\n\n// binaryEncoder encoder of byte slices of the results\nvar binaryEncoder goapi.BinaryEncoder = goapi.InlineBinary\n\n// SetBinaryDir - byte slices of the results larger than threshold go to JS as files of the directory,\n// like the cache directory of the app, instead of inline base64. JS passes files of the directory\n// to byte slice parameters, other files are not read\nfunc SetBinaryDir(dir string, threshold int) {\n\tbinaryDir = dir\n\tbinaryEncoder = goapi.FileBinary(dir, threshold)\n\tregistry.SetBinaryDir(dir)\n}\n\n// ReleaseBinary - JS read the file with byte slice of the result, it is removed\nfunc ReleaseBinary(uri string) {\n\tif err := goapi.ReleaseBinaryFile(binaryDir, uri); err != nil {\n\t\tlog.Printf(\"release of binary failed: %v\", err)\n\t}\n}\n\n// Metrics - json snapshot of the metrics of calls and subscriptions\nfunc Metrics() string {\n\tbytes, _ := json.Marshal(registry.Metrics().Snapshot())\n\treturn string(bytes)\n}\n\n\ntype X_____xxxx struct { Val string }\nfunc Ping____(number int) string {\n\treturn strconv.Itoa(number)\n}\n\nvar _ = errors.New\n\nfunc Ping____XXX(val interface{}) string {\n\tdata := X_____xxxx{}\n\tmapstructure.Decode(val, &val)\n\treturn data.Val\n}\n\n{{if .Dev }}\nfunc serveHome(w http.ResponseWriter, r *http.Request) {\n\tlog.Println(r.URL)\n\tif r.URL.Path != \"/\" {\n\t\thttp.Error(w, \"Not found\", http.StatusNotFound)\n\t\treturn\n\t}\n\tif r.Method != \"GET\" {\n\t\thttp.Error(w, \"Method not allowed\", http.StatusMethodNotAllowed)\n\t\treturn\n\t}\n\thttp.ServeFile(w, r, \"home.html\")\n}\n\n\nfunc SetCors(h http.Handler) http.Handler {\n\treturn http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n\t\tw.Header().Set(\"Access-Control-Allow-Origin\", \"*\")\n\t\tw.Header().Set(\"Access-Control-Allow-Methods\", \"POST, GET, OPTIONS, PUT, DELETE\")\n\t\tw.Header().Set(\"Access-Control-Allow-Headers\", \"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization\")\n\n\t\tif (*r).Method == \"OPTIONS\" {\n\t\t\treturn\n\t\t}\n\n\t\th.ServeHTTP(w, r)\n\t})\n}\n\nfunc main() {\n\tregistry.SetDev(true)\n\n\thub := remgo.NewHub()\n\toptions := remgo.Options{\n\t\tMaxMessageSize: {{.Bridge.MaxMessageSize}},\n\t\tCompression:    {{.Bridge.Compression}},\n\t\tMaxBatchSize:   {{.Bridge.MaxBatchSize}},\n\t}\n\t{{- with .Bridge.CompressionLevel }}\n\tcompressionLevel := {{ . }}\n\toptions.CompressionLevel = &compressionLevel\n\t{{- end }}\n\thub.Configure(options)\n\tif fileName := os.Getenv(remgo.RecordEnv); fileName != \"\" {\n\t\trecorder, err := remgo.NewRecorder(fileName)\n\t\tif err != nil {\n\t\t\tlog.Fatal(\"Recorder: \", err)\n\t\t}\n\t\tdefer recorder.Close()\n\n\t\thub.Record(recorder)\n\t}\n\tgo hub.Run(&registry)\n\n  goPath := os.Getenv(\"GOPATH\")\n\tfs := http.FileServer(http.Dir(goPath  + \"/src/\"))\n\thandler := http.StripPrefix(\"/file\" + goPath + \"/src/\", fs)\n\n\tfmt.Printf(\"serving files in %s\\n\", goPath)\n\n\thttp.Handle(\"/file/\", SetCors(handler))\n\n\thttp.Handle(\"/metrics\", registry.Metrics())\n\thttp.HandleFunc(\"/\", serveHome)\n\thttp.HandleFunc(\"/ws\", func(w http.ResponseWriter, r *http.Request) {\n\t\tremgo.ServeWs(&registry, hub, w, r)\n\t})\n\terr := http.ListenAndServe(\"0.0.0.0:{{.Port}}\", nil)\n\tif err != nil {\n\t\tlog.Fatal(\"ListenAndServe: \", err)\n\t}\n}\n{{end}}\n// JsCallback the interface for any callbacks\ntype JsCallback interface {\n\tOnSuccess(json string)\n\tOnError(json string)\n}\n\n// JsEvent the interface for any events\ntype JsEvent interface {\n\tOnEvent(eventName string, json string)\n}\n\ntype eventerSender struct {\n\tevent\t\tJsEvent\n}\n\nfunc newEventSender(event JsEvent) goapi.JsEvent {\n\treturn &eventerSender{\n\t\tevent: event,\n\t}\n}\n\nfunc (eventer eventerSender) OnEvent(eventName string, data interface{}) {\n\tlog.Printf(\" >> + << %#v\", data)\n\tbytes, _ := json.Marshal(goapi.EncodeBinary(data, goapi.InlineBinary))\n\teventer.event.OnEvent(eventName, string(bytes))\n}\n\ntype callbackCaller struct {\n\tcallback JsCallback\n}\n\nfunc (caller callbackCaller) OnSuccess(data interface{}) {\n\tlog.Printf(\" >> + << %#v\", data)\n\tbytes, _ := json.Marshal(goapi.EncodeBinary(data, binaryEncoder))\n\tcaller.callback.OnSuccess(string(bytes))\n}\n\nfunc (caller callbackCaller) OnError(data interface{}) {\n\tbytes, _ := json.Marshal(data)\n\tcaller.callback.OnError(string(bytes))\n}\n\nfunc newCaller(callback JsCallback) goapi.JsCallback {\n\treturn &callbackCaller{\n\t\tcallback: callback,\n\t}\n}\n\n// CallMethod - call from JS\nfunc CallMethod(callData string, callback JsCallback) {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tcaller := newCaller(callback)\n\tregistry.Call(methodCallData, caller)\n}\n\n// CallMethodSync - blocking call from JS of the function which answers before it returns,\n// the result is {\"Success\": value} or {\"Error\": error}\nfunc CallMethodSync(callData string) string {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tresult, err := registry.CallSync(context.Background(), methodCallData)\n\tif err != nil {\n\t\tbytes, _ := json.Marshal(map[string]interface{}{\"Error\": err})\n\t\treturn string(bytes)\n\t}\n\n\tbytes, _ := json.Marshal(map[string]interface{}{\"Success\": goapi.EncodeBinary(result, binaryEncoder)})\n\treturn string(bytes)\n}\n\n// OpenStream - open stream of streaming function from JS, its items and its end are sent\n// as \"stream:<streamID>\" events, credits is the number of items JS is ready to read\nfunc OpenStream(callData string, streamID string, credits int) {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tregistry.OpenStream(context.Background(), streamID, methodCallData, credits, registry.NewEventStreamSink(\"stream:\"+streamID))\n}\n\n// StreamCredit - JS is ready to read count more items of the stream\nfunc StreamCredit(streamID string, count int) {\n\tregistry.StreamCredit(streamID, count)\n}\n\n// CloseStream - JS stopped reading the stream\nfunc CloseStream(streamID string) {\n\tregistry.CloseStream(streamID)\n}\n\nfunc RegisterEventCallback(callback JsEvent) {\n\tregistry.RegisterEventCallback(newEventSender(callback))\n}\n\nfunc RemoveEventCallback() {\n\tregistry.RegisterEventCallback(nil)\n}\n\n// Subscribe - subsribe from JS\nfunc Subscribe(callData string) {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tregistry.Subscribe(methodCallData)\n}\n\n// Cancel - cancel subscription from JS\nfunc Cancel(callData string) {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tregistry.CancelSubscription(methodCallData)\n}\n"
+var _Assets382e2ce7c28616d04646a0aad561f67e2a6e1c39 = "/**\n * GoCall library binding\n * flow\n */\n\nimport {\n  NativeModules,\n  NativeEventEmitter,\n  DeviceEventEmitter,\n  EmitterSubscription,\n  Platform,\n} from 'react-native';\n\ntype GoSubscription = {\n   subscription: EmitterSubscription,\n   name: string,\n   args: any[],\n   devId: number,\n};\n\ntype StreamListener = {\n   onItem: (item: any) => void,\n   onDone: (error: any) => void,\n};\n\n// StreamControl of the opened stream, credit lets go send count more items\ntype StreamControl = {\n   credit: (count: number) => void,\n   close: () => void,\n};\n\n{{if .Dev }}\nclass RemoveDev {\n  server = \"\"\n  requestId = 1\n  call = {}\n  event = {}\n  stream = {}\n  ws: WebSocket\n  pendingList = []\n  outbox = []\n\n  constructor(server : string) {\n    this.server = server\n    this.connect()\n  }\n\n  connect = () => {\n    const ws = new WebSocket(this.server)\n    ws.binaryType = 'arraybuffer'\n    ws.onmessage = this.onMessage\n\n    this.ws = ws\n    this.ws.onopen = this.sendPending\n    ws.onclose = () => {\n       // Try to reconnect in 5 seconds\n       setTimeout(() => { this.connect()  }, 1000);\n   };\n  }\n\n  onMessage = (message: any) => {\n    if (typeof message.data !== 'string') {\n      const [json, attachments] = splitFrame(message.data)\n      this.onResponse(JSON.parse(decodeUtf8(json)), attachments)\n      return\n    }\n\n    const messages = message.data.split(\"\\n\")\n    messages.forEach((content: string) => {\n      this.onResponse(JSON.parse(content), [])\n    })\n  }\n\n  // onResponse routes the response, byte slices of binary frame are attached to it\n  onResponse = (response: any, attachments: Uint8Array[]) => {\n    if (this.stream[response.ID]) {\n      this.stream[response.ID](decodeAttachments(response, attachments))\n    } else if (this.call[response.ID]) {\n      this.call[response.ID](keepAttachments(response, attachments))\n    } else  if (response.EventName) {\n      if (this.event[response.EventName]) {\n        this.notify(this.event[response.EventName], keepAttachments(response, attachments))\n      }\n    }\n  }\n\n  notify(subscribers, response) {\n      const keys = Object.keys(subscribers)\n      keys.forEach(key => {\n         subscribers[key](response)\n      })\n\n  }\n\n  sendPending = () => {\n    this.pendingList.forEach(it => this.ws.send(it))\n  }\n\n  callMethod = (name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> => {\n    return new Promise((resolve, reject) => {\n      const callData = {\n        args,\n        kwargs,\n        method: name,\n      }\n\n      const requestID = this.requestId++\n\n      this.call[requestID] = (response: any) => {\n        if (response.Error === undefined || response.Error === null) {\n          resolve(JSON.stringify(response.Success))\n        } else {\n          reject(JSON.stringify(response.Error))\n        }\n\n        this.call[requestID] = null\n      }\n\n      this.send({id: requestID, call: callData })\n    })\n  }\n\n  // send queues the request, requests of the same tick go in one frame as a batch\n  send = (request: any) => {\n    if (this.outbox.length === 0) {\n      setTimeout(this.flush, 0)\n    }\n\n    this.outbox.push(request)\n  }\n\n  flush = () => {\n    const outbox = this.outbox\n    this.outbox = []\n\n    for (let start = 0; start < outbox.length; start += maxBatchSize) {\n      const batch = outbox.slice(start, start + maxBatchSize)\n      const body = encodeRequest(batch.length === 1 ? batch[0] : batch)\n      try  {\n        this.ws.send(body)\n      } catch (err) {\n        this.pendingList = [...this.pendingList, body]\n      }\n    }\n  }\n\n  openStream = (name: string, args :any[], kwargs: ?{ [name: string]: any }, credits: number, listener: StreamListener) : StreamControl => {\n    const requestID = this.requestId++\n\n    this.stream[requestID] = (response: any) => {\n      if (response.Done) {\n        delete this.stream[requestID]\n        listener.onDone(response.Error)\n      } else {\n        listener.onItem(response.Item)\n      }\n    }\n\n    this.send({id: requestID, stream: { args, kwargs, method: name, credits }})\n\n    return {\n      credit: (count: number) => {\n        this.send({id: this.requestId++, credit: { stream: requestID, count }})\n      },\n      close: () => {\n        delete this.stream[requestID]\n        this.send({id: this.requestId++, close: { stream: requestID }})\n      },\n    }\n  }\n\n  cancel = (name: string, args :any[], eventName, requestId) : GoSubscriptions => {\n    const callData = {\n      args,\n      event: name,\n    }\n\n    delete this.event[eventName][requestId]\n\n    this.send({id: this.requestId, cancel: callData })\n\n    return { name, name, devId: this.requestId }\n  }\n\n  subscribe = (name: string, args :any[], eventName: string, callback: (json : String) => void) : GoSubscriptions => {\n    const callData = {\n      args,\n      event: name,\n    }\n\n    const requestID = this.requestId++\n\n    if (!this.event[eventName]) {\n      this.event[eventName] = {}\n    }\n\n    this.event[eventName][requestID] = (response: any) => {\n      if (response.Body) {\n        callback(JSON.stringify(response.Body))\n      }\n    }\n\n    this.send({id: requestID, subscribe: callData })\n\n    return { args, name, eventName, devId: requestID }\n  }\n}\n\n// maxBatchSize requests in one frame, the limit of the dev server\nconst maxBatchSize = {{if .Bridge.MaxBatchSize}}{{.Bridge.MaxBatchSize}}{{else}}64{{end}}\n\nconst devCall = new RemoveDev(\"ws://localhost:{{.Port}}/ws\");\n\n// encodeRequest json of the request, or binary frame when it has byte arrays,\n// the frame is uint32 length and bytes of the json and of every byte array\nfunction encodeRequest(request: any) : string | ArrayBuffer {\n  const attachments = []\n  const json = JSON.stringify(request, (key: string, value: any) => {\n    if (value instanceof Uint8Array) {\n      attachments.push(value)\n      return { $binary: attachments.length - 1 }\n    }\n\n    return value\n  })\n\n  if (attachments.length === 0) {\n    return json\n  }\n\n  const chunks = [encodeUtf8(json), ...attachments]\n  const frame = new Uint8Array(chunks.reduce((size, chunk) => size + 4 + chunk.length, 0))\n  const view = new DataView(frame.buffer)\n\n  let offset = 0\n  chunks.forEach((chunk) => {\n    view.setUint32(offset, chunk.length)\n    frame.set(chunk, offset + 4)\n    offset += 4 + chunk.length\n  })\n\n  return frame.buffer\n}\n\n// splitFrame json and byte arrays of binary frame\nfunction splitFrame(buffer: ArrayBuffer) : [Uint8Array, Uint8Array[]] {\n  const view = new DataView(buffer)\n  const chunks = []\n\n  let offset = 0\n  while (offset + 4 <= buffer.byteLength) {\n    const length = view.getUint32(offset)\n    chunks.push(new Uint8Array(buffer, offset + 4, length))\n    offset += 4 + length\n  }\n\n  return [chunks[0], chunks.slice(1)]\n}\n\nconst binaryAttachments = {}\nlet nextAttachment = 1\n\n// keepAttachments replaces {$binary: index} markers with keys of the kept byte arrays, the\n// response goes through JSON string and reviveBinary takes the byte arrays back\nfunction keepAttachments(response: any, attachments: Uint8Array[]) : any {\n  return mapAttachments(response, (index: number) => {\n    const key = nextAttachment++\n    binaryAttachments[key] = attachments[index]\n\n    return { $attachment: key }\n  })\n}\n\n// decodeAttachments replaces the markers with byte arrays\nfunction decodeAttachments(response: any, attachments: Uint8Array[]) : any {\n  return mapAttachments(response, (index: number) => attachments[index])\n}\n\nfunction mapAttachments(value: any, convert: (index: number) => any) : any {\n  if (value === null || typeof value !== 'object') {\n    return value\n  }\n\n  if (Array.isArray(value)) {\n    return value.map(item => mapAttachments(item, convert))\n  }\n\n  if (typeof value.$binary === 'number') {\n    return convert(value.$binary)\n  }\n\n  const result = {}\n  Object.keys(value).forEach((key) => {\n    result[key] = mapAttachments(value[key], convert)\n  })\n\n  return result\n}\n\nfunction encodeUtf8(text: string) : Uint8Array {\n  const bytes = []\n  for (let index = 0; index < text.length; index++) {\n    let code = text.charCodeAt(index)\n    if (code >= 0xd800 && code < 0xdc00 && index + 1 < text.length) {\n      code = 0x10000 + ((code - 0xd800) << 10) + (text.charCodeAt(++index) - 0xdc00)\n    }\n\n    if (code < 0x80) {\n      bytes.push(code)\n    } else if (code < 0x800) {\n      bytes.push(0xc0 | (code >> 6), 0x80 | (code & 0x3f))\n    } else if (code < 0x10000) {\n      bytes.push(0xe0 | (code >> 12), 0x80 | ((code >> 6) & 0x3f), 0x80 | (code & 0x3f))\n    } else {\n      bytes.push(0xf0 | (code >> 18), 0x80 | ((code >> 12) & 0x3f), 0x80 | ((code >> 6) & 0x3f), 0x80 | (code & 0x3f))\n    }\n  }\n\n  return new Uint8Array(bytes)\n}\n\nfunction decodeUtf8(bytes: Uint8Array) : string {\n  let text = ''\n  for (let index = 0; index < bytes.length;) {\n    const byte = bytes[index++]\n    let code = byte\n    if (byte >= 0xf0) {\n      code = ((byte & 0x07) << 18) | ((bytes[index++] & 0x3f) << 12) | ((bytes[index++] & 0x3f) << 6) | (bytes[index++] & 0x3f)\n    } else if (byte >= 0xe0) {\n      code = ((byte & 0x0f) << 12) | ((bytes[index++] & 0x3f) << 6) | (bytes[index++] & 0x3f)\n    } else if (byte >= 0xc0) {\n      code = ((byte & 0x1f) << 6) | (bytes[index++] & 0x3f)\n    }\n\n    text += String.fromCodePoint(code)\n  }\n\n  return text\n}\n\n{{else}}\n// iOS module is an RCTEventEmitter with a single event, re-dispatch it by name\nif (Platform.OS === 'ios') {\n  const goCallEmitter = new NativeEventEmitter(NativeModules.GoCall)\n  goCallEmitter.addListener('GoCallEvent', ({ name, json }) => {\n    DeviceEventEmitter.emit(name, json)\n  })\n}\n\nlet nativeStreamId = 1\n\n// openNativeStream opens the stream in native module, its items and its end come as \"stream:<id>\" events\nfunction openNativeStream(name: string, args :any[], kwargs: ?{ [name: string]: any }, credits: number, listener: StreamListener) : StreamControl {\n  const streamID = `${Date.now()}:${nativeStreamId++}`\n\n  const subscription = DeviceEventEmitter.addListener(`stream:${streamID}`, (json: string) => {\n    const response = JSON.parse(json, reviveBinary)\n    if (response.Done) {\n      subscription.remove()\n      listener.onDone(response.Error)\n    } else {\n      listener.onItem(response.Item)\n    }\n  })\n\n  const callData = JSON.stringify({\n    args,\n    kwargs,\n    method: name,\n  }, binaryReplacer)\n\n  NativeModules.GoCall.openStream(callData, streamID, credits)\n\n  return {\n    credit: (count: number) => NativeModules.GoCall.streamCredit(streamID, count),\n    close: () => {\n      subscription.remove()\n      NativeModules.GoCall.closeStream(streamID)\n    },\n  }\n}\n\n{{end}}\n\n// Byte slices of go are Uint8Array, they come as markers: {$base64: \"...\"} inline,\n// {$binary: index} attached to binary frame of the dev server, or {$file: uri, size}\n// written to the cache directory by native module when they are large\n\n// binaryFile passes the file to go function as []byte parameter, go reads the file\n// itself, so large payloads, like photos, don't go through the bridge. The file must be\n// in the cache directory of the app, go does not read other files, nor any file in dev\nexport function binaryFile(uri: string) : Uint8Array {\n   return ({ $file: uri }: any)\n}\n\n// reviveBinary is JSON.parse reviver which turns the markers into Uint8Array\nexport function reviveBinary(key: string, value: any) : any {\n   if (value === null || typeof value !== 'object' || Array.isArray(value)) {\n      return value\n   }\n\n   if (typeof value.$base64 === 'string') {\n      return decodeBase64(value.$base64)\n   }\n   {{if .Dev}}\n   if (value.$attachment !== undefined) {\n      const data = binaryAttachments[value.$attachment]\n      delete binaryAttachments[value.$attachment]\n\n      return data\n   }\n   {{end}}\n   return value\n}\n\n// parseResult parses the result of go function, byte slices written to files are\n// read before it resolves\nexport async function parseResult(json: string) : Promise<any> {\n   const files = []\n   const value = JSON.parse(json, (key: string, item: any) => {\n      if (item !== null && typeof item === 'object' && typeof item.$file === 'string') {\n         const data = new Uint8Array(item.size || 0)\n         files.push(readBinaryFile(item.$file, data))\n\n         return data\n      }\n\n      return reviveBinary(key, item)\n   })\n\n   await Promise.all(files)\n   return value\n}\n\nfunction readBinaryFile(uri: string, data: Uint8Array) : Promise<void> {\n   return new Promise((resolve, reject) => {\n      const request = new XMLHttpRequest()\n      request.open('GET', uri)\n      request.responseType = 'arraybuffer'\n      request.onload = () => {\n         data.set(new Uint8Array(request.response))\n         {{- if not .Dev }}\n         NativeModules.GoCall.releaseBinary(uri)\n         {{- end }}\n         resolve()\n      }\n      request.onerror = () => reject(`failed to read ${uri}`)\n      request.send()\n   })\n}\n\n// binaryReplacer is JSON.stringify replacer which turns Uint8Array into {$base64: \"...\"}\nfunction binaryReplacer(key: string, value: any) : any {\n   return value instanceof Uint8Array ? { $base64: encodeBase64(value) } : value\n}\n\nconst base64Alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/'\n\nfunction encodeBase64(data: Uint8Array) : string {\n   let text = ''\n   for (let index = 0; index < data.length; index += 3) {\n      const chunk = (data[index] << 16) | ((data[index + 1] || 0) << 8) | (data[index + 2] || 0)\n      text += base64Alphabet[(chunk >> 18) & 63] + base64Alphabet[(chunk >> 12) & 63]\n      text += index + 1 < data.length ? base64Alphabet[(chunk >> 6) & 63] : '='\n      text += index + 2 < data.length ? base64Alphabet[chunk & 63] : '='\n   }\n\n   return text\n}\n\nfunction decodeBase64(text: string) : Uint8Array {\n   const clean = text.replace(/=+$/, '')\n   const data = new Uint8Array(Math.floor(clean.length * 3 / 4))\n\n   let chunk = 0\n   let bits = 0\n   let offset = 0\n   for (let index = 0; index < clean.length; index++) {\n      chunk = (chunk << 6) | base64Alphabet.indexOf(clean[index])\n      bits += 6\n      if (bits >= 8) {\n         bits -= 8\n         data[offset++] = (chunk >> bits) & 0xff\n      }\n   }\n\n   return data\n}\n\nfunction getName(name: string, args :any[]) : string {\n   body = args.reduce((acc:string, value: any) => {\n      if (acc == \"\") {\n         return value\n      }\n\n      return acc + \":\" + value\n   }, \"\")\n\n   return `${name}:${body}`\n}\n\ntype ValidationFieldError = {\n   path: string,\n   rule: string,\n   param: string,\n   message: string,\n};\n\n// ValidationError is thrown before the call when arguments break validation rules,\n// go side rejects the call with the same fields\nexport class ValidationError extends Error {\n   fields: ValidationFieldError[]\n\n   constructor(fields: ValidationFieldError[]) {\n      super('validation failed: ' + fields.map(field => `${field.path} ${field.message}`).join(', '))\n      this.fields = fields\n   }\n}\n\nfunction isEmpty(value: any) : boolean {\n   if (value === undefined || value === null) {\n      return true\n   }\n\n   if (typeof value === 'string' || Array.isArray(value) || value instanceof Uint8Array) {\n      return value.length === 0\n   }\n\n   return false\n}\n\n// number value or length of the value, same as go validator does\nfunction measure(value: any) : ?number {\n   if (typeof value === 'number') {\n      return value\n   }\n\n   if (typeof value === 'string') {\n      return [...value].length\n   }\n\n   if (Array.isArray(value) || value instanceof Uint8Array) {\n      return value.length\n   }\n\n   if (typeof value === 'object') {\n      return Object.keys(value).length\n   }\n\n   return undefined\n}\n\nconst emailPattern = /^[^@\\s]+@[^@\\s]+\\.[^@\\s]+$/\n\nfunction checkRule(value: any, [name, param]: [string, string]) : ?string {\n   if (name === 'required') {\n      return isEmpty(value) ? 'is required' : undefined\n   }\n\n   if (value === undefined || value === null) {\n      return undefined\n   }\n\n   const size = measure(value)\n\n   switch (name) {\n      case 'min':\n         return size !== undefined && size < Number(param) ? `must be at least ${param}` : undefined\n      case 'max':\n         return size !== undefined && size > Number(param) ? `must be at most ${param}` : undefined\n      case 'len':\n         return size !== undefined && size !== Number(param) ? `must be exactly ${param}` : undefined\n      case 'email':\n         return typeof value === 'string' && value.length > 0 && !emailPattern.test(value) ? 'must be an email' : undefined\n      case 'oneof':\n         return param.split(' ').filter(item => item !== '').includes(String(value)) ? undefined : `must be one of ${param}`\n   }\n\n   return undefined\n}\n\nfunction validateValue(errors: ValidationFieldError[], path: string, value: any, rules: [string, string][], type: ?string) {\n   rules.forEach((rule) => {\n      const message = checkRule(value, rule)\n      if (message) {\n         errors.push({ path, rule: rule[0], param: rule[1], message })\n      }\n   })\n\n   if (!type || value === undefined || value === null) {\n      return\n   }\n\n   if (Array.isArray(value)) {\n      value.forEach((item, index) => validateValue(errors, `${path}[${index}]`, item, [], type))\n      return\n   }\n\n   if (type.startsWith('{}')) {\n      const valueType = type.slice(2)\n      Object.keys(value).sort().forEach((key) => {\n         validateValue(errors, `${path}[${JSON.stringify(key)}]`, value[key], [], valueType)\n      })\n      return\n   }\n\n   const fields = validationRules[type] || {}\n   Object.keys(fields).forEach((name) => {\n      validateValue(errors, `${path}.${name}`, value[name], fields[name].rules, fields[name].type)\n   })\n}\n\nfunction validateArgs(args: [string, any, [string, string][], ?string][]) {\n   const errors = []\n   args.forEach(([path, value, rules, type]) => validateValue(errors, path, value, rules, type))\n\n   if (errors.length > 0) {\n      throw new ValidationError(errors)\n   }\n}\n\nexport function subribeApiCall(name: string, args :any[], callback: (json : String) => void) : GoSubscription {\n   args = trimArgs(args)\n   {{if .Dev}}\n   const subscriptionName = getName(name, args)\n   return devCall.subscribe(name, args, subscriptionName, callback)\n   {{else}}\n   const subscriptionName = getName(name, args)\n   subscription = DeviceEventEmitter.addListener(subscriptionName, callback);\n\n   const callData = JSON.stringify({\n      args,\n      event: name,\n   }, binaryReplacer)\n\n   NativeModules.GoCall.subscribe(callData)\n   \n   return {\n      args,\n      name,\n      subscription,\n   }\n   {{end}}\n}\n\nexport async function cancelSubscriptionApiCall(subs : GoSubscription) : Promise<any> {\n  {{if .Dev}} \n  const {name, args, requestId, eventName, subscription} = subs\n  return devCall.cancel(name, args, eventName, requestId)\n\n  {{else}}\n  const {name, args, subscription} = subs\n\n  const callData = JSON.stringify({\n    args,\n    event: name,\n  }, binaryReplacer)\n\n  subscription.remove()\n\n  return NativeModules.GoCall.cancel(callData)\n  {{end}}\n}\n\n// trimArgs drops missing trailing arguments, go side gives them default values\nfunction trimArgs(args: any[]) : any[] {\n   let length = args.length\n   while (length > 0 && args[length - 1] === undefined) {\n      length--\n   }\n\n   return args.slice(0, length)\n}\n\n// runApiCall calls go function with positional args and, optionally, with\n// arguments named after go parameters\nexport async function runApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {\n   {{if .Dev}} \n    return devCall.callMethod(name, trimArgs(args), kwargs)\n   {{else}}\n    const callData = JSON.stringify({\n      args: trimArgs(args),\n      kwargs,\n      method: name,\n    }, binaryReplacer)\n\n    return NativeModules.GoCall.callMethod(callData)\n   {{end}}\n}\n\n// runPureApiCall calls go function which answers before it returns, native module\n// calls it synchronously when the bridge can, e.g. not under remote debugger\nexport async function runPureApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {\n   {{if .Dev}}\n    return devCall.callMethod(name, trimArgs(args), kwargs)\n   {{else}}\n    const callData = JSON.stringify({\n      args: trimArgs(args),\n      kwargs,\n      method: name,\n    }, binaryReplacer)\n\n    if (!global.nativeCallSyncHook) {\n      return NativeModules.GoCall.callMethod(callData)\n    }\n\n    const response = JSON.parse(NativeModules.GoCall.callMethodSync(callData))\n    if (response.Error !== undefined && response.Error !== null) {\n      throw JSON.stringify(response.Error)\n    }\n\n    return JSON.stringify(response.Success)\n   {{end}}\n}\n\n// streamWindow items go sends ahead of the reader, the read ones are granted back by half of the window\nconst streamWindow = 16\n\n// streamApiCall opens go streaming function for every for await loop, go sends no more\n// items than the loop is ready to read and break of the loop stops the function\nexport function streamApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterable<any> {\n   return {\n      [Symbol.asyncIterator]: () => createStreamIterator(name, trimArgs(args), kwargs),\n   }\n}\n\nfunction createStreamIterator(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterator<any> {\n   const buffer = []\n   const waiting = []\n   let finished = false\n   let failure = undefined\n   let consumed = 0\n   let control: ?StreamControl = null\n\n   const take = () => {\n      const value = buffer.shift()\n\n      consumed++\n      if (!finished && control && consumed >= streamWindow / 2) {\n         control.credit(consumed)\n         consumed = 0\n      }\n\n      return { value, done: false }\n   }\n\n   const flush = () => {\n      while (waiting.length > 0 && (buffer.length > 0 || finished)) {\n         const { resolve, reject } = waiting.shift()\n         if (buffer.length > 0) {\n            resolve(take())\n         } else if (failure !== undefined) {\n            reject(failure)\n            failure = undefined\n         } else {\n            resolve({ value: undefined, done: true })\n         }\n      }\n   }\n\n   const listener = {\n      onItem: (item: any) => {\n         buffer.push(item)\n         flush()\n      },\n      onDone: (error: any) => {\n         finished = true\n         if (error !== undefined && error !== null) {\n            failure = JSON.stringify(error)\n         }\n         flush()\n      },\n   }\n\n   {{if .Dev}}\n   control = devCall.openStream(name, args, kwargs, streamWindow, listener)\n   {{else}}\n   control = openNativeStream(name, args, kwargs, streamWindow, listener)\n   {{end}}\n\n   return {\n      next: () => new Promise((resolve, reject) => {\n         waiting.push({ resolve, reject })\n         flush()\n      }),\n      // return is called by break of for await loop, go stops sending the items\n      return: () => {\n         if (!finished) {\n            finished = true\n            buffer.length = 0\n            control && control.close()\n         }\n         flush()\n\n         return Promise.resolve({ value: undefined, done: true })\n      },\n      [Symbol.asyncIterator]() {\n         return this\n      },\n   }\n}\n"
 var _Assetsd70499efd324d14a684d938f753ca0f22925c087 = "/**\n * GoCall library binding\n * flow\n */\n\nimport React, {Component, PureComponent, type ComponentType} from 'react';\n\nimport {\n  View,\n  NativeModules,\n} from 'react-native';\n\nimport {\n  branch,\n  renderNothing,\n} from 'recompose';\n\nexport function RenderWhenReady(fields) {\n  return function(target) {\n    return branch(\n      (props) => {\n        if (Array.isArray(fields)) {\n          return !fields.reduce((acc, val) => acc && (props[val] !== undefined), true)\n        }\n\n        return props[fields] === undefined\n      },\n      renderNothing,\n    )(target)\n  }\n}\n\nimport {\n  cancelSubscriptionApiCall,\n{{range $_, $item := .Functions}}\n  {{ $item }}, {{end}}\n{{range $_, $item := .Structures}}\n  type {{ $item }}, {{end}}\n\n} from './{{ .PackageName }}'\n\nconst emptyArray = []\n"
-var _Assets693a5f743ee831585a375e293545870ad9775df1 = "{{ if .Subscription }}\n/**{{range $_, $item := .Comments}}\n * {{ $item }}{{end}}\n */\nexport function {{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }}: {{ $item.Type }},{{end}}callback: (e: {{ .Subscription }}) => void ) : GoSubscription {\n   try {\n      return subribeApiCall('{{ .Name }}', [{{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}}], (json: string) => { callback(JSON.parse(json)) })\n   } catch(error) {\n      console.warn(\"Call of {{ .Name }} failed\", error)\n   }\n\n   return undefined\n}\n{{ else }}\n/**{{range $_, $item := .Comments}}\n * {{ $item }}{{end}}\n */\nexport async function {{ .Name }}({{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }}: {{ $item.Type }}{{end}}) : Promise<{{ .ReturnType }}> {\n   try {\n        const jsonString = await runApiCall('{{ .Name }}', [{{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}}])\n        return JSON.parse(jsonString)\n   } catch(error) {\n        console.warn(\"Call of {{ .Name }} failed\", error)\n        throw error\n   }\n}\n{{ end }}\n"
-var _Assets3b4dd3b006d667b8c4ecbff30ccd3c1161228224 = "\n{{define \"typeCast\"}}\n   {{if eq .Type \"string\" -}} \n      if arg == nil {\n         return \"\", errors.New(\"wrong type\") \n      }  \n         \n      str, ok := arg.(string)\n      if !ok {\n         num, ok := arg.(float64) \n         if ok {\n            return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil\n         }\n\n         return \"\", errors.New(\"wrong type, string is expected ({{ .Name  }})\")\n      }\n\n      return str, nil\n   {{- else if eq .Type \"int\" -}} \n      if arg == nil {\n         return 0, errors.New(\"wrong type\") \n      }\n            \n      str, ok := arg.(string) \n      if ok {\n         return strconv.Atoi(str)\n      }\n      fl, ok := arg.(float64)\n      if ok {\n         return int(fl), nil\n      }\n\n      return arg.(int), nil\n      {{- else if eq .Type \"float32\" -}} \n      str, ok := arg.(string) \n      if ok {\n         fl, err := strconv.ParseFloat(float32) \n         if err == nil {\n            return fl\n         }\n         return 0, errors.New(\"invalid data\")\n      }\n      return arg.(float32), nil\n   {{- else -}}  \n      obj := {{ .Package}}.{{ .RichType.SimpleType }}{}\n      err := mapstructure.Decode(arg, &obj)\n\n      {{if .RichType.Pointer }} \n      return &obj, err   \n      {{ else }}\n      return obj, err   \n      {{ end }}\n   {{- end -}}\n{{end -}}\n\n{{define \"atomTypeCast\"}}\n            {{if eq .SimpleType \"string\"}}\n               str, ok := arg.(string)\n               if !ok {\n                  num, ok := arg.(float64) \n                  if ok {\n                     return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil\n                  }\n\n                  return \"\", errors.New(\"wrong type, string is expected for ({{ .Name  }})\")\n               }\n\n               return str, nil\n            {{else if eq .SimpleType \"int\"}} \n               if arg == nil {\n                  return 0, errors.New(\"wrong type\") \n               }\n\n               str, ok := arg.(string) \n               if ok {\n                  return strconv.Atoi(str)\n               }\n\n               fl, ok := arg.(float64)\n               if ok {\n                  return int(fl), nil\n               }\n\n               return arg.(int), nil\n            {{else if eq .SimpleType \"float32\"}} \n               if arg == nil {\n                  return 0, errors.New(\"wrong type\") \n               }\n\n               str, ok := arg.(string) \n               if ok {\n                  fl, err := strconv.ParseFloat(float32) \n                  if err == nil {\n                     return fl\n                  }\n                  return 0, errors.New(\"invalid data\")\n               }\n               return arg.(float32), nil\n            {{else if eq .SimpleType \"int32\"}} \n               if arg == nil {\n                  return 0, errors.New(\"wrong type\") \n               }\n\n               str, ok := arg.(string) \n               if ok {\n                  val, err := strconv.Atoi(str)                  \n                  return int32(val), err\n               }\n\n               fl, ok := arg.(float64)\n               if ok {\n                  return int(fl), nil\n               }\n\n               return arg.(int), nil\n            {{else}}\n               obj := {{ .Package}}.{{ .RichType.SimpleType }}{}\n               err := mapstructure.Decode(arg, &obj)\n\n               {{if .RichType.Pointer }} \n               return &obj, err   \n               {{ else }}\n               return obj, err   \n               {{ end }}\n\n            {{end}}\n{{end}}\n\n{{define \"argCast\" }}\n{{if .RichType.Array -}} \n      argsSlice := arg.([]interface{}) \n      outArray := make([]{{ .RichType.SimpleType }}, 0, len(argsSlice))\n\n      for _, element := range argsSlice {\n         item, err := func(arg interface{}) ({{ .RichType.SimpleType }}, error) {\n            {{template \"atomTypeCast\" .RichType}}\n         }(element)\n\n         if err != nil {\n            return outArray, err\n         }\n\n         outArray = append(outArray, item)         \n      }\n\n      return outArray, nil\n{{else -}}\n{{template \"typeCast\" . -}}\n{{end -}}\n{{end -}}\n\n{{- if .Subscription }}\n/**{{range $_, $item := .Comments}}\n * {{ $item }}{{end}}\n */\nfunc subscribeTo{{ .Name }}(callData map[string]interface{}, event goapi.EventCallback) (goapi.Subscription, error) {\n   {{- $length := len .Params }}\n   {{ if gt $length 0 -}}\n   ________args, ok := callData[\"args\"].([]interface{})\n   if !ok {\n      return nil,errors.New(\"not able to cast args, wrong type\")\n   }\n   {{ end -}}\n\n   {{ range $index, $item := .Params }}\n   {{ $item.Name }}, err := func(arg interface{}) ({{ $item.Type }}, error) { {{ template \"argCast\" $item }}\n   }(________args[{{$index}}])\n   if err != nil {\n      return nil, err\n   }{{ end }}\n\n   return {{ .Package}}.{{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }},{{end}}event)\n}\n\n/**{{range $_, $item := .Comments}}\n * {{ $item }}{{end}}\n */\nfunc subscriptionTypes{{ .Name }}(________args []interface{}) ([]interface{}, error) {\n   result := make([]interface{}, 0, len(________args))\n   {{ range $index, $item := .Params -}}\n   {{ $item.Name }}, err := func(arg interface{}) ({{ $item.Type }}, error) { {{ template \"argCast\" $item }}\n   }(________args[{{$index}}])\n   if err != nil {\n      return nil, err\n   }\n   result = append(result, {{ $item.Name }})\n   {{ end }}\n\n   return result, nil\n}\n{{- else }}\nfunc callAdapterFor{{ .Name }}(callData map[string]interface{}, callback goapi.JsCallback) error {\n   {{- $length := len .Params }}\n   {{ if gt $length 0 -}}\n   ________args, ok := callData[\"args\"].([]interface{})\n   if !ok {\n      return errors.New(\"not able to cast args, wrong type\")\n   }\n   {{ end }}\n   {{ range $index, $item := .Params}}\n   {{ $item.Name }}, err := func(arg interface{}) ({{if $item.RichType.Object }}{{ $item.Package}}.{{end}}{{ $item.Type }}, error) { {{ template \"argCast\" $item }}\n   }(________args[{{$index}}])\n   if err != nil {\n      return err\n   }{{ end }}\n\n   {{ .Package }}.{{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }}, {{end}} callback)\n   return nil\n}\n{{- end }}\n"
-var _Assets92c913ced1d27143d20f01dabffaabc15c750cd9 = "func {{ .Name }}({{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }} {{ $item.Type }}{{end}})  {\n   {{ .Package }}.{{ .Name }}({{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}})\n} \n"
-var _Assets0533dda96e7fff99871c332d671b47636555c60c = "package {{.Package}}\n\nimport (\n\t\"encoding/json\"\n\t\"log\"\n\t\"errors\"\n\t\"strconv\"\n\t{{if .Dev -}}\n\t\"net/http\"\n\t\"fmt\"\n\t\"os\"{{end}}\n\t\"gitlab.vmassive.ru/wand/goapi\"\n\t\"github.com/mitchellh/mapstructure\"\n\t\"{{ .SourcePackage }}\"\n\t{{if .Dev}}\"gitlab.vmassive.ru/wand/goapi/remgo\"{{end}}\n)\n\n// Registry for all calls\nvar registry goapi.JsRegistry = goapi.NewJsRegistry()\n\n\ntype X_____xxxx struct { Val string }\nfunc Ping____(number int) string {\n\treturn strconv.Itoa(number)\n}\n\nfunc Ping____XXX(val interface{}) string {\n\tdata := X_____xxxx{}\n\tmapstructure.Decode(val, &val)\n\treturn data.Val\n}\n\n{{if .Dev }}\nfunc serveHome(w http.ResponseWriter, r *http.Request) {\n\tlog.Println(r.URL)\n\tif r.URL.Path != \"/\" {\n\t\thttp.Error(w, \"Not found\", http.StatusNotFound)\n\t\treturn\n\t}\n\tif r.Method != \"GET\" {\n\t\thttp.Error(w, \"Method not allowed\", http.StatusMethodNotAllowed)\n\t\treturn\n\t}\n\thttp.ServeFile(w, r, \"home.html\")\n}\n\n\nfunc SetCors(h http.Handler) http.Handler {\n\treturn http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n\t\tw.Header().Set(\"Access-Control-Allow-Origin\", \"*\")\n\t\tw.Header().Set(\"Access-Control-Allow-Methods\", \"POST, GET, OPTIONS, PUT, DELETE\")\n\t\tw.Header().Set(\"Access-Control-Allow-Headers\", \"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization\")\n\n\t\tif (*r).Method == \"OPTIONS\" {\n\t\t\treturn\n\t\t}\n\n\t\th.ServeHTTP(w, r)\n\t})\n}\n\nfunc main() {\n\thub := remgo.NewHub()\n\tgo hub.Run(&registry)\n\n  goPath := os.Getenv(\"GOPATH\")\n\tfs := http.FileServer(http.Dir(goPath  + \"/src/\"))\n\thandler := http.StripPrefix(\"/file\" + goPath + \"/src/\", fs)\n\n\tfmt.Printf(\"serving files in %s\\n\", goPath)\n\n\thttp.Handle(\"/file/\", SetCors(handler))\n\n\thttp.HandleFunc(\"/\", serveHome)\n\thttp.HandleFunc(\"/ws\", func(w http.ResponseWriter, r *http.Request) {\n\t\tremgo.ServeWs(&registry, hub, w, r)\n\t})\n\terr := http.ListenAndServe(\"0.0.0.0:9009\", nil)\n\tif err != nil {\n\t\tlog.Fatal(\"ListenAndServe: \", err)\n\t}\n}\n{{end}}\n// JsCallback the interface for any callbacks\ntype JsCallback interface {\n\tOnSuccess(json string)\n\tOnError(json string)\n}\n\n// JsEvent the interface for any events\ntype JsEvent interface {\n\tOnEvent(eventName string, json string)\n}\n\ntype eventerSender struct {\n\tevent\t\tJsEvent\n}\n\nfunc newEventSender(event JsEvent) goapi.JsEvent {\n\treturn &eventerSender{\n\t\tevent: event,\n\t}\n}\n\nfunc (eventer eventerSender) OnEvent(eventName string, data interface{}) {\n\tlog.Printf(\" >> + << %#v\", data)\n\tbytes, _ := json.Marshal(data)\n\teventer.event.OnEvent(eventName, string(bytes))\n}\n\ntype callbackCaller struct {\n\tcallback JsCallback\n}\n\nfunc (caller callbackCaller) OnSuccess(data interface{}) {\n\tlog.Printf(\" >> + << %#v\", data)\n\tbytes, _ := json.Marshal(data)\n\tcaller.callback.OnSuccess(string(bytes))\n}\n\nfunc (caller callbackCaller) OnError(data interface{}) {\n\tbytes, _ := json.Marshal(data)\n\tcaller.callback.OnError(string(bytes))\n}\n\nfunc newCaller(callback JsCallback) goapi.JsCallback {\n\treturn &callbackCaller{\n\t\tcallback: callback,\n\t}\n}\n\n// CallMethod - call from JS\nfunc CallMethod(callData string, callback JsCallback) {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tcaller := newCaller(callback)\n\tregistry.Call(methodCallData, caller)\n}\n\n\nfunc RegisterEventCallback(callback JsEvent) {\n\tregistry.RegisterEventCallback(newEventSender(callback))\n}\n\nfunc RemoveEventCallback() {\n\tregistry.RegisterEventCallback(nil)\n}\n\n// Subscribe - subsribe from JS\nfunc Subscribe(callData string) {\n\tmethodCallData := make(map[string]interface{})\n\tjson.Unmarshal([]byte(callData), &methodCallData)\n\n\tregistry.Subscribe(methodCallData)\n}\n\n// Subscribe - subsribe from JS\nfunc Cancel(callData string) {\n\tmethodCallData := make(map[string]interface{})\n\tregistry.CancelSubscription(methodCallData)\n}\n"
+var _Assets5ee76a8e8ec8d6750c9c1ac841dc306ce4982b9f = "/**\n * Jest mock of {{ .PackageName }}.js, generated by wand\n * @flow\n *\n *    jest.mock('../{{ .PackageName }}')\n *    import { mocks, resetMocks } from '../__mocks__/{{ .PackageName }}'\n *\n *    mocks.getUser.mockResolve(user)\n *    mocks.watchUser.emit(user)\n *    mocks.getUser.expectCalledWith('42')\n *    mocks.listUsers.mockItems([user])\n *\n * The state of the mocks is shared by the mocked module and this file,\n * when both are imported in the same test.\n */\n{{ if .Structures }}\nimport type {\n{{- range $_, $name := .Structures }}\n   {{ $name }},\n{{- end }}\n} from '../{{ .PackageName }}';\n{{ end }}\ntype GoSubscription = {\n   name: string,\n   args: any[],\n   subscription: { remove: () => void },\n};\n\ntype MockResult<Result> = {\n   value?: Result,\n   error?: any,\n   once: boolean,\n};\n\nexport class MockCall<Args, Result> {\n   name: string\n   calls: Args[] = []\n   results: MockResult<Result>[] = []\n\n   constructor(name: string) {\n      this.name = name\n   }\n\n   // mockResolve resolves every call with the value\n   mockResolve(value: Result): this {\n      this.results = [...this.results.filter(result => result.once), { value, once: false }]\n      return this\n   }\n\n   // mockResolveOnce resolves the next call with the value\n   mockResolveOnce(value: Result): this {\n      this.results = [...this.results.filter(result => result.once), { value, once: true }, ...this.results.filter(result => !result.once)]\n      return this\n   }\n\n   // mockReject rejects every call with the error\n   mockReject(error: any): this {\n      this.results = [...this.results.filter(result => result.once), { error, once: false }]\n      return this\n   }\n\n   // mockRejectOnce rejects the next call with the error\n   mockRejectOnce(error: any): this {\n      this.results = [...this.results.filter(result => result.once), { error, once: true }, ...this.results.filter(result => !result.once)]\n      return this\n   }\n\n   invoke(args: Args): Promise<Result> {\n      this.calls = [...this.calls, args]\n\n      const result = this.results[0]\n      if (result === undefined) {\n         return Promise.reject(new Error(`${this.name} is not mocked, use mocks.${this.name}.mockResolve`))\n      }\n\n      if (result.once) {\n         this.results = this.results.slice(1)\n      }\n\n      if (result.error !== undefined) {\n         return Promise.reject(result.error)\n      }\n\n      return Promise.resolve((result.value: any))\n   }\n\n   lastCall(): ?Args {\n      return this.calls[this.calls.length - 1]\n   }\n\n   expectCalled(times?: number) {\n      if (times === undefined) {\n         expect(this.calls.length).toBeGreaterThan(0)\n      } else {\n         expect(this.calls.length).toBe(times)\n      }\n   }\n\n   expectNotCalled() {\n      expect(this.calls.length).toBe(0)\n   }\n\n   expectCalledWith(...args: Args) {\n      expect(this.calls).toContainEqual(args)\n   }\n\n   expectLastCalledWith(...args: Args) {\n      expect(this.lastCall()).toEqual(args)\n   }\n\n   reset() {\n      this.calls = []\n      this.results = []\n   }\n}\n\ntype MockSubscriber<Args, Event> = {\n   args: Args,\n   callback: (e: Event) => void,\n   active: boolean,\n};\n\nexport class MockSubscription<Args, Event> {\n   name: string\n   subscribers: MockSubscriber<Args, Event>[] = []\n\n   constructor(name: string) {\n      this.name = name\n   }\n\n   subscribe(args: Args, callback: (e: Event) => void): GoSubscription {\n      const subscriber = { args, callback, active: true }\n      this.subscribers = [...this.subscribers, subscriber]\n\n      return {\n         name: this.name,\n         args: (args: any),\n         subscription: { remove: () => { subscriber.active = false } },\n      }\n   }\n\n   cancel(subs: GoSubscription) {\n      subs.subscription.remove()\n   }\n\n   // emit sends the event to active subscribers, to ones with the same arguments when args are given\n   emit(event: Event, args?: Args) {\n      this.active(args).forEach(subscriber => subscriber.callback(event))\n   }\n\n   active(args?: Args): MockSubscriber<Args, Event>[] {\n      return this.subscribers.filter(subscriber => subscriber.active && (args === undefined || equal(subscriber.args, args)))\n   }\n\n   expectSubscribed(times?: number) {\n      if (times === undefined) {\n         expect(this.active().length).toBeGreaterThan(0)\n      } else {\n         expect(this.active().length).toBe(times)\n      }\n   }\n\n   expectSubscribedWith(...args: Args) {\n      expect(this.active(args).length).toBeGreaterThan(0)\n   }\n\n   reset() {\n      this.subscribers = []\n   }\n}\n\nexport class MockStream<Args, Item> {\n   name: string\n   calls: Args[] = []\n   items: Item[] = []\n   error: any = undefined\n   closed: number = 0\n\n   constructor(name: string) {\n      this.name = name\n   }\n\n   // mockItems makes every stream yield the items\n   mockItems(items: Item[]): this {\n      this.items = items\n      return this\n   }\n\n   // mockReject makes every stream fail with the error after the items\n   mockReject(error: any): this {\n      this.error = error\n      return this\n   }\n\n   invoke(args: Args): AsyncIterable<Item> {\n      this.calls = [...this.calls, args]\n\n      const stream = this\n      const items = this.items\n      const error = this.error\n\n      return {\n         [Symbol.asyncIterator]() {\n            let index = 0\n            let done = false\n\n            return {\n               next: () => {\n                  if (!done && index < items.length) {\n                     return Promise.resolve({ value: items[index++], done: false })\n                  }\n\n                  if (!done && error !== undefined) {\n                     done = true\n                     return Promise.reject(error)\n                  }\n\n                  done = true\n                  return Promise.resolve({ value: undefined, done: true })\n               },\n               return: () => {\n                  if (!done) {\n                     done = true\n                     stream.closed++\n                  }\n\n                  return Promise.resolve({ value: undefined, done: true })\n               },\n            }\n         },\n      }\n   }\n\n   expectCalledWith(...args: Args) {\n      expect(this.calls).toContainEqual(args)\n   }\n\n   // expectClosed checks that the reader stopped before the end of the stream\n   expectClosed(times?: number) {\n      if (times === undefined) {\n         expect(this.closed).toBeGreaterThan(0)\n      } else {\n         expect(this.closed).toBe(times)\n      }\n   }\n\n   reset() {\n      this.calls = []\n      this.items = []\n      this.error = undefined\n      this.closed = 0\n   }\n}\n\nfunction equal(left: any, right: any): boolean {\n   return JSON.stringify(left) === JSON.stringify(right)\n}\n\nfunction createMocks() {\n   return {\n{{- range $_, $item := .Functions }}\n      {{ $item.Name }}: {{ if $item.Subscription }}new MockSubscription<{{ template \"jsTuple\" $item.Params }}, {{ $item.Subscription }}>{{ else if $item.Stream }}new MockStream<{{ template \"jsTuple\" $item.Params }}, {{ $item.Stream }}>{{ else }}new MockCall<{{ template \"jsTuple\" $item.Params }}, {{ $item.ReturnType }}>{{ end }}('{{ $item.Name }}'),\n{{- end }}\n   }\n}\n\n// mocks of the functions, shared through the global object\nexport const mocks: $Call<typeof createMocks> = global.__wandMocks_{{ .PackageName }} || (global.__wandMocks_{{ .PackageName }} = createMocks());\n\n// resetMocks forgets calls, results and subscribers of every mock\nexport function resetMocks() {\n   Object.keys(mocks).forEach(name => (mocks: any)[name].reset())\n}\n\nexport class ValidationError extends Error {\n   fields: { path: string, rule: string, param: string, message: string }[]\n\n   constructor(fields: { path: string, rule: string, param: string, message: string }[]) {\n      super('validation failed')\n      this.fields = fields\n   }\n}\n\n// binaryFile is the file as it is passed to go, mocks get { $file: uri }\nexport function binaryFile(uri: string) : Uint8Array {\n   return ({ $file: uri }: any)\n}\n\nexport function subribeApiCall(name: string, args :any[], callback: (json : String) => void) : GoSubscription {\n   return (mocks: any)[name].subscribe(args, (event) => callback(JSON.stringify(event)))\n}\n\nexport async function cancelSubscriptionApiCall(subs : GoSubscription) : Promise<any> {\n   subs.subscription.remove()\n}\n\nexport function streamApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterable<any> {\n   return (mocks: any)[name].invoke(args)\n}\n\nexport async function runApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {\n   return JSON.stringify(await (mocks: any)[name].invoke(args))\n}\n{{ range $_, $item := .Functions }}\n{{- if $item.Subscription }}\nexport function {{ $item.Name }}({{ range $index, $param := $item.Params }}{{ $param.Name }}: {{ $param.Type }}, {{ end }}callback: (e: {{ $item.Subscription }}) => void) : GoSubscription {\n   return mocks.{{ $item.Name }}.subscribe([{{ template \"jsArgs\" $item.Params }}], callback)\n}\n{{ else if $item.Stream }}\nexport function {{ $item.Name }}({{ template \"jsParams\" $item.Params }}) : AsyncIterable<{{ $item.Stream }}> {\n   return mocks.{{ $item.Name }}.invoke([{{ template \"jsArgs\" $item.Params }}])\n}\n{{ else }}\nexport function {{ $item.Name }}({{ template \"jsParams\" $item.Params }}) : Promise<{{ $item.ReturnType }}> {\n   return mocks.{{ $item.Name }}.invoke([{{ template \"jsArgs\" $item.Params }}])\n}\n{{ end }}\n{{- end }}\n"
+var _Assets1b5a0b888703ccdfe91b8321278ad2de7284081c = "//\n// GoCall library binding, generated by wand\n//\n\n#import <React/RCTBridgeModule.h>\n#import <React/RCTEventEmitter.h>\n\n@interface GoCall : RCTEventEmitter <RCTBridgeModule>\n\n- (void)sendGoEvent:(NSString *)eventName json:(NSString *)json;\n\n@end\n"
+var _Assets1fc5d4d87680f2abfc9b2b8defb9450378e85cb7 = "package {{ .JavaPackage }};\n\nimport com.facebook.react.bridge.Promise;\nimport com.facebook.react.bridge.ReactApplicationContext;\nimport com.facebook.react.bridge.ReactContextBaseJavaModule;\nimport com.facebook.react.bridge.ReactMethod;\nimport com.facebook.react.modules.core.DeviceEventManagerModule;\n\nimport {{ .GoPackage }}.{{ .GoClass }};\nimport {{ .GoPackage }}.JsCallback;\nimport {{ .GoPackage }}.JsEvent;\n\n/**\n * GoCall library binding, generated by wand\n */\npublic class GoCallModule extends ReactContextBaseJavaModule {\n\n    // byte slices of the results larger than this go to JS as files of the cache directory\n    private static final long BINARY_THRESHOLD = 64 * 1024;\n\n    public GoCallModule(ReactApplicationContext reactContext) {\n        super(reactContext);\n    }\n\n    @Override\n    public String getName() {\n        return \"GoCall\";\n    }\n\n    @Override\n    public void initialize() {\n        super.initialize();\n\n        {{ .GoClass }}.setBinaryDir(getReactApplicationContext().getCacheDir().getAbsolutePath(), BINARY_THRESHOLD);\n        {{ .GoClass }}.registerEventCallback(new JsEvent() {\n            @Override\n            public void onEvent(String eventName, String json) {\n                getReactApplicationContext()\n                    .getJSModule(DeviceEventManagerModule.RCTDeviceEventEmitter.class)\n                    .emit(eventName, json);\n            }\n        });\n    }\n\n    @Override\n    public void onCatalystInstanceDestroy() {\n        {{ .GoClass }}.removeEventCallback();\n        super.onCatalystInstanceDestroy();\n    }\n\n    @ReactMethod\n    public void callMethod(String callData, final Promise promise) {\n        {{ .GoClass }}.callMethod(callData, new JsCallback() {\n            @Override\n            public void onSuccess(String json) {\n                promise.resolve(json);\n            }\n\n            @Override\n            public void onError(String json) {\n                promise.reject(\"GoCallError\", json);\n            }\n        });\n    }\n\n    @ReactMethod(isBlockingSynchronousMethod = true)\n    public String callMethodSync(String callData) {\n        return {{ .GoClass }}.callMethodSync(callData);\n    }\n\n    @ReactMethod\n    public void openStream(String callData, String streamID, int credits) {\n        {{ .GoClass }}.openStream(callData, streamID, credits);\n    }\n\n    @ReactMethod\n    public void streamCredit(String streamID, int count) {\n        {{ .GoClass }}.streamCredit(streamID, count);\n    }\n\n    @ReactMethod\n    public void closeStream(String streamID) {\n        {{ .GoClass }}.closeStream(streamID);\n    }\n\n    @ReactMethod\n    public void releaseBinary(String uri) {\n        {{ .GoClass }}.releaseBinary(uri);\n    }\n\n    @ReactMethod\n    public void subscribe(String callData) {\n        {{ .GoClass }}.subscribe(callData);\n    }\n\n    @ReactMethod\n    public void cancel(String callData, Promise promise) {\n        {{ .GoClass }}.cancel(callData);\n        promise.resolve(null);\n    }\n}\n"
+var _Assets65b8236b605607685be6c120d11359f4940bfbaa = "//\n// GoCall library binding, generated by wand\n//\n\n#import \"GoCall.h\"\n#import <{{ .GoClass }}/{{ .GoClass }}.h>\n\nstatic NSString *const GoCallEvent = @\"GoCallEvent\";\n\n// byte slices of the results larger than this go to JS as files of the caches directory\nstatic const long GoCallBinaryThreshold = 64 * 1024;\n\n@interface GoCallPromise : NSObject <{{ .GoClass }}JsCallback>\n\n@property (nonatomic, copy) RCTPromiseResolveBlock resolve;\n@property (nonatomic, copy) RCTPromiseRejectBlock reject;\n\n@end\n\n@implementation GoCallPromise\n\n- (void)onSuccess:(NSString *)json {\n  self.resolve(json);\n}\n\n- (void)onError:(NSString *)json {\n  self.reject(@\"GoCallError\", json, nil);\n}\n\n@end\n\n@interface GoCallEventSender : NSObject <{{ .GoClass }}JsEvent>\n\n@property (nonatomic, weak) GoCall *module;\n\n@end\n\n@implementation GoCallEventSender\n\n- (void)onEvent:(NSString *)eventName json:(NSString *)json {\n  [self.module sendGoEvent:eventName json:json];\n}\n\n@end\n\n@implementation GoCall {\n  BOOL hasListeners;\n  GoCallEventSender *eventSender;\n}\n\nRCT_EXPORT_MODULE();\n\n- (instancetype)init {\n  if (self = [super init]) {\n    NSString *caches = NSSearchPathForDirectoriesInDomains(NSCachesDirectory, NSUserDomainMask, YES).firstObject;\n    {{ .GoClass }}SetBinaryDir(caches, GoCallBinaryThreshold);\n  }\n\n  return self;\n}\n\n+ (BOOL)requiresMainQueueSetup {\n  return NO;\n}\n\n- (NSArray<NSString *> *)supportedEvents {\n  return @[GoCallEvent];\n}\n\n- (void)startObserving {\n  hasListeners = YES;\n\n  eventSender = [GoCallEventSender new];\n  eventSender.module = self;\n  {{ .GoClass }}RegisterEventCallback(eventSender);\n}\n\n- (void)stopObserving {\n  hasListeners = NO;\n\n  {{ .GoClass }}RemoveEventCallback();\n  eventSender = nil;\n}\n\n- (void)sendGoEvent:(NSString *)eventName json:(NSString *)json {\n  if (hasListeners) {\n    [self sendEventWithName:GoCallEvent body:@{@\"name\": eventName, @\"json\": json}];\n  }\n}\n\nRCT_EXPORT_METHOD(callMethod:(NSString *)callData\n                  resolver:(RCTPromiseResolveBlock)resolve\n                  rejecter:(RCTPromiseRejectBlock)reject) {\n  GoCallPromise *callback = [GoCallPromise new];\n  callback.resolve = resolve;\n  callback.reject = reject;\n\n  {{ .GoClass }}CallMethod(callData, callback);\n}\n\nRCT_EXPORT_BLOCKING_SYNCHRONOUS_METHOD(callMethodSync:(NSString *)callData) {\n  return {{ .GoClass }}CallMethodSync(callData);\n}\n\nRCT_EXPORT_METHOD(openStream:(NSString *)callData\n                  streamID:(NSString *)streamID\n                  credits:(NSInteger)credits) {\n  {{ .GoClass }}OpenStream(callData, streamID, credits);\n}\n\nRCT_EXPORT_METHOD(streamCredit:(NSString *)streamID\n                  count:(NSInteger)count) {\n  {{ .GoClass }}StreamCredit(streamID, count);\n}\n\nRCT_EXPORT_METHOD(closeStream:(NSString *)streamID) {\n  {{ .GoClass }}CloseStream(streamID);\n}\n\nRCT_EXPORT_METHOD(releaseBinary:(NSString *)uri) {\n  {{ .GoClass }}ReleaseBinary(uri);\n}\n\nRCT_EXPORT_METHOD(subscribe:(NSString *)callData) {\n  {{ .GoClass }}Subscribe(callData);\n}\n\nRCT_EXPORT_METHOD(cancel:(NSString *)callData\n                  resolver:(RCTPromiseResolveBlock)resolve\n                  rejecter:(RCTPromiseRejectBlock)reject) {\n  {{ .GoClass }}Cancel(callData);\n  resolve(nil);\n}\n\n@end\n"
+var _Assets500f66c87a3d0fb7bdc8df7ccba9449196609d7f = "package {{ .JavaPackage }};\n\nimport java.util.Collections;\nimport java.util.List;\n\nimport com.facebook.react.ReactPackage;\nimport com.facebook.react.bridge.NativeModule;\nimport com.facebook.react.bridge.ReactApplicationContext;\nimport com.facebook.react.uimanager.ViewManager;\n\n/**\n * GoCall package, add it to getPackages() of your MainApplication\n */\npublic class GoCallPackage implements ReactPackage {\n\n    @Override\n    public List<NativeModule> createNativeModules(ReactApplicationContext reactContext) {\n        return Collections.<NativeModule>singletonList(new GoCallModule(reactContext));\n    }\n\n    @Override\n    public List<ViewManager> createViewManagers(ReactApplicationContext reactContext) {\n        return Collections.emptyList();\n    }\n}\n"
+var _Assets7b2418a5868bb4a4fcc5b1587c35f0366a17b4fc = "{{- /* partials shared by js templates, executed with []js.Field */ -}}\n\n{{- define \"jsArgs\" -}}\n{{ range $index, $item := . }}{{ if $index }}, {{ end }}{{ $item.Name }}{{ end }}\n{{- end -}}\n\n{{- define \"jsCallArgs\" -}}\n{{- /* required arguments by position, optional ones by go parameter name, so missing ones get defaults */ -}}\n{{- $optional := false -}}\n[{{ range $index, $item := . }}{{ if $item.Optional }}{{ $optional = true }}{{ else }}{{ if $index }}, {{ end }}{{ $item.Name }}{{ end }}{{ end }}]\n{{- if $optional }}, { {{ $first := true }}{{ range $_, $item := . }}{{ if $item.Optional }}{{ if not $first }}, {{ end }}{{ $first = false }}{{ $item.Name }}{{ end }}{{ end }} }{{ end }}\n{{- end -}}\n\n{{- define \"jsParams\" -}}\n{{ range $index, $item := . }}{{ if $index }}, {{ end }}{{ $item.Name }}{{ if $item.Optional }}?{{ end }}: {{ $item.Type }}{{ end }}\n{{- end -}}\n\n{{- define \"jsTuple\" -}}\n[{{ range $index, $item := . }}{{ if $index }}, {{ end }}{{ $item.Type }}{{ if $item.Optional }} | void{{ end }}{{ end }}]\n{{- end -}}\n\n{{- define \"jsRules\" -}}\n[{{ range $index, $rule := . }}{{ if $index }}, {{ end }}[{{ printf \"%q\" $rule.Name }}, {{ printf \"%q\" $rule.Param }}]{{ end }}]\n{{- end -}}\n\n{{- define \"jsValidateType\" -}}\n{{ if .ValidateType }}{{ printf \"%q\" .ValidateType }}{{ else }}null{{ end }}\n{{- end -}}\n\n{{- define \"jsValidate\" -}}\nvalidateArgs([{{ range $_, $item := . }}{{ if $item.Validate }}\n      [{{ printf \"%q\" $item.Name }}, {{ $item.Name }}, {{ template \"jsRules\" $item.Rules }}, {{ template \"jsValidateType\" $item }}],{{ end }}{{ end }}\n   ])\n{{- end -}}\n"
+var _Assets92c913ced1d27143d20f01dabffaabc15c750cd9 = "{{ docComment .Comments }}\nfunc callAdapterFor{{ .Name }}(callData map[string]interface{}, callback goapi.JsCallback) error {\n   {{ template \"decodeArgs\" . }}\n   {{ template \"validateArgs\" . }}\n   {{ range $index, $item := .Results }}{{ if $index }}, {{ end }}{{ $item }}{{ end }}{{ if .Results }} := {{ end }}{{ .Package }}.{{ .Name }}({{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}})\n   {{- if .HasError }}\n   if ________err != nil {\n      return ________err\n   }\n   {{- end }}\n\n   callback.OnSuccess({{ if .HasResult }}________result{{ else }}nil{{ end }})\n   return nil\n}\n"
+var _Assetsfce71382fed38a9ea29c66065721ca08c06ec158 = "\n{{ docComment .Comments }}\nfunc streamAdapterFor{{ .Name }}(callData map[string]interface{}, stream goapi.Stream) error {\n   {{ template \"decodeArgs\" . }}\n   {{ template \"validateArgs\" . }}\n   {{- if .StreamChannel }}\n   ________items{{ if .HasError }}, ________err{{ end }} := {{ .Package }}.{{ .Name }}({{range $index, $item := .Params}}{{if $index}}, {{end}}{{ $item.Name }}{{end}})\n   {{- if .HasError }}\n   if ________err != nil {\n      return ________err\n   }\n   {{- end }}\n\n   for ________item := range ________items {\n      if err := stream.Send(________item); err != nil {\n         // js stopped reading, the producer must not block on the channel\n         go func() {\n            for range ________items {\n            }\n         }()\n         return nil\n      }\n   }\n\n   return nil\n   {{- else }}\n   {{ if .HasError }}return {{ end }}{{ .Package }}.{{ .Name }}({{range $index, $item := .Params}}{{ $item.Name }}, {{end}}stream)\n   {{- if not .HasError }}\n   return nil\n   {{- end }}\n   {{- end }}\n}\n"
+var _Assets1ad8fcf1a012f1e976cdf59512da19e040972051 = "{{ docComment .Comments }}\nexport type {{ .Name }}{{ if .TypeParams }}<{{ range $index, $item := .TypeParams }}{{ if $index }}, {{ end }}{{ $item }}{{ end }}>{{ end }} = { {{range $_, $item := .Field}} {{range $_, $comment := $item.Comment }}\n    // {{ $comment }} {{end}}\n    {{ $item.Name }}{{ if $item.Optional }}?{{ end }}: {{ $item.Type }}, {{end}}\n}\n"
+var _Assetsbc844896c95f6209e3a41025614823bcac921292 = "{{- /* rules of validated structures, executed with []js.ValidationType */ -}}\n\nconst validationRules = {\n{{- range $_, $type := . }}\n  {{ $type.Name }}: {\n  {{- range $_, $field := $type.Field }}\n    {{ printf \"%q\" $field.Name }}: { rules: {{ template \"jsRules\" $field.Rules }}, type: {{ template \"jsValidateType\" $field }} },\n  {{- end }}\n  },\n{{- end }}\n}\n"
+var _Assets98270d80a614210b33d5a2aec48a956c8db8b424 = "\ntype {{ .Name }}Prop = {\n  {{range $index, $item := .Props}}\n  {{ $item.Name }}: {{ $item.Type }},{{end}}\n}\n\nexport function with{{ .Name }}<Props: {} & {{ .Name }}Prop>(\n  WrappedComponent : ComponentType<{{ .Name }}Prop & { {{ .VarName }} : {{ .Name }} }>\n) : ComponentType<Props> {\n  return class {{ .Name }}DataView extends Component<Props, *> {\n    {{ if .Update }}subscription = undefined {{end}}\n\n    constructor(props: Props) {\n      super(props);\n      this.state = {  {{ .VarName }} :  undefined }\n    }\n\n    componentDidMount() {\n      {{ if .Props }}\n      const { {{ template \"jsArgs\" .Props }}} = this.props\n      {{ end }}\n\n      {{ if .Get }}\n      {{ .Get.Name }}({{ template \"jsArgs\" .Props }})\n        .then(this.onValue)\n      {{ end }}\n      {{ if .Update }}\n      this.subscription = {{ .Update.Name  }}({{range $index, $item := .Props}}{{ $item.Name }},{{end}}this.onValue)\n      {{ end }}\n    }\n\n    onValue = ({{ .VarName }}: {{ .Name }}) => {\n      this.setState({ {{ .VarName }} })\n    }\n\n    componentWillUnmount() {\n     {{ if .Update }}cancelSubscriptionApiCall(this.subscription)\n     {{ end }}\n    }\n\n    render() {\n      const {  {{ .VarName }}  } = this.state;\n\n      return <WrappedComponent\n                {{ .VarName }}={  {{ .VarName }}  }\n                {...this.props} />\n    }\n  }\n}\n"
 
 // Assets returns go-assets FileSystem
-var Assets = assets.NewFileSystem(map[string][]string{"/": []string{"templates"}, "/templates": []string{"callmap.go.tmpl", "struct.js.tmpl", "headWith.js.tmpl", "func.js.tmpl", "func.go.tmpl", "pure.go.tmpl", "with.js.tmpl", "head.js.tmpl", "head.go.tmpl"}}, map[string]*assets.File{
-	"/templates": &assets.File{
+var Assets = assets.NewFileSystem(map[string][]string{"/": []string{"templates"}, "/templates": []string{"callmap.go.tmpl", "func.go.tmpl", "func.js.tmpl", "head.go.tmpl", "head.js.tmpl", "headWith.js.tmpl", "mock.js.tmpl", "module.h.tmpl", "module.java.tmpl", "module.m.tmpl", "package.java.tmpl", "partials.js.tmpl", "pure.go.tmpl", "stream.go.tmpl", "struct.js.tmpl", "validation.js.tmpl", "with.js.tmpl"}}, map[string]*assets.File{
+	"/": &assets.File{
+		Path:     "/",
+		FileMode: 0x800001ed,
+		Mtime:    time.Unix(1543914237, 1543914237372695923),
+		Data:     nil,
+	}, "/templates": &assets.File{
 		Path:     "/templates",
 		FileMode: 0x800001ed,
 		Mtime:    time.Unix(1543910948, 1543910948206846959),
@@ -26,51 +39,86 @@ var Assets = assets.NewFileSystem(map[string][]string{"/": []string{"templates"}
 	}, "/templates/callmap.go.tmpl": &assets.File{
 		Path:     "/templates/callmap.go.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543219321, 1543219321979688793),
+		Mtime:    time.Unix(1792420287, 1792420287789616108),
 		Data:     []byte(_Assets2b7ef9c09e8e120a968348f6a0414b24b022fd53),
-	}, "/templates/struct.js.tmpl": &assets.File{
-		Path:     "/templates/struct.js.tmpl",
+	}, "/templates/func.go.tmpl": &assets.File{
+		Path:     "/templates/func.go.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543219321, 1543219321981672778),
-		Data:     []byte(_Assets1ad8fcf1a012f1e976cdf59512da19e040972051),
+		Mtime:    time.Unix(1792419384, 1792419384990259930),
+		Data:     []byte(_Assets3b4dd3b006d667b8c4ecbff30ccd3c1161228224),
+	}, "/templates/func.js.tmpl": &assets.File{
+		Path:     "/templates/func.js.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792420371, 1792420371574560314),
+		Data:     []byte(_Assets693a5f743ee831585a375e293545870ad9775df1),
+	}, "/templates/head.go.tmpl": &assets.File{
+		Path:     "/templates/head.go.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792421732, 1792421732740230631),
+		Data:     []byte(_Assets0533dda96e7fff99871c332d671b47636555c60c),
+	}, "/templates/head.js.tmpl": &assets.File{
+		Path:     "/templates/head.js.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792421732, 1792421732740230631),
+		Data:     []byte(_Assets382e2ce7c28616d04646a0aad561f67e2a6e1c39),
 	}, "/templates/headWith.js.tmpl": &assets.File{
 		Path:     "/templates/headWith.js.tmpl",
 		FileMode: 0x1a4,
 		Mtime:    time.Unix(1543412756, 1543412756246955551),
 		Data:     []byte(_Assetsd70499efd324d14a684d938f753ca0f22925c087),
-	}, "/templates/func.js.tmpl": &assets.File{
-		Path:     "/templates/func.js.tmpl",
+	}, "/templates/mock.js.tmpl": &assets.File{
+		Path:     "/templates/mock.js.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543585604, 1543585604534249602),
-		Data:     []byte(_Assets693a5f743ee831585a375e293545870ad9775df1),
-	}, "/templates/func.go.tmpl": &assets.File{
-		Path:     "/templates/func.go.tmpl",
+		Mtime:    time.Unix(1792419367, 1792419367681508424),
+		Data:     []byte(_Assets5ee76a8e8ec8d6750c9c1ac841dc306ce4982b9f),
+	}, "/templates/module.h.tmpl": &assets.File{
+		Path:     "/templates/module.h.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543417878, 1543417878454904514),
-		Data:     []byte(_Assets3b4dd3b006d667b8c4ecbff30ccd3c1161228224),
+		Mtime:    time.Unix(1792415640, 1792415640830983423),
+		Data:     []byte(_Assets1b5a0b888703ccdfe91b8321278ad2de7284081c),
+	}, "/templates/module.java.tmpl": &assets.File{
+		Path:     "/templates/module.java.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792419367, 1792419367681508424),
+		Data:     []byte(_Assets1fc5d4d87680f2abfc9b2b8defb9450378e85cb7),
+	}, "/templates/module.m.tmpl": &assets.File{
+		Path:     "/templates/module.m.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792419367, 1792419367681508424),
+		Data:     []byte(_Assets65b8236b605607685be6c120d11359f4940bfbaa),
+	}, "/templates/package.java.tmpl": &assets.File{
+		Path:     "/templates/package.java.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792415640, 1792415640830983423),
+		Data:     []byte(_Assets500f66c87a3d0fb7bdc8df7ccba9449196609d7f),
+	}, "/templates/partials.js.tmpl": &assets.File{
+		Path:     "/templates/partials.js.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792420371, 1792420371574560314),
+		Data:     []byte(_Assets7b2418a5868bb4a4fcc5b1587c35f0366a17b4fc),
 	}, "/templates/pure.go.tmpl": &assets.File{
 		Path:     "/templates/pure.go.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543219321, 1543219321981220251),
+		Mtime:    time.Unix(1792420977, 1792420977692979493),
 		Data:     []byte(_Assets92c913ced1d27143d20f01dabffaabc15c750cd9),
-	}, "/templates/head.go.tmpl": &assets.File{
-		Path:     "/templates/head.go.tmpl",
+	}, "/templates/stream.go.tmpl": &assets.File{
+		Path:     "/templates/stream.go.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1544101440, 1544101440394019108),
-		Data:     []byte(_Assets0533dda96e7fff99871c332d671b47636555c60c),
-	}, "/": &assets.File{
-		Path:     "/",
-		FileMode: 0x800001ed,
-		Mtime:    time.Unix(1543914237, 1543914237372695923),
-		Data:     nil,
+		Mtime:    time.Unix(1792418976, 1792418976752611168),
+		Data:     []byte(_Assetsfce71382fed38a9ea29c66065721ca08c06ec158),
+	}, "/templates/struct.js.tmpl": &assets.File{
+		Path:     "/templates/struct.js.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792416953, 1792416953433556678),
+		Data:     []byte(_Assets1ad8fcf1a012f1e976cdf59512da19e040972051),
+	}, "/templates/validation.js.tmpl": &assets.File{
+		Path:     "/templates/validation.js.tmpl",
+		FileMode: 0x1a4,
+		Mtime:    time.Unix(1792417195, 1792417195724025231),
+		Data:     []byte(_Assetsbc844896c95f6209e3a41025614823bcac921292),
 	}, "/templates/with.js.tmpl": &assets.File{
 		Path:     "/templates/with.js.tmpl",
 		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543405268, 1543405268596567788),
+		Mtime:    time.Unix(1792417957, 1792417957765007106),
 		Data:     []byte(_Assets98270d80a614210b33d5a2aec48a956c8db8b424),
-	}, "/templates/head.js.tmpl": &assets.File{
-		Path:     "/templates/head.js.tmpl",
-		FileMode: 0x1a4,
-		Mtime:    time.Unix(1543930723, 1543930723051139091),
-		Data:     []byte(_Assets382e2ce7c28616d04646a0aad561f67e2a6e1c39),
 	}}, "")
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+var errDrift = errors.New("generated code is out of date, run wand generate")
+
+func checkApplication(configName string, dev bool) error {
+	codeList, err := createCodeList(configName, dev)
+	if err != nil {
+		return err
+	}
+
+	err = ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	sink := generator.NewMemorySink()
+	err = Generate(codeList, sink)
+	if err != nil {
+		return err
+	}
+
+	drift, err := CheckDrift(sink)
+	if err != nil {
+		return err
+	}
+
+	if drift {
+		return errDrift
+	}
+
+	log.Printf("generated code is up to date")
+	return nil
+}
+
+// CheckDrift - print unified diff for every generated file which differs from the disk
+func CheckDrift(sink *generator.MemorySink) (bool, error) {
+	drift := false
+
+	for _, name := range sink.Names() {
+		current, err := ioutil.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			log.Errorf("failed to read %s: %v", name, err)
+			return drift, err
+		}
+
+		expected := sink.Files[name]
+		if string(current) == string(expected) {
+			continue
+		}
+
+		drift = true
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(current)),
+			B:        difflib.SplitLines(string(expected)),
+			FromFile: name,
+			ToFile:   name + " (generated)",
+			Context:  3,
+		})
+		if err != nil {
+			return drift, err
+		}
+
+		fmt.Print(diff)
+	}
+
+	return drift, nil
+}
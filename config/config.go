@@ -11,23 +11,71 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
+// Export modes of the source functions
+const (
+	// ExportAll bridges every exported function, it is the default
+	ExportAll = "all"
+	// ExportAnnotated bridges only functions with //wand:export directive
+	ExportAnnotated = "annotated"
+)
+
 type Source struct {
 	Package string
+	// Export mode, "all" or "annotated"
+	Export string
+	// Include name patterns of the functions to bridge, like "Get*", all when empty
+	Include []string
+	// Exclude name patterns of the functions which are not bridged
+	Exclude []string
 }
 
 type Js struct {
 	Path string
+	// Mocks generates jest mock of the module in __mocks__
+	Mocks bool
 }
 
 type Wrapper struct {
 	Package string
 	Port    int16
+	// Bridge websocket options of the development server
+	Bridge Bridge
+}
+
+// Bridge limits and compression of the development server websocket, zero values are defaults
+type Bridge struct {
+	// MaxMessageSize limit of the inbound message in bytes
+	MaxMessageSize int64
+	// Compression enables permessage-deflate when the client supports it
+	Compression bool
+	// CompressionLevel flate level from -2 to 9, 0 is no compression, the default level when it is not set
+	CompressionLevel *int
+	// MaxBatchSize limit of the requests in one batch
+	MaxBatchSize int
+}
+
+type Android struct {
+	Path    string
+	Package string
+	Output  string
+}
+
+type Ios struct {
+	Path   string
+	Output string
+}
+
+type Native struct {
+	Android Android
+	Ios     Ios
 }
 
 type Configuration struct {
-	Source  Source
-	Wrapper Wrapper
-	Js      Js
+	Source    Source
+	Wrapper   Wrapper
+	Js        Js
+	Native    Native
+	Templates string
 }
 
 func requestString(name string) string {
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+var errStrict = errors.New("source has problems, see the diagnostics above")
+
+// diagnostics collects problems of one file with positions of its file set
+type diagnostics struct {
+	fset *token.FileSet
+	list []generator.Diagnostic
+}
+
+func newDiagnostics(fset *token.FileSet) *diagnostics {
+	return &diagnostics{
+		fset: fset,
+		list: make([]generator.Diagnostic, 0),
+	}
+}
+
+func (diag *diagnostics) add(pos token.Pos, severity generator.Severity, code string, format string, args ...interface{}) {
+	diag.list = append(diag.list, generator.Diagnostic{
+		Position: diag.fset.Position(pos),
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (diag *diagnostics) warnf(pos token.Pos, code string, format string, args ...interface{}) {
+	diag.add(pos, generator.SeverityWarning, code, format, args...)
+}
+
+func (diag *diagnostics) errorf(pos token.Pos, code string, format string, args ...interface{}) {
+	diag.add(pos, generator.SeverityError, code, format, args...)
+}
+
+// reportDiagnostics prints diagnostics compiler style, strict code list fails on any of them
+func reportDiagnostics(codeList *generator.CodeList) error {
+	for _, diagnostic := range codeList.Diagnostics {
+		fmt.Fprintln(os.Stderr, diagnostic.String())
+	}
+
+	if codeList.Strict && len(codeList.Diagnostics) > 0 {
+		return errStrict
+	}
+
+	return nil
+}
+
+// builtinTypes predeclared types which are not basic types of generator
+var builtinTypes = map[string]bool{"any": true, "error": true, "byte": true, "rune": true}
+
+// checkType reports parts of the type which js can not get: functions,
+// channels, anonymous structs, unexported types and types of other packages
+func checkType(diag *diagnostics, expr ast.Expr, where string, typeParams map[string]bool) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.Ident:
+			if !x.IsExported() && !generator.IsBasic(x.Name) && !builtinTypes[x.Name] && !typeParams[x.Name] {
+				diag.warnf(x.Pos(), generator.DiagUnexportedType, "%s uses unexported type %s", where, x.Name)
+			}
+
+		case *ast.SelectorExpr:
+			if pkg, ok := x.X.(*ast.Ident); !ok || pkg.Name != "goapi" {
+				diag.warnf(x.Pos(), generator.DiagUnsupportedType, "%s uses type %s of another package, it becomes %s", where, typeString(x), generator.JsType(generator.ParseType(x)))
+			}
+			return false
+
+		case *ast.FuncType, *ast.ChanType, *ast.StructType, *ast.Ellipsis:
+			diag.warnf(x.Pos(), generator.DiagUnsupportedType, "%s uses unsupported type %s, it becomes any", where, typeString(x.(ast.Expr)))
+			return false
+
+		case *ast.InterfaceType:
+			if x.Methods != nil && len(x.Methods.List) > 0 {
+				diag.warnf(x.Pos(), generator.DiagUnsupportedType, "%s uses interface with methods, it becomes any", where)
+			}
+			return false
+		}
+
+		return true
+	})
+}
+
+func typeString(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.SelectorExpr:
+		return typeString(x.X) + "." + x.Sel.Name
+	case *ast.Ident:
+		return x.Name
+	case *ast.FuncType:
+		return "func"
+	case *ast.ChanType:
+		return "chan"
+	case *ast.StructType:
+		return "struct"
+	case *ast.Ellipsis:
+		return "..."
+	}
+
+	return fmt.Sprintf("%T", expr)
+}
+
+func stringSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, item := range list {
+		set[item] = true
+	}
+
+	return set
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/generator"
+	"gitlab.vmassive.ru/wand/schema"
+)
+
+// Dump intermediate representation of the source package, as generators see it
+type Dump struct {
+	PackageName string
+	Enums       []generator.ExportedEnum
+	Structures  []generator.ExportedStucture
+	Functions   []generator.FunctionData
+	Pure        []generator.FunctionData
+}
+
+func dumpApplication(configName string, dev bool) error {
+	codeList, err := createCodeList(configName, dev)
+	if err != nil {
+		return err
+	}
+
+	err = ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(Dump{
+		PackageName: codeList.PackageName,
+		Enums:       codeList.Enums,
+		Structures:  codeList.Structures,
+		Functions:   codeList.Functions,
+		Pure:        codeList.Pure,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func schemaApplication(configName string, out string) error {
+	codeList, err := createCodeList(configName, false)
+	if err != nil {
+		return err
+	}
+
+	err = ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return schema.New(codeList.PathMap.Js, codeList.PackageName).CreateCode(codeList, generator.NewDiskSink())
+	}
+
+	data, err := schema.Marshal(schema.Build(codeList))
+	if err != nil {
+		return err
+	}
+
+	if out == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	err = generator.NewDiskSink().WriteFile(out, data)
+	if err == nil {
+		log.Printf("created %s", out)
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"gitlab.vmassive.ru/wand/config"
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+// exportControl decides which exported functions of the source are bridged
+type exportControl struct {
+	annotated bool
+	include   []string
+	exclude   []string
+}
+
+// newExportControl export mode and name patterns of the source configuration
+func newExportControl(configuration *config.Configuration) (*exportControl, error) {
+	control := &exportControl{}
+	if configuration == nil {
+		return control, nil
+	}
+
+	source := configuration.Source
+	switch source.Export {
+	case "", config.ExportAll:
+	case config.ExportAnnotated:
+		control.annotated = true
+	default:
+		return nil, fmt.Errorf("unknown export mode %q, expected %q or %q", source.Export, config.ExportAll, config.ExportAnnotated)
+	}
+
+	for _, pattern := range append(append([]string{}, source.Include...), source.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("wrong export pattern %q: %v", pattern, err)
+		}
+	}
+
+	control.include = source.Include
+	control.exclude = source.Exclude
+
+	return control, nil
+}
+
+// reason tells why the function is not bridged, empty when it is,
+// directives of the function win over the patterns and the mode
+func (control *exportControl) reason(function generator.FunctionData) string {
+	if findAnnotation(function.Annotation, "ignore") != nil {
+		return "//wand:ignore"
+	}
+
+	if findAnnotation(function.Annotation, "export") != nil {
+		return ""
+	}
+
+	for _, pattern := range control.exclude {
+		if matched, _ := path.Match(pattern, function.Name); matched {
+			return fmt.Sprintf("excluded by %q", pattern)
+		}
+	}
+
+	if len(control.include) > 0 && !control.included(function.Name) {
+		return "not included by any pattern"
+	}
+
+	if control.annotated {
+		return "no //wand:export in annotated mode"
+	}
+
+	return ""
+}
+
+func (control *exportControl) included(name string) bool {
+	for _, pattern := range control.include {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
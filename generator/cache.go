@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// FileDeclarations exported declarations found in one source file
+type FileDeclarations struct {
+	PackageName string
+	Enums       []ExportedEnum
+	Constants   []EnumValue
+	Structures  []ExportedStucture
+	Functions   []FunctionData
+	Pure        []FunctionData
+	Skipped     []SkippedFunction
+	// Diagnostics problems of the file, they are reported again when the file is taken from the cache
+	Diagnostics []Diagnostic
+}
+
+// Cache keeps declarations of parsed files by hash of their content and
+// fingerprints of generated outputs between runs
+type Cache struct {
+	files   map[string]*FileDeclarations
+	outputs map[string]string
+}
+
+// NewCache creates an empty cache, nil cache is valid and caches nothing
+func NewCache() *Cache {
+	return &Cache{
+		files:   make(map[string]*FileDeclarations),
+		outputs: make(map[string]string),
+	}
+}
+
+// File returns declarations of the file content parsed before
+func (cache *Cache) File(hash string) *FileDeclarations {
+	if cache == nil {
+		return nil
+	}
+
+	return cache.files[hash]
+}
+
+func (cache *Cache) StoreFile(hash string, declarations *FileDeclarations) {
+	if cache != nil {
+		cache.files[hash] = declarations
+	}
+}
+
+// Prune removes files which are not in the source anymore
+func (cache *Cache) Prune(used map[string]bool) {
+	if cache == nil {
+		return
+	}
+
+	for hash := range cache.files {
+		if !used[hash] {
+			delete(cache.files, hash)
+		}
+	}
+}
+
+// Changed tells if the output was generated from different inputs last time
+func (cache *Cache) Changed(output string, fingerprint string) bool {
+	if cache == nil {
+		return true
+	}
+
+	return cache.outputs[output] != fingerprint
+}
+
+// Generated stores fingerprint of the inputs the output was generated from
+func (cache *Cache) Generated(output string, fingerprint string) {
+	if cache != nil {
+		cache.outputs[output] = fingerprint
+	}
+}
+
+// ContentHash hash of the file content
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint hash of the values encoded as json
+func Fingerprint(values ...interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+
+	return ContentHash(data)
+}
+
+// ApiFingerprint fingerprint of the exported api of the declarations, diagnostics and skipped functions are not a part of it
+func (declarations *FileDeclarations) ApiFingerprint() string {
+	api := *declarations
+	api.Diagnostics = nil
+	api.Skipped = nil
+
+	return Fingerprint(api)
+}
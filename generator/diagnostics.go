@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// Severity of the diagnostic
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Diagnostic problem of the source found by the parser
+type Diagnostic struct {
+	Position token.Position
+	Severity Severity
+	// Code short name of the problem, like unknown-annotation
+	Code    string
+	Message string
+}
+
+// String compiler style description, like "api.go:12:1: warning: message [code]"
+func (diagnostic Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", diagnostic.Position, diagnostic.Severity, diagnostic.Message, diagnostic.Code)
+}
+
+// Diagnostic codes
+const (
+	DiagUnknownAnnotation = "unknown-annotation"
+	DiagWrongAnnotation   = "wrong-annotation"
+	DiagUnknownParameter  = "unknown-parameter"
+	DiagUnknownRule       = "unknown-rule"
+	DiagUnsupportedType   = "unsupported-type"
+	DiagUnexportedType    = "unexported-type"
+	DiagMissingCallback   = "missing-callback"
+)
+
+// SortDiagnostics sorts diagnostics by file and position
+func SortDiagnostics(list []Diagnostic) {
+	sort.SliceStable(list, func(i, j int) bool {
+		left, right := list[i].Position, list[j].Position
+		if left.Filename != right.Filename {
+			return left.Filename < right.Filename
+		}
+
+		if left.Line != right.Line {
+			return left.Line < right.Line
+		}
+
+		return left.Column < right.Column
+	})
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+)
+
+// FuncMap functions available in all templates
+var FuncMap = template.FuncMap{
+	"lowerCamel": strcase.ToLowerCamel,
+	"jsType":     jsTypeFunc,
+	"goType":     goTypeFunc,
+	"jsonName":   JsonName,
+	"docComment": DocComment,
+	"indent":     Indent,
+}
+
+// typeOf accepts type as Type or as a go type string like "[]*User"
+func typeOf(tp interface{}) *Type {
+	switch x := tp.(type) {
+	case *Type:
+		return x
+	case Type:
+		return &x
+	case string:
+		return ParseTypeString(x)
+	}
+
+	return nil
+}
+
+func jsTypeFunc(tp interface{}) string {
+	return JsType(typeOf(tp))
+}
+
+func goTypeFunc(tp interface{}) string {
+	return GoType(typeOf(tp), "")
+}
+
+// JsonName name of the field in json, the tag is the raw struct tag
+func JsonName(name string, tag ...string) string {
+	if len(tag) == 0 {
+		return name
+	}
+
+	jsonTag := reflect.StructTag(strings.Trim(tag[0], "`")).Get("json")
+	jsonName := strings.Split(jsonTag, ",")[0]
+	if jsonName == "" || jsonName == "-" {
+		return name
+	}
+
+	return jsonName
+}
+
+// DocComment formats comment lines as /** */ block
+func DocComment(comments []string) string {
+	lines := make([]string, 0, len(comments)+2)
+	lines = append(lines, "/**")
+	for _, comment := range comments {
+		lines = append(lines, strings.TrimRight(" * "+comment, " "))
+	}
+	lines = append(lines, " */")
+
+	return strings.Join(lines, "\n")
+}
+
+// Indent prefixes every line but the first one with spaces
+func Indent(spaces int, text string) string {
+	padding := strings.Repeat(" ", spaces)
+	return strings.Replace(text, "\n", "\n"+padding, -1)
+}
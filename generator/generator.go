@@ -1,56 +1,156 @@
 package generator
 
 import (
-	"go/ast"
+	"go/token"
+	"sort"
 
 	"gitlab.vmassive.ru/wand/config"
 )
 
+// Annotation is a doc comment line like "@callback: User", "@throttle interval=100ms"
+// or a directive like "//wand:callback User"
 type Annotation struct {
-	Name  string
+	// Name of the annotation without "@", e.g. callback
+	Name string
+	// Value text after the name
 	Value string
+	// Args key=value words of the value, nil when there are none
+	Args map[string]string
 }
 
+// FunctionData exported function of the source package
 type FunctionData struct {
-	Name         string
-	Comments     []string
-	ReturnType   string
-	Params       *ast.FieldList
+	// Name of the go function
+	Name string
+	// Comments doc comment lines without annotations
+	Comments []string
+	// ReturnType type of @callback annotation, "any" when not annotated
+	ReturnType string
+	// Params parameters of the go function without callbacks
+	Params []Field
+	// Results results of the go function
+	Results []Field
+	// Subscription type of @subscription annotation, nil for calls
 	Subscription *string
-	Annotation   []Annotation
-	CallName     string
+	// Stream type of the items of streaming function, of @stream annotation or of <-chan result
+	Stream *Type
+	// StreamChannel is true when streaming function returns <-chan, otherwise it takes goapi.Stream
+	StreamChannel bool
+	// Annotation all annotations of the function
+	Annotation []Annotation
+	// CallName name used by js to call the function
+	CallName string
+}
+
+// Kind of the function: subscription, stream, call with @callback or pure
+func (function FunctionData) Kind() string {
+	switch {
+	case function.Subscription != nil:
+		return "subscription"
+	case function.Stream != nil:
+		return "stream"
+	case function.ReturnType != "any":
+		return "call"
+	}
+
+	return "pure"
+}
+
+// Result first result of pure function which is not an error, it goes to js, nil when there is none
+func (function FunctionData) Result() *Field {
+	for index := range function.Results {
+		if !IsError(function.Results[index].Type) {
+			return &function.Results[index]
+		}
+	}
+
+	return nil
 }
 
+// ReturnsError tells if the last result of the function is an error
+func (function FunctionData) ReturnsError() bool {
+	return len(function.Results) > 0 && IsError(function.Results[len(function.Results)-1].Type)
+}
+
+// SkippedFunction exported function of the source which is not bridged
+type SkippedFunction struct {
+	// Name of the go function
+	Name string
+	// Kind call, subscription or pure
+	Kind string
+	// Reason why the function is skipped
+	Reason string
+	// Position of the function name
+	Position token.Position
+}
+
+// ExportedStucture exported struct of the source package
 type ExportedStucture struct {
-	Comments   []string
-	Name       string
-	Field      *ast.FieldList
+	// Comments doc comment lines without annotations
+	Comments []string
+	// Name of the go type
+	Name string
+	// TypeParams names of type parameters of generic struct
+	TypeParams []string
+	// Field exported fields of the struct which are in json
+	Field []Field
+	// Annotation annotations like @get and @update
 	Annotation []Annotation
 }
 
+// PathMap directories used by the generators
 type PathMap struct {
+	// Source directory of the source package
 	Source string
+	// Target directory of the generated go package
 	Target string
-	Js     string
+	// Js directory of the generated js code
+	Js string
 }
 
+// CodeList everything found in the source package, the root object of the
+// go and js head templates
 type CodeList struct {
-	Package       string
-	PackageName   string
-	Dev           bool
-	Port          int16
+	// Package name of the generated go package, main for dev server
+	Package string
+	// PackageName name of the source package
+	PackageName string
+	// Dev is true when code is generated for development server
+	Dev bool
+	// Port of development server
+	Port int16
+	// Bridge websocket options of development server
+	Bridge config.Bridge
+	// SourcePackage import path of the source package
 	SourcePackage string
-	Structures    []ExportedStucture
-	Functions     []FunctionData
-	Pure          []FunctionData
-	Config        *config.Configuration
-	PathMap       PathMap
+	// Enums exported basic types with their constants sorted by name
+	Enums []ExportedEnum
+	// Structures exported structs sorted by name
+	Structures []ExportedStucture
+	// Functions exported functions with @callback, @subscription or streams sorted by name
+	Functions []FunctionData
+	// Pure exported functions without annotations sorted by name, js gets their first result
+	Pure []FunctionData
+	// Skipped exported functions which are not bridged because of export control
+	Skipped []SkippedFunction
+	// Diagnostics problems of the source sorted by position
+	Diagnostics []Diagnostic
+	// Strict fails parsing when there are any diagnostics
+	Strict bool
+	// Config wand.yaml
+	Config *config.Configuration
+	// PathMap directories of the source and generated code
+	PathMap PathMap
 }
 
 func (list *CodeList) AddStructure(structure ExportedStucture) {
 	list.Structures = append(list.Structures, structure)
 }
 
+func (list *CodeList) AddEnum(enum ExportedEnum) {
+	list.Enums = append(list.Enums, enum)
+}
+
 func (list *CodeList) AddFunction(function FunctionData) {
 	list.Functions = append(list.Functions, function)
 }
@@ -59,6 +159,29 @@ func (list *CodeList) AddPureFunction(function FunctionData) {
 	list.Pure = append(list.Pure, function)
 }
 
+// Sort orders declarations by name, so generated code does not depend on parsing order
+func (list *CodeList) Sort() {
+	sort.SliceStable(list.Enums, func(i, j int) bool {
+		return list.Enums[i].Name < list.Enums[j].Name
+	})
+
+	sort.SliceStable(list.Structures, func(i, j int) bool {
+		return list.Structures[i].Name < list.Structures[j].Name
+	})
+
+	sort.SliceStable(list.Functions, func(i, j int) bool {
+		return list.Functions[i].Name < list.Functions[j].Name
+	})
+
+	sort.SliceStable(list.Pure, func(i, j int) bool {
+		return list.Pure[i].Name < list.Pure[j].Name
+	})
+
+	sort.SliceStable(list.Skipped, func(i, j int) bool {
+		return list.Skipped[i].Name < list.Skipped[j].Name
+	})
+}
+
 type Generator interface {
-	CreateCode(source *CodeList) error
+	CreateCode(source *CodeList, sink Sink) error
 }
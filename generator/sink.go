@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Sink receives generated files instead of the generators writing them directly
+type Sink interface {
+	WriteFile(name string, data []byte) error
+}
+
+// DiskSink writes generated files to disk atomically, files with the same content are left untouched
+type DiskSink struct{}
+
+func NewDiskSink() Sink {
+	return &DiskSink{}
+}
+
+func (sink *DiskSink) WriteFile(name string, data []byte) error {
+	if fileStatus(name, data) == StatusUnchanged {
+		log.Printf("%s is not changed", name)
+		return nil
+	}
+
+	dir := path.Dir(name)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+path.Base(name)+".")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Errorf("failed to write %s: %v", name, err)
+	}
+
+	return err
+}
+
+const (
+	StatusCreate    = "create"
+	StatusUpdate    = "update"
+	StatusUnchanged = "unchanged"
+)
+
+// fileStatus tells what writing the data would do to the file
+func fileStatus(name string, data []byte) string {
+	current, err := ioutil.ReadFile(name)
+	if err != nil {
+		return StatusCreate
+	}
+
+	if bytes.Equal(current, data) {
+		return StatusUnchanged
+	}
+
+	return StatusUpdate
+}
+
+// DryRunSink only lists the changes generated files would make
+type DryRunSink struct {
+	writer io.Writer
+}
+
+func NewDryRunSink(writer io.Writer) Sink {
+	return &DryRunSink{
+		writer: writer,
+	}
+}
+
+func (sink *DryRunSink) WriteFile(name string, data []byte) error {
+	_, err := fmt.Fprintf(sink.writer, "%-9s %s (%d bytes)\n", fileStatus(name, data), name, len(data))
+	return err
+}
+
+// MemorySink keeps generated files in memory
+type MemorySink struct {
+	Files map[string][]byte
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		Files: make(map[string][]byte),
+	}
+}
+
+func (sink *MemorySink) WriteFile(name string, data []byte) error {
+	content := make([]byte, len(data))
+	copy(content, data)
+	sink.Files[name] = content
+
+	return nil
+}
+
+// Names returns sorted names of the written files
+func (sink *MemorySink) Names() []string {
+	names := make([]string, 0, len(sink.Files))
+	for name := range sink.Files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
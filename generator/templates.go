@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/assets"
+)
+
+const embeddedTemplates = "/templates"
+
+// Templates shared template set of the generators. All the templates are
+// parsed once with FuncMap, so templates defined (with define) in one file
+// can be used in any other. Files of the templates directory (templates in
+// wand.yaml) override embedded templates by name, other *.tmpl files of the
+// directory are parsed too and may hold partials.
+//
+// Templates and the data they are executed with:
+//
+//	head.go.tmpl, callmap.go.tmpl      *generator.CodeList
+//	func.go.tmpl, pure.go.tmpl,
+//	stream.go.tmpl                     gocall.Function
+//	head.js.tmpl                       *generator.CodeList
+//	func.js.tmpl                       js.Function
+//	struct.js.tmpl                     js.Structure
+//	headWith.js.tmpl                   js.WithHeader
+//	with.js.tmpl                       js.WithData
+//	validation.js.tmpl                 []js.ValidationType
+//	mock.js.tmpl                       js.MockData
+//	partials.js.tmpl                   jsArgs, jsParams, jsTuple and jsValidate, []js.Field
+//	module.java.tmpl, package.java.tmpl,
+//	module.h.tmpl, module.m.tmpl       native.ModuleData
+type Templates struct {
+	dir         string
+	set         *template.Template
+	files       map[string]string
+	fingerprint string
+}
+
+// NewTemplates loads and parses all the templates
+func NewTemplates(dir string) (*Templates, error) {
+	templates := &Templates{
+		dir:   dir,
+		set:   template.New("wand").Funcs(FuncMap),
+		files: make(map[string]string),
+	}
+
+	texts := make([]string, 0)
+	for _, name := range templates.names() {
+		text, fileName, err := templates.Load(name)
+		if err != nil {
+			return nil, err
+		}
+
+		texts = append(texts, fileName, text)
+
+		_, err = templates.set.New(fileName).Parse(text)
+		if err != nil {
+			log.Errorf("failed with error %v", err)
+			return nil, err
+		}
+
+		templates.files[name] = fileName
+	}
+
+	templates.fingerprint = Fingerprint(texts)
+
+	return templates, nil
+}
+
+// Fingerprint hash of the texts of all the templates
+func (templates *Templates) Fingerprint() string {
+	return templates.fingerprint
+}
+
+// names of the embedded templates and templates of the directory
+func (templates *Templates) names() []string {
+	names := append([]string{}, TemplateNames()...)
+	if templates.dir == "" {
+		return names
+	}
+
+	files, err := ioutil.ReadDir(templates.dir)
+	if err != nil {
+		return names
+	}
+
+	known := make(map[string]bool)
+	for _, name := range names {
+		known[name] = true
+	}
+
+	for _, file := range files {
+		if !file.IsDir() && path.Ext(file.Name()) == ".tmpl" && !known[file.Name()] {
+			names = append(names, file.Name())
+		}
+	}
+
+	return names
+}
+
+// Load returns the text of the template and the file it was loaded from
+func (templates *Templates) Load(name string) (string, string, error) {
+	if templates.dir != "" {
+		fileName := path.Join(templates.dir, name)
+		data, err := ioutil.ReadFile(fileName)
+		if err == nil {
+			return string(data), fileName, nil
+		}
+
+		if !os.IsNotExist(err) {
+			log.Errorf("read file error %v", err)
+			return "", fileName, err
+		}
+	}
+
+	fileName := path.Join(embeddedTemplates, name)
+	file, err := assets.Assets.Open(fileName)
+	if err != nil {
+		log.Errorf("read file error %v", err)
+		return "", fileName, err
+	}
+
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		log.Errorf("read file error %v", err)
+		return "", fileName, err
+	}
+
+	return string(data), fileName, nil
+}
+
+// Execute executes the template by its name, like func.go.tmpl. Templates are
+// named after their files, so errors point to the file and line of the template.
+func (templates *Templates) Execute(wr io.Writer, name string, data interface{}) error {
+	fileName, ok := templates.files[name]
+	if !ok {
+		return fmt.Errorf("template %s not found", name)
+	}
+
+	err := templates.set.ExecuteTemplate(wr, fileName, data)
+	if err != nil {
+		log.Errorf("template failed with error %v", err)
+	}
+
+	return err
+}
+
+// TemplateNames returns names of the embedded templates
+func TemplateNames() []string {
+	return assets.Assets.Dirs[embeddedTemplates]
+}
+
+// EjectTemplates copies embedded templates to the directory, so they can be changed
+func EjectTemplates(dir string, force bool) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	embedded := &Templates{}
+
+	for _, name := range TemplateNames() {
+		fileName := path.Join(dir, name)
+		if _, err := os.Stat(fileName); err == nil && !force {
+			log.Printf("%s already exists, skipping", fileName)
+			continue
+		}
+
+		text, _, err := embedded.Load(name)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(fileName, []byte(text), 0644)
+		if err != nil {
+			log.Errorf("failed to write %s", fileName)
+			return err
+		}
+
+		log.Printf("created %s", fileName)
+	}
+
+	return nil
+}
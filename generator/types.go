@@ -0,0 +1,396 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"gitlab.vmassive.ru/wand/rules"
+)
+
+// Kind of the type, tells which fields of Type are set
+type Kind string
+
+const (
+	// KindBasic predeclared type like string or int, Name is set
+	KindBasic Kind = "basic"
+	// KindNamed declared type like User or time.Time, Name and Package are set
+	KindNamed Kind = "named"
+	// KindSlice slice or array of Elem
+	KindSlice Kind = "slice"
+	// KindMap map of Key to Elem
+	KindMap Kind = "map"
+	// KindPointer pointer to Elem
+	KindPointer Kind = "pointer"
+	// KindEnum declared basic type with constants, Elem is the basic type
+	KindEnum Kind = "enum"
+	// KindGeneric instance of generic type, Name and Args are set
+	KindGeneric Kind = "generic"
+	// KindAny interface{} or a type which is not supported
+	KindAny Kind = "any"
+)
+
+// Type type of a field or parameter, a tagged union by Kind
+type Type struct {
+	Kind Kind
+	// Name of basic, named, enum and generic types
+	Name string `json:",omitempty"`
+	// Package name of the package of named types from other packages, empty for the source package
+	Package string `json:",omitempty"`
+	// Elem element of slices and maps, pointed type or basic type of enums
+	Elem *Type `json:",omitempty"`
+	// Key of maps
+	Key *Type `json:",omitempty"`
+	// Enum values of enums
+	Enum []EnumValue `json:",omitempty"`
+	// Args type arguments of generic types
+	Args []*Type `json:",omitempty"`
+}
+
+// Field parameter or result of a function or field of a structure
+type Field struct {
+	// Name of the go parameter or field, empty for unnamed results
+	Name string
+	// JSONName name of the field in json
+	JSONName string
+	// Type of the field
+	Type *Type
+	// Optional is true when the field may be missing in json (omitempty), parameters
+	// are optional when they and all the following ones are pointers, slices, maps or have @default
+	Optional bool
+	// Tag raw struct tag
+	Tag string
+	// Comments doc comment lines without annotations
+	Comments []string
+	// Annotation annotations of the field
+	Annotation []Annotation
+	// Rules validation rules of validate tag or @validate annotation
+	Rules []rules.Rule
+	// Default json value of @default annotation of the parameter
+	Default string
+}
+
+// EnumValue exported typed constant of the source package
+type EnumValue struct {
+	// Name of the constant
+	Name string
+	// Type name of the constant type
+	Type string
+	// Value json literal of the value, empty when it can not be computed
+	Value string
+}
+
+// ExportedEnum exported basic type of the source package, like "type Status string"
+type ExportedEnum struct {
+	// Comments doc comment lines
+	Comments []string
+	// Name of the go type
+	Name string
+	// Type basic type the enum is declared with
+	Type *Type
+	// Values constants of the type in the order of declaration
+	Values []EnumValue
+}
+
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "error": true,
+}
+
+// IsError tells if the type is the predeclared error
+func IsError(tp *Type) bool {
+	return tp != nil && tp.Kind == KindBasic && tp.Name == "error"
+}
+
+// IsBasic tells if the name is a predeclared type
+func IsBasic(name string) bool {
+	return basicTypes[name]
+}
+
+// ParseTypeString converts go type written as a string, like "[]*User" of annotations,
+// nil when it is not a type expression
+func ParseTypeString(text string) *Type {
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return nil
+	}
+
+	return ParseType(expr)
+}
+
+// ParseType converts go type expression to Type
+func ParseType(expr ast.Expr) *Type {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if x.Name == "any" {
+			return &Type{Kind: KindAny}
+		}
+
+		if IsBasic(x.Name) {
+			return &Type{Kind: KindBasic, Name: x.Name}
+		}
+
+		return &Type{Kind: KindNamed, Name: x.Name}
+
+	case *ast.SelectorExpr:
+		tp := &Type{Kind: KindNamed, Name: x.Sel.Name}
+		if pkg, ok := x.X.(*ast.Ident); ok {
+			tp.Package = pkg.Name
+		}
+
+		return tp
+
+	case *ast.StarExpr:
+		return &Type{Kind: KindPointer, Elem: ParseType(x.X)}
+
+	case *ast.ArrayType:
+		return &Type{Kind: KindSlice, Elem: ParseType(x.Elt)}
+
+	case *ast.MapType:
+		return &Type{Kind: KindMap, Key: ParseType(x.Key), Elem: ParseType(x.Value)}
+
+	case *ast.IndexExpr:
+		return genericType(x.X, []ast.Expr{x.Index})
+
+	case *ast.IndexListExpr:
+		return genericType(x.X, x.Indices)
+
+	case *ast.ParenExpr:
+		return ParseType(x.X)
+	}
+
+	return &Type{Kind: KindAny}
+}
+
+func genericType(expr ast.Expr, indices []ast.Expr) *Type {
+	tp := ParseType(expr)
+	if tp.Kind != KindNamed {
+		return &Type{Kind: KindAny}
+	}
+
+	tp.Kind = KindGeneric
+	for _, index := range indices {
+		tp.Args = append(tp.Args, ParseType(index))
+	}
+
+	return tp
+}
+
+// ConstantValue json literal of a basic literal, empty when it is not a string or a number
+func ConstantValue(lit *ast.BasicLit) string {
+	switch lit.Kind {
+	case token.STRING:
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return ""
+		}
+
+		return strconv.Quote(value)
+
+	case token.INT, token.FLOAT:
+		return lit.Value
+	}
+
+	return ""
+}
+
+// JsType flow type of the type
+func JsType(tp *Type) string {
+	if tp == nil {
+		return "any"
+	}
+
+	switch tp.Kind {
+	case KindBasic, KindNamed:
+		return JsTypeName(tp.Name)
+	case KindSlice:
+		if IsBytes(tp) {
+			return "Uint8Array"
+		}
+
+		return jsElemType(tp.Elem) + "[]"
+	case KindMap:
+		return "{ [key: " + JsType(tp.Key) + "]: " + JsType(tp.Elem) + "}"
+	case KindPointer:
+		return "?" + jsElemType(tp.Elem)
+
+	case KindEnum:
+		values := make([]string, 0, len(tp.Enum))
+		for _, value := range tp.Enum {
+			if value.Value == "" {
+				return JsType(tp.Elem)
+			}
+
+			values = append(values, value.Value)
+		}
+
+		if len(values) == 0 {
+			return JsType(tp.Elem)
+		}
+
+		return strings.Join(values, " | ")
+
+	case KindGeneric:
+		args := make([]string, 0, len(tp.Args))
+		for _, arg := range tp.Args {
+			args = append(args, JsType(arg))
+		}
+
+		return tp.Name + "<" + strings.Join(args, ", ") + ">"
+	}
+
+	return "any"
+}
+
+// jsElemType flow type of slice element or pointed type, unions are put in parentheses
+func jsElemType(tp *Type) string {
+	jsType := JsType(tp)
+	if strings.Contains(jsType, " | ") {
+		return "(" + jsType + ")"
+	}
+
+	return jsType
+}
+
+// JsTypeName flow type for go type name
+func JsTypeName(name string) string {
+	switch name {
+	case "float32", "float64",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"byte", "rune":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "error":
+		// the same as the schema, errors are messages for js
+		return "string"
+	}
+
+	return name
+}
+
+// GoType go type as it is written outside of the source package, types of
+// the source package are prefixed with pkg when it is not empty
+func GoType(tp *Type, pkg string) string {
+	if tp == nil {
+		return "interface{}"
+	}
+
+	switch tp.Kind {
+	case KindBasic:
+		return tp.Name
+	case KindNamed, KindEnum:
+		return qualifiedName(tp, pkg)
+	case KindSlice:
+		return "[]" + GoType(tp.Elem, pkg)
+	case KindMap:
+		return "map[" + GoType(tp.Key, pkg) + "]" + GoType(tp.Elem, pkg)
+	case KindPointer:
+		return "*" + GoType(tp.Elem, pkg)
+
+	case KindGeneric:
+		args := make([]string, 0, len(tp.Args))
+		for _, arg := range tp.Args {
+			args = append(args, GoType(arg, pkg))
+		}
+
+		return qualifiedName(tp, pkg) + "[" + strings.Join(args, ", ") + "]"
+	}
+
+	return "interface{}"
+}
+
+func qualifiedName(tp *Type, pkg string) string {
+	if tp.Package != "" {
+		return tp.Package + "." + tp.Name
+	}
+
+	if pkg != "" {
+		return pkg + "." + tp.Name
+	}
+
+	return tp.Name
+}
+
+// IsBytes tells if the type is a byte slice, js gets it as Uint8Array
+func IsBytes(tp *Type) bool {
+	return tp != nil && tp.Kind == KindSlice && tp.Elem != nil && tp.Elem.Kind == KindBasic && (tp.Elem.Name == "byte" || tp.Elem.Name == "uint8")
+}
+
+// IsCallback tells if the type is a callback or a stream of goapi, such parameters are passed by the generated code
+func IsCallback(tp *Type) bool {
+	return tp != nil && tp.Kind == KindNamed && (tp.Name == "JsCallback" || tp.Name == "EventCallback" || tp.Name == "Stream")
+}
+
+// ResolveEnums attaches values to the enums and turns references to them into
+// enum types. Declarations are copied, they may be shared with the cache.
+func (list *CodeList) ResolveEnums(values []EnumValue) {
+	enums := make(map[string]*Type)
+
+	for i, enum := range list.Enums {
+		enum.Values = make([]EnumValue, 0)
+		for _, value := range values {
+			if value.Type == enum.Name {
+				enum.Values = append(enum.Values, value)
+			}
+		}
+
+		list.Enums[i] = enum
+		enums[enum.Name] = &Type{Kind: KindEnum, Name: enum.Name, Elem: enum.Type, Enum: enum.Values}
+	}
+
+	for i, structure := range list.Structures {
+		list.Structures[i].Field = resolveFields(structure.Field, enums)
+	}
+
+	for i, function := range list.Functions {
+		list.Functions[i].Params = resolveFields(function.Params, enums)
+		list.Functions[i].Results = resolveFields(function.Results, enums)
+		list.Functions[i].Stream = resolveType(function.Stream, enums)
+	}
+
+	for i, function := range list.Pure {
+		list.Pure[i].Params = resolveFields(function.Params, enums)
+		list.Pure[i].Results = resolveFields(function.Results, enums)
+	}
+}
+
+func resolveFields(fields []Field, enums map[string]*Type) []Field {
+	resolved := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		field.Type = resolveType(field.Type, enums)
+		resolved = append(resolved, field)
+	}
+
+	return resolved
+}
+
+func resolveType(tp *Type, enums map[string]*Type) *Type {
+	if tp == nil {
+		return nil
+	}
+
+	if tp.Kind == KindNamed && tp.Package == "" {
+		if enum, ok := enums[tp.Name]; ok {
+			return enum
+		}
+	}
+
+	resolved := *tp
+	resolved.Elem = resolveType(tp.Elem, enums)
+	resolved.Key = resolveType(tp.Key, enums)
+
+	if tp.Args != nil {
+		resolved.Args = make([]*Type, 0, len(tp.Args))
+		for _, arg := range tp.Args {
+			resolved.Args = append(resolved.Args, resolveType(arg, enums))
+		}
+	}
+
+	return &resolved
+}
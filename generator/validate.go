@@ -0,0 +1,45 @@
+package generator
+
+// StructureName name of the source structure the value of the type is decoded to,
+// pointers, slices and values of maps are followed, empty for other types
+func StructureName(tp *Type) string {
+	for tp != nil && (tp.Kind == KindPointer || tp.Kind == KindSlice || tp.Kind == KindMap) {
+		tp = tp.Elem
+	}
+
+	if tp != nil && tp.Kind == KindNamed && tp.Package == "" {
+		return tp.Name
+	}
+
+	return ""
+}
+
+// ValidatedStructures names of the structures with validation rules in their fields or in fields of their fields
+func (list *CodeList) ValidatedStructures() map[string]bool {
+	validated := make(map[string]bool)
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, structure := range list.Structures {
+			if validated[structure.Name] {
+				continue
+			}
+
+			for _, field := range structure.Field {
+				if NeedsValidation(field, validated) {
+					validated[structure.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return validated
+}
+
+// NeedsValidation tells if the field has rules or its value is a validated structure
+func NeedsValidation(field Field, validated map[string]bool) bool {
+	return len(field.Rules) > 0 || validated[StructureName(field.Type)]
+}
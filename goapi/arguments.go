@@ -0,0 +1,156 @@
+package goapi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Param parameter of the go function as the generated adapter sees it
+type Param struct {
+	Name string
+	// Optional parameters may be missing, they get Default or zero value
+	Optional bool
+	// Default json value of @default annotation
+	Default string
+}
+
+// ArityError wrong number or names of the arguments
+type ArityError struct {
+	Message string
+}
+
+func (err *ArityError) Error() string {
+	return err.Message
+}
+
+// Arguments arguments of the call from js, positional "args" and named "kwargs"
+type Arguments struct {
+	params   []Param
+	args     []interface{}
+	kwargs   map[string]interface{}
+	defaults map[int]interface{}
+}
+
+// NewArguments checks that the call data has every required parameter and nothing else
+func NewArguments(callData map[string]interface{}, params []Param) (*Arguments, error) {
+	arguments := &Arguments{
+		params:   params,
+		args:     make([]interface{}, 0),
+		kwargs:   make(map[string]interface{}),
+		defaults: make(map[int]interface{}),
+	}
+
+	if err := arguments.decodeDefaults(); err != nil {
+		return nil, err
+	}
+
+	if args, ok := callData["args"]; ok && args != nil {
+		list, ok := args.([]interface{})
+		if !ok {
+			return nil, &ArityError{Message: "args must be an array"}
+		}
+
+		arguments.args = list
+	}
+
+	if kwargs, ok := callData["kwargs"]; ok && kwargs != nil {
+		named, ok := kwargs.(map[string]interface{})
+		if !ok {
+			return nil, &ArityError{Message: "kwargs must be an object"}
+		}
+
+		arguments.kwargs = named
+	}
+
+	return arguments, arguments.check()
+}
+
+// decodeDefaults decodes json of the defaults, wrong default fails the call instead of
+// making the argument missing
+func (arguments *Arguments) decodeDefaults() error {
+	for index, param := range arguments.params {
+		if param.Default == "" {
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(param.Default), &value); err != nil {
+			return fmt.Errorf("wrong default of argument %s: %v", param.Name, err)
+		}
+
+		arguments.defaults[index] = value
+	}
+
+	return nil
+}
+
+func (arguments *Arguments) check() error {
+	if len(arguments.args) > len(arguments.params) {
+		return &ArityError{Message: fmt.Sprintf("takes at most %d arguments, got %d", len(arguments.params), len(arguments.args))}
+	}
+
+	known := make(map[string]int)
+	for index, param := range arguments.params {
+		known[param.Name] = index
+	}
+
+	for name := range arguments.kwargs {
+		index, ok := known[name]
+		if !ok {
+			return &ArityError{Message: "unknown argument " + name}
+		}
+
+		if _, ok := arguments.positional(index); ok {
+			return &ArityError{Message: "argument " + name + " is passed twice"}
+		}
+	}
+
+	for index, param := range arguments.params {
+		if !param.Optional && !arguments.Passed(index) {
+			return &ArityError{Message: "missing argument " + param.Name}
+		}
+	}
+
+	return nil
+}
+
+// positional returns the argument passed by position, null of optional parameter is
+// a missing argument, js sends undefined arguments before the last one as null
+func (arguments *Arguments) positional(index int) (interface{}, bool) {
+	if index >= len(arguments.args) {
+		return nil, false
+	}
+
+	value := arguments.args[index]
+	if value == nil && arguments.params[index].Optional {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Passed tells if the argument is passed by position or by name
+func (arguments *Arguments) Passed(index int) bool {
+	if _, ok := arguments.positional(index); ok {
+		return true
+	}
+
+	_, ok := arguments.kwargs[arguments.params[index].Name]
+	return ok
+}
+
+// Get returns the argument by position or by name, default value of missing
+// optional parameter or false when it should get zero value
+func (arguments *Arguments) Get(index int) (interface{}, bool) {
+	if value, ok := arguments.positional(index); ok {
+		return value, true
+	}
+
+	param := arguments.params[index]
+	if value, ok := arguments.kwargs[param.Name]; ok {
+		return value, true
+	}
+
+	value, ok := arguments.defaults[index]
+	return value, ok
+}
@@ -0,0 +1,89 @@
+package goapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+var argumentParams = []Param{
+	{Name: "filter"},
+	{Name: "limit", Optional: true, Default: "10"},
+	{Name: "sort", Optional: true, Default: `"name"`},
+	{Name: "ids", Optional: true},
+}
+
+func TestArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		callData map[string]interface{}
+		values   []interface{}
+	}{
+		{
+			"positional",
+			map[string]interface{}{"args": []interface{}{"a", float64(5), "email", nil}},
+			[]interface{}{"a", float64(5), "email", nil},
+		},
+		{
+			"defaults",
+			map[string]interface{}{"args": []interface{}{"a"}},
+			[]interface{}{"a", float64(10), "name", nil},
+		},
+		{
+			"null before the last argument",
+			map[string]interface{}{"args": []interface{}{"a", nil, "email"}},
+			[]interface{}{"a", float64(10), "email", nil},
+		},
+		{
+			"kwargs",
+			map[string]interface{}{"args": []interface{}{"a"}, "kwargs": map[string]interface{}{"sort": "email"}},
+			[]interface{}{"a", float64(10), "email", nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			arguments, err := NewArguments(test.callData, argumentParams)
+			if err != nil {
+				t.Fatalf("NewArguments() failed: %v", err)
+			}
+
+			values := make([]interface{}, len(argumentParams))
+			for index := range argumentParams {
+				values[index], _ = arguments.Get(index)
+			}
+
+			if !reflect.DeepEqual(values, test.values) {
+				t.Errorf("values = %v, want %v", values, test.values)
+			}
+		})
+	}
+}
+
+func TestArgumentsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		callData map[string]interface{}
+		params   []Param
+		arity    bool
+	}{
+		{"too many", map[string]interface{}{"args": []interface{}{"a", 1, "b", nil, 5}}, argumentParams, true},
+		{"missing", map[string]interface{}{"args": []interface{}{}}, argumentParams, true},
+		{"unknown kwarg", map[string]interface{}{"args": []interface{}{"a"}, "kwargs": map[string]interface{}{"page": 1}}, argumentParams, true},
+		{"passed twice", map[string]interface{}{"args": []interface{}{"a"}, "kwargs": map[string]interface{}{"filter": "b"}}, argumentParams, true},
+		{"args is not an array", map[string]interface{}{"args": "a"}, argumentParams, true},
+		{"wrong default", map[string]interface{}{"args": []interface{}{}}, []Param{{Name: "limit", Optional: true, Default: "ten"}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewArguments(test.callData, test.params)
+			if err == nil {
+				t.Fatalf("NewArguments() succeeded, want error")
+			}
+
+			if _, arity := err.(*ArityError); arity != test.arity {
+				t.Errorf("NewArguments() = %v, arity error %v, want %v", err, arity, test.arity)
+			}
+		})
+	}
+}
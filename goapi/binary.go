@@ -0,0 +1,479 @@
+package goapi
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Markers of byte slices in json, js turns them into Uint8Array
+const (
+	// Base64Marker {"$base64": "..."} bytes inline
+	Base64Marker = "$base64"
+	// AttachmentMarker {"$binary": index} bytes attached to binary frame of remgo
+	AttachmentMarker = "$binary"
+	// FileMarker {"$file": "file:///...", "size": n} bytes in the file
+	FileMarker = "$file"
+)
+
+const fileScheme = "file://"
+
+// BinaryEncoder replaces byte slice with json value, usually with a marker
+type BinaryEncoder func(data []byte) interface{}
+
+// InlineBinary encodes the bytes as {"$base64": "..."}
+func InlineBinary(data []byte) interface{} {
+	return map[string]interface{}{Base64Marker: base64.StdEncoding.EncodeToString(data)}
+}
+
+// FileBinary encodes the bytes larger than threshold as files of the directory,
+// so they don't go through the bridge inline, smaller bytes are inline
+func FileBinary(dir string, threshold int) BinaryEncoder {
+	return func(data []byte) interface{} {
+		if len(data) <= threshold {
+			return InlineBinary(data)
+		}
+
+		file, err := ioutil.TempFile(dir, "wand-*.bin")
+		if err != nil {
+			return InlineBinary(data)
+		}
+		defer file.Close()
+
+		if _, err := file.Write(data); err != nil {
+			os.Remove(file.Name())
+			return InlineBinary(data)
+		}
+
+		return map[string]interface{}{FileMarker: fileScheme + file.Name(), "size": len(data)}
+	}
+}
+
+// ReleaseBinaryFile removes the file of FileBinary after js read it, files out of the directory are kept
+func ReleaseBinaryFile(dir string, uri string) error {
+	name, ok := binaryFileName(dir, uri)
+	if !ok || !strings.HasPrefix(filepath.Base(name), "wand-") {
+		return fmt.Errorf("%s is not a binary file of %s", uri, dir)
+	}
+
+	return os.Remove(name)
+}
+
+// binaryFileName path of the file uri, false when the file is not in the directory
+func binaryFileName(dir string, uri string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+
+	name := filepath.Clean(strings.TrimPrefix(uri, fileScheme))
+	return name, filepath.Dir(name) == filepath.Clean(dir)
+}
+
+// binaryFiles directory of the files js passes with {"$file": uri} markers
+type binaryFiles struct {
+	lock sync.RWMutex
+	dir  string
+}
+
+func (files *binaryFiles) setDir(dir string) {
+	files.lock.Lock()
+	defer files.lock.Unlock()
+
+	files.dir = dir
+}
+
+func (files *binaryFiles) getDir() string {
+	files.lock.RLock()
+	defer files.lock.RUnlock()
+
+	return files.dir
+}
+
+// DecodeBytes decodes the argument of []byte parameter, it is bytes of resolved marker,
+// marker itself, base64 string of encoding/json or array of numbers
+func DecodeBytes(arg interface{}) ([]byte, error) {
+	switch value := arg.(type) {
+	case nil:
+		return nil, nil
+
+	case []byte:
+		return value, nil
+
+	case string:
+		return base64.StdEncoding.DecodeString(value)
+
+	case map[string]interface{}:
+		resolved, err := ResolveBinary(value, nil, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if data, ok := resolved.([]byte); ok {
+			return data, nil
+		}
+
+	case []interface{}:
+		data := make([]byte, len(value))
+		for index, item := range value {
+			number, ok := item.(float64)
+			if !ok || number < 0 || number > 255 {
+				return nil, errors.New("wrong type, bytes are expected")
+			}
+			data[index] = byte(number)
+		}
+
+		return data, nil
+	}
+
+	return nil, errors.New("wrong type, bytes are expected")
+}
+
+// ResolveBinary replaces markers of the json value with bytes, attachments are the
+// bytes of binary frame which {"$binary": index} markers refer to. {"$file": uri} markers
+// are read only from the files of dir, they are rejected when dir is empty
+func ResolveBinary(value interface{}, attachments [][]byte, dir string) (interface{}, error) {
+	switch x := value.(type) {
+	case map[string]interface{}:
+		if len(x) <= 2 {
+			if data, ok, err := resolveMarker(x, attachments, dir); ok {
+				return data, err
+			}
+		}
+
+		for key, item := range x {
+			resolved, err := ResolveBinary(item, attachments, dir)
+			if err != nil {
+				return nil, err
+			}
+			x[key] = resolved
+		}
+
+	case []interface{}:
+		for index, item := range x {
+			resolved, err := ResolveBinary(item, attachments, dir)
+			if err != nil {
+				return nil, err
+			}
+			x[index] = resolved
+		}
+	}
+
+	return value, nil
+}
+
+func resolveMarker(marker map[string]interface{}, attachments [][]byte, dir string) ([]byte, bool, error) {
+	if text, ok := marker[Base64Marker].(string); ok {
+		data, err := base64.StdEncoding.DecodeString(text)
+		return data, true, err
+	}
+
+	if index, ok := marker[AttachmentMarker].(float64); ok {
+		if index < 0 || int(index) >= len(attachments) {
+			return nil, true, fmt.Errorf("no attachment %v", index)
+		}
+
+		return attachments[int(index)], true, nil
+	}
+
+	if uri, ok := marker[FileMarker].(string); ok {
+		name, ok := binaryFileName(dir, uri)
+		if !ok {
+			return nil, true, fmt.Errorf("%s is not a file of the binary directory", uri)
+		}
+
+		data, err := ioutil.ReadFile(name)
+		return data, true, err
+	}
+
+	return nil, false, nil
+}
+
+// EncodeBinary replaces byte slices of the value with the values of the encoder. The value
+// is returned as it is when it has no byte slices, otherwise structures with byte slices
+// become maps with the names encoding/json gives to their fields
+func EncodeBinary(value interface{}, encode BinaryEncoder) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	if encoded, changed := encodeBinary(reflect.ValueOf(value), encode); changed {
+		return encoded
+	}
+
+	return value
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encodeBinary encoded value and true when the value has byte slices
+func encodeBinary(value reflect.Value, encode BinaryEncoder) (interface{}, bool) {
+	if !value.IsValid() || !hasBinary(value.Type()) {
+		return nil, false
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil, false
+		}
+
+		return encodeBinary(value.Elem(), encode)
+
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil, false
+		}
+
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return encode(value.Bytes()), true
+		}
+
+		return encodeItems(value, encode)
+
+	case reflect.Array:
+		return encodeItems(value, encode)
+
+	case reflect.Map:
+		return encodeMap(value, encode)
+
+	case reflect.Struct:
+		return encodeStruct(value, encode)
+	}
+
+	return nil, false
+}
+
+func encodeItems(value reflect.Value, encode BinaryEncoder) (interface{}, bool) {
+	items := make([]interface{}, value.Len())
+	changed := false
+	for index := range items {
+		item := value.Index(index)
+		if encoded, ok := encodeBinary(item, encode); ok {
+			items[index], changed = encoded, true
+		} else {
+			items[index] = item.Interface()
+		}
+	}
+
+	return items, changed
+}
+
+func encodeMap(value reflect.Value, encode BinaryEncoder) (interface{}, bool) {
+	if value.IsNil() {
+		return nil, false
+	}
+
+	items := make(map[string]interface{}, value.Len())
+	changed := false
+	iterator := value.MapRange()
+	for iterator.Next() {
+		key := mapKey(iterator.Key())
+		if encoded, ok := encodeBinary(iterator.Value(), encode); ok {
+			items[key], changed = encoded, true
+		} else {
+			items[key] = iterator.Value().Interface()
+		}
+	}
+
+	return items, changed
+}
+
+func mapKey(key reflect.Value) string {
+	if key.Type().Implements(textMarshalerType) {
+		text, _ := key.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text)
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(key.Uint(), 10)
+	}
+
+	return fmt.Sprint(key.Interface())
+}
+
+func encodeStruct(value reflect.Value, encode BinaryEncoder) (interface{}, bool) {
+	fields := make(map[string]interface{}, value.NumField())
+	changed := false
+
+	tp := value.Type()
+	for index := 0; index < tp.NumField(); index++ {
+		field := tp.Field(index)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, options := jsonTag(field)
+		if name == "-" {
+			continue
+		}
+
+		item := value.Field(index)
+		if field.Anonymous && name == "" && embeddedStruct(item) {
+			// fields of embedded structure are promoted, fields of the structure itself win
+			if item.Kind() == reflect.Ptr {
+				if item.IsNil() {
+					continue
+				}
+				item = item.Elem()
+			}
+
+			embedded, embeddedChanged := encodeStruct(item, encode)
+			changed = changed || embeddedChanged
+			for key, embeddedValue := range embedded.(map[string]interface{}) {
+				if _, exists := fields[key]; !exists || !promoted(tp, key) {
+					fields[key] = embeddedValue
+				}
+			}
+			continue
+		}
+
+		if options.omitEmpty && isEmptyValue(item) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if encoded, ok := encodeBinary(item, encode); ok && !options.asString {
+			fields[name], changed = encoded, true
+		} else {
+			fields[name] = item.Interface()
+		}
+	}
+
+	return fields, changed
+}
+
+// promoted tells if the json name belongs to embedded structure, not to a field of the structure itself
+func promoted(tp reflect.Type, name string) bool {
+	for index := 0; index < tp.NumField(); index++ {
+		field := tp.Field(index)
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+
+		fieldName, _ := jsonTag(field)
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+
+		if fieldName == name {
+			return false
+		}
+	}
+
+	return true
+}
+
+type tagOptions struct {
+	omitEmpty bool
+	asString  bool
+}
+
+func jsonTag(field reflect.StructField) (string, tagOptions) {
+	parts := strings.Split(field.Tag.Get("json"), ",")
+	options := tagOptions{}
+	for _, option := range parts[1:] {
+		options.omitEmpty = options.omitEmpty || option == "omitempty"
+		options.asString = options.asString || option == "string"
+	}
+
+	if parts[0] == "-" && len(parts) == 1 {
+		return "-", options
+	}
+
+	return parts[0], options
+}
+
+func embeddedStruct(value reflect.Value) bool {
+	if value.Kind() == reflect.Ptr {
+		return value.Type().Elem().Kind() == reflect.Struct
+	}
+
+	return value.Kind() == reflect.Struct
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+
+	return false
+}
+
+// binaryTypes cache of hasBinary
+var binaryTypes sync.Map
+
+// hasBinary tells if values of the type may have byte slices, values of
+// json marshalers are encoded by themselves
+func hasBinary(tp reflect.Type) bool {
+	if cached, ok := binaryTypes.Load(tp); ok {
+		return cached.(bool)
+	}
+
+	result := typeHasBinary(tp, make(map[reflect.Type]bool))
+	binaryTypes.Store(tp, result)
+
+	return result
+}
+
+// typeHasBinary looks for byte slices reachable from the type, visited types are not checked twice
+func typeHasBinary(tp reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[tp] {
+		return false
+	}
+	visited[tp] = true
+
+	switch {
+	case tp.Implements(marshalerType) || tp.Implements(textMarshalerType):
+		return false
+
+	case tp.Kind() == reflect.Interface:
+		return true
+
+	case tp.Kind() == reflect.Slice && tp.Elem().Kind() == reflect.Uint8:
+		return true
+
+	case tp.Kind() == reflect.Ptr || tp.Kind() == reflect.Slice || tp.Kind() == reflect.Array || tp.Kind() == reflect.Map:
+		return typeHasBinary(tp.Elem(), visited)
+
+	case tp.Kind() == reflect.Struct:
+		for index := 0; index < tp.NumField(); index++ {
+			field := tp.Field(index)
+			if field.PkgPath == "" && typeHasBinary(field.Type, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package goapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveBinaryMarkers(t *testing.T) {
+	value := map[string]interface{}{
+		"inline":   map[string]interface{}{Base64Marker: "AQID"},
+		"attached": []interface{}{map[string]interface{}{AttachmentMarker: float64(1)}},
+		"text":     "plain",
+	}
+
+	resolved, err := ResolveBinary(value, [][]byte{{9}, {4, 5}}, "")
+	if err != nil {
+		t.Fatalf("ResolveBinary() failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"inline":   []byte{1, 2, 3},
+		"attached": []interface{}{[]byte{4, 5}},
+		"text":     "plain",
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("ResolveBinary() = %#v, want %#v", resolved, expected)
+	}
+}
+
+func TestResolveBinaryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		marker map[string]interface{}
+	}{
+		{"wrong base64", map[string]interface{}{Base64Marker: "%%%"}},
+		{"missing attachment", map[string]interface{}{AttachmentMarker: float64(3)}},
+		{"negative attachment", map[string]interface{}{AttachmentMarker: float64(-1)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ResolveBinary(test.marker, [][]byte{{1}}, ""); err == nil {
+				t.Errorf("ResolveBinary(%v) succeeded, want error", test.marker)
+			}
+		})
+	}
+}
+
+func TestResolveBinaryFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "binary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	inside := filepath.Join(dir, "photo.jpg")
+	if err := ioutil.WriteFile(inside, []byte("photo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	nested := filepath.Join(dir, "nested")
+	os.Mkdir(nested, 0700)
+	if err := ioutil.WriteFile(filepath.Join(nested, "secret"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ResolveBinary(map[string]interface{}{FileMarker: "file://" + inside}, nil, dir)
+	if err != nil || !bytes.Equal(data.([]byte), []byte("photo")) {
+		t.Errorf("ResolveBinary() of the file of the directory = %v, %v", data, err)
+	}
+
+	rejected := []struct {
+		uri string
+		dir string
+	}{
+		{"file://" + inside, ""},
+		{"file:///etc/passwd", dir},
+		{"file://" + dir + "/../" + filepath.Base(dir) + "/nested/secret", dir},
+		{"file://" + filepath.Join(nested, "secret"), dir},
+	}
+
+	for _, test := range rejected {
+		if data, err := ResolveBinary(map[string]interface{}{FileMarker: test.uri}, nil, test.dir); err == nil {
+			t.Errorf("ResolveBinary(%s) in %q = %v, want error", test.uri, test.dir, data)
+		}
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      interface{}
+		expected []byte
+	}{
+		{"nil", nil, nil},
+		{"bytes", []byte{1}, []byte{1}},
+		{"base64 string", "AQI=", []byte{1, 2}},
+		{"marker", map[string]interface{}{Base64Marker: "AQI="}, []byte{1, 2}},
+		{"numbers", []interface{}{float64(1), float64(255)}, []byte{1, 255}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := DecodeBytes(test.arg)
+			if err != nil || !bytes.Equal(data, test.expected) {
+				t.Errorf("DecodeBytes(%v) = %v, %v, want %v", test.arg, data, err, test.expected)
+			}
+		})
+	}
+
+	for _, arg := range []interface{}{[]interface{}{float64(256)}, true, map[string]interface{}{FileMarker: "file:///etc/passwd"}} {
+		if _, err := DecodeBytes(arg); err == nil {
+			t.Errorf("DecodeBytes(%v) succeeded, want error", arg)
+		}
+	}
+}
+
+func TestReleaseBinaryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "binary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	encoded := FileBinary(dir, 2)([]byte("large"))
+	uri := encoded.(map[string]interface{})[FileMarker].(string)
+
+	if err := ReleaseBinaryFile(dir, "file://"+filepath.Join(dir, "photo.jpg")); err == nil {
+		t.Errorf("ReleaseBinaryFile() removed the file which is not written by FileBinary")
+	}
+
+	if err := ReleaseBinaryFile(dir, uri); err != nil {
+		t.Errorf("ReleaseBinaryFile(%s) failed: %v", uri, err)
+	}
+}
@@ -1,8 +1,9 @@
 package goapi
 
 import (
-	"fmt"
-	"runtime/debug"
+	"context"
+	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -65,28 +66,70 @@ type CallFunc func(map[string]interface{}, JsCallback) error
 // SubFunc  type for general event function
 type SubFunc func(map[string]interface{}, EventCallback) (Subscription, error)
 
+// SubTypesFunc typed positional arguments of the subscription, they build the name of its events
 type SubTypesFunc func(callData []interface{}) ([]interface{}, error)
 
+// SubNamedTypesFunc typed arguments of the subscription from call data with "args" and "kwargs"
+type SubNamedTypesFunc func(callData map[string]interface{}) ([]interface{}, error)
+
 type subscriptionAdapter struct {
 	subscriptionFunc      SubFunc
-	subscriptionTypesFunc SubTypesFunc
+	subscriptionTypesFunc SubNamedTypesFunc
+}
+
+// eventName name of the events of the subscription, it is built with typed arguments
+func (adapter *subscriptionAdapter) eventName(event string, subscriptionData map[string]interface{}) (string, error) {
+	typedArgs, err := adapter.subscriptionTypesFunc(subscriptionData)
+	if err != nil {
+		return "", err
+	}
+
+	return BuildSubscriptionName(event, typedArgs), nil
 }
 
 type JsRegistry struct {
 	subscriptions        map[string]*subscriptionAdapter
 	functions            map[string]CallFunc
+	streamFunctions      map[string]StreamFunc
+	streams              *streamRegistry
 	subscriptionRegistry SubscriptionRegistry
+	panics               *panicConfig
+	metrics              *Metrics
+	middlewares          *middlewareChain
+	binaryFiles          *binaryFiles
 }
 
 func NewJsRegistry() JsRegistry {
+	panics := &panicConfig{}
+	metrics := NewMetrics()
+
 	return JsRegistry{
 		subscriptions:        make(map[string]*subscriptionAdapter),
 		functions:            make(map[string]CallFunc),
-		subscriptionRegistry: NewSubscriptionRegistry(),
+		streamFunctions:      make(map[string]StreamFunc),
+		streams:              newStreamRegistry(),
+		subscriptionRegistry: newSubscriptionRegistry(panics, metrics),
+		panics:               panics,
+		metrics:              metrics,
+		middlewares:          &middlewareChain{},
+		binaryFiles:          &binaryFiles{},
 	}
 }
 
+// RegisterSubscription registers subscription which takes only positional arguments
 func (registry *JsRegistry) RegisterSubscription(eventName string, subFunc SubFunc, typeFunction SubTypesFunc) {
+	registry.RegisterNamedSubscription(eventName, subFunc, func(callData map[string]interface{}) ([]interface{}, error) {
+		args, ok := callData["args"].([]interface{})
+		if !ok {
+			return nil, errors.New("no args in event request " + eventName)
+		}
+
+		return typeFunction(args)
+	})
+}
+
+// RegisterNamedSubscription registers subscription which takes positional and named arguments
+func (registry *JsRegistry) RegisterNamedSubscription(eventName string, subFunc SubFunc, typeFunction SubNamedTypesFunc) {
 	registry.subscriptions[eventName] = &subscriptionAdapter{subscriptionFunc: subFunc, subscriptionTypesFunc: typeFunction}
 }
 
@@ -94,25 +137,68 @@ func (registry *JsRegistry) RegisterFunction(functionName string, adapterFunctio
 	registry.functions[functionName] = adapterFunction
 }
 
+// RegisterStream registers adapter of streaming function, js opens it with OpenStream
+func (registry *JsRegistry) RegisterStream(functionName string, adapterFunction StreamFunc) {
+	registry.streamFunctions[functionName] = adapterFunction
+}
+
 func (registry *JsRegistry) RegisterEventCallback(callback JsEvent) {
 	registry.subscriptionRegistry.SetCallback(callback)
 }
 
+// ActiveSubscriptions keys of the subscriptions which are not cancelled
+func (registry *JsRegistry) ActiveSubscriptions() []string {
+	return registry.subscriptionRegistry.Active()
+}
+
+// Metrics metrics of the calls and subscriptions of this registry
+func (registry *JsRegistry) Metrics() *Metrics {
+	return registry.metrics
+}
+
+// SetPanicHandler sets the handler of panics of this registry
+func (registry *JsRegistry) SetPanicHandler(handler PanicHandler) {
+	registry.panics.setHandler(handler)
+}
+
+// SetDev makes panics send the value and the frames to js, release builds get only "internal error"
+func (registry *JsRegistry) SetDev(dev bool) {
+	registry.panics.setDev(dev)
+}
+
+// SetBinaryDir makes {"$file": uri} markers of the arguments read the files of the directory,
+// the markers are rejected until it is set and for the files out of it
+func (registry *JsRegistry) SetBinaryDir(dir string) {
+	registry.binaryFiles.setDir(dir)
+}
+
+// Use adds middlewares of the calls, they run after global middlewares in the order they were added
+func (registry *JsRegistry) Use(middleware ...Middleware) {
+	registry.middlewares.use(middleware)
+}
+
+// UseSubscription adds middlewares of subscriptions and their cancellations
+func (registry *JsRegistry) UseSubscription(middleware ...SubscriptionMiddleware) {
+	registry.middlewares.useSubscription(middleware)
+}
+
 func (registry *JsRegistry) Subscribe(subscriptionData map[string]interface{}) {
+	registry.SubscribeContext(context.Background(), subscriptionData)
+}
+
+// SubscribeContext subscribes through the middlewares with the context
+func (registry *JsRegistry) SubscribeContext(ctx context.Context, subscriptionData map[string]interface{}) {
 	eventName, ok := subscriptionData["event"].(string)
 	if ok {
-		adapter := registry.subscriptions[eventName]
-		if adapter != nil {
-			args, ok := subscriptionData["args"].([]interface{})
-			if ok {
-				typedArgs, err := adapter.subscriptionTypesFunc(args)
-				if err == nil {
-					eventName = BuildSubscriptionName(eventName, typedArgs)
-					registry.subscriptionRegistry.RegisterSubscription(eventName, subscriptionData, adapter.subscriptionFunc)
-				}
-			} else {
-				log.Errorf("Can't handle event %s : %#+v", eventName, subscriptionData)
+		defer func() {
+			if r := recover(); r != nil {
+				registry.panics.report(r, eventName, subscriptionData)
 			}
+		}()
+
+		err := registry.subscriptionHandler()(NewSubscriptionInfo(ctx, eventName, subscriptionData, false))
+		if err != nil {
+			log.Errorf("Subscription %s failed: %v", eventName, err)
 		}
 	} else {
 		log.Errorf("Wrong subscribe call: no event field %#+v", subscriptionData)
@@ -120,60 +206,226 @@ func (registry *JsRegistry) Subscribe(subscriptionData map[string]interface{}) {
 }
 
 func (registry *JsRegistry) CancelSubscription(subscriptionData map[string]interface{}) {
+	registry.CancelSubscriptionContext(context.Background(), subscriptionData)
+}
+
+// CancelSubscriptionContext cancels subscription through the middlewares with the context
+func (registry *JsRegistry) CancelSubscriptionContext(ctx context.Context, subscriptionData map[string]interface{}) {
 	eventName, ok := subscriptionData["event"].(string)
 	if ok {
-		adapter := registry.subscriptions[eventName]
-		if adapter != nil {
-			args, ok := subscriptionData["args"].([]interface{})
-			if ok {
-				typedArgs, err := adapter.subscriptionTypesFunc(args)
-				if err == nil {
-					eventName = BuildSubscriptionName(eventName, typedArgs)
-					registry.subscriptionRegistry.CancelSubscription(eventName)
-				}
-			} else {
-				log.Errorf("No args in event request %s", eventName)
+		defer func() {
+			if r := recover(); r != nil {
+				registry.panics.report(r, eventName, subscriptionData)
 			}
-		} else {
-			log.Errorf("No adapter for event %s", eventName)
+		}()
+
+		err := registry.subscriptionHandler()(NewSubscriptionInfo(ctx, eventName, subscriptionData, true))
+		if err != nil {
+			log.Errorf("Cancel of subscription %s failed: %v", eventName, err)
 		}
 	} else {
 		log.Errorf("Wrong cancelSubscription call: no event field %#+v", subscriptionData)
 	}
 }
 
+// subscriptionHandler chain of the middlewares around subscribe and cancel
+func (registry *JsRegistry) subscriptionHandler() SubscriptionHandler {
+	handler := registry.handleSubscription
+
+	_, middlewares := registry.middlewares.snapshot(globalMiddlewares.snapshot(nil, nil))
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+func (registry *JsRegistry) handleSubscription(info *SubscriptionInfo) error {
+	adapter := registry.subscriptions[info.Event]
+	if adapter == nil {
+		return errors.New("no adapter for event " + info.Event)
+	}
+
+	subscriptionData := info.callData()
+	eventName, err := adapter.eventName(info.Event, subscriptionData)
+	if err != nil {
+		return err
+	}
+
+	if info.Cancel {
+		registry.subscriptionRegistry.CancelSubscription(eventName)
+		return nil
+	}
+
+	return registry.subscriptionRegistry.RegisterSubscription(eventName, subscriptionData, adapter.subscriptionFunc)
+}
+
+// SubscriptionName name of the events of the subscription, like "watchUser:42", the same as
+// the events of the subscription are sent with
+func (registry *JsRegistry) SubscriptionName(subscriptionData map[string]interface{}) (string, error) {
+	event, _ := subscriptionData["event"].(string)
+	adapter := registry.subscriptions[event]
+	if adapter == nil {
+		return "", errors.New("no adapter for event " + event)
+	}
+
+	return adapter.eventName(event, subscriptionData)
+}
+
+// meteredName name of the function in the metrics, names which are not registered share
+// one series, so js can't add series by calling them
+func (registry *JsRegistry) meteredName(method string) string {
+	if registry.functions[method] != nil || registry.streamFunctions[method] != nil {
+		return method
+	}
+
+	return UnknownFunction
+}
+
 func (registry *JsRegistry) Call(methodCallData map[string]interface{}, callback JsCallback) {
+	registry.CallContext(context.Background(), methodCallData, callback)
+}
+
+// CallContext calls the function through the middlewares with the context
+func (registry *JsRegistry) CallContext(ctx context.Context, methodCallData map[string]interface{}, callback JsCallback) {
 	methodName, ok := methodCallData["method"].(string)
 	if ok {
+		callback = newMeteredCallback(callback, registry.metrics, registry.meteredName(methodName))
+
 		defer func() {
 			if r := recover(); r != nil {
 				log.Errorf("[!!!] Method \"%s\" crashed", methodName)
-				fmt.Printf("%v", r)
+				callback.OnError(registry.panics.report(r, methodName, methodCallData))
+			}
+		}()
 
-				stack := debug.Stack()
+		err := registry.callHandler()(NewCallInfo(ctx, methodName, methodCallData), callback)
+		if validationErr, ok := err.(*ValidationError); ok {
+			callback.OnError(validationErr)
+		} else if err != nil {
+			callback.OnError(err.Error())
+		}
+	}
+}
 
-				stackLines := strings.Split(string(stack), "\n")
+// syncCallback keeps the result of the function which answers before it returns
+type syncCallback struct {
+	done   bool
+	result interface{}
+	err    interface{}
+}
 
-				callback.OnError(stackLines)
-			}
-		}()
+func (callback *syncCallback) OnSuccess(result interface{}) {
+	callback.done, callback.result = true, result
+}
 
-		functionCall := registry.functions[methodName]
-		if functionCall != nil {
-			log.Printf("[CALL] methodName %s", methodName)
-			err := functionCall(methodCallData, callback)
-			if err != nil {
-				callback.OnError(err.Error())
-			}
-		} else {
-			log.Errorf("methodName not found %s", methodName)
-			callback.OnError("no such method: " + methodName)
+func (callback *syncCallback) OnError(err interface{}) {
+	callback.done, callback.err = true, err
+}
+
+// CallSync calls the function through the middlewares and returns its result or error,
+// the function must answer before it returns, like adapters of pure functions do
+func (registry *JsRegistry) CallSync(ctx context.Context, methodCallData map[string]interface{}) (interface{}, interface{}) {
+	callback := &syncCallback{}
+	registry.CallContext(ctx, methodCallData, callback)
+
+	if !callback.done {
+		methodName, _ := methodCallData["method"].(string)
+		return nil, "method " + methodName + " does not answer synchronously"
+	}
+
+	return callback.result, callback.err
+}
+
+// OpenStream calls streaming function through the middlewares in its own goroutine,
+// key identifies the stream for StreamCredit and CloseStream, credits is the number
+// of items js is ready to read, the items and the end of the stream go to the sink
+func (registry *JsRegistry) OpenStream(ctx context.Context, key string, methodCallData map[string]interface{}, credits int, sink StreamSink) {
+	methodName, _ := methodCallData["method"].(string)
+	method := registry.meteredName(methodName)
+	stream := newCreditStream(ctx, credits, meteredStreamSink{sink: sink, metrics: registry.metrics, method: method})
+	registry.streams.add(key, stream)
+	registry.metrics.streamOpened(method)
+
+	go func() {
+		defer registry.metrics.streamClosed(method)
+		defer registry.streams.remove(key, stream)
+		registry.CallContext(context.WithValue(stream.ctx, streamContextKey{}, Stream(stream)), methodCallData, stream)
+		// the call without method or stopped by a middleware gives no result
+		stream.finish("stream ended without result", true)
+	}()
+}
+
+// StreamCredit lets the stream send count more items
+func (registry *JsRegistry) StreamCredit(key string, count int) {
+	if stream := registry.streams.get(key); stream != nil {
+		stream.grant(count)
+	}
+}
+
+// CloseStream stops the stream when js stopped reading, the function gets ErrStreamClosed
+func (registry *JsRegistry) CloseStream(key string) {
+	if stream := registry.streams.get(key); stream != nil {
+		stream.finish(nil, false)
+		registry.streams.remove(key, stream)
+	}
+}
+
+// NewEventStreamSink sink which sends the items of the stream as events named eventName,
+// the events are {"Item": item} and {"Done": true, "Error": error}
+func (registry *JsRegistry) NewEventStreamSink(eventName string) StreamSink {
+	return eventStreamSink{eventName: eventName, callback: &registry.subscriptionRegistry.callback}
+}
+
+// callHandler chain of the middlewares around the function
+func (registry *JsRegistry) callHandler() CallHandler {
+	handler := registry.handleCall
+
+	middlewares, _ := registry.middlewares.snapshot(globalMiddlewares.snapshot(nil, nil))
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+func (registry *JsRegistry) handleCall(info *CallInfo, callback JsCallback) error {
+	// bytes of the arguments come as markers, like {"$base64": "..."}
+	callData, err := ResolveBinary(info.callData(), nil, registry.binaryFiles.getDir())
+	if err != nil {
+		return err
+	}
+
+	if streamCall := registry.streamFunctions[info.Method]; streamCall != nil {
+		stream := streamFromContext(info.Context)
+		if stream == nil {
+			return errors.New("method " + info.Method + " is a stream, open it as a stream")
 		}
+
+		log.Printf("[STREAM] methodName %s", info.Method)
+		err := streamCall(callData.(map[string]interface{}), stream)
+		if err != nil {
+			return err
+		}
+
+		callback.OnSuccess(nil)
+		return nil
 	}
+
+	functionCall := registry.functions[info.Method]
+	if functionCall == nil {
+		log.Errorf("methodName not found %s", info.Method)
+		return errors.New("no such method: " + info.Method)
+	}
+
+	log.Printf("[CALL] methodName %s", info.Method)
+	return functionCall(callData.(map[string]interface{}), callback)
 }
 
 type JsEventCall struct {
 	callback JsEvent
+	panics   *panicConfig
+	metrics  *Metrics
 }
 
 func (jsEvent *JsEventCall) SetCallback(callback JsEvent) {
@@ -181,11 +433,20 @@ func (jsEvent *JsEventCall) SetCallback(callback JsEvent) {
 }
 
 func (jsEvent JsEventCall) OnEvent(eventName string, data interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			jsEvent.panics.report(r, eventName, nil)
+			jsEvent.metrics.eventSent(eventName, true)
+		}
+	}()
+
 	if jsEvent.callback != nil {
 		log.Printf("sending event %s", eventName)
 		jsEvent.callback.OnEvent(eventName, data)
+		jsEvent.metrics.eventSent(eventName, false)
 	} else {
 		log.Printf("skipping event, no active callbback")
+		jsEvent.metrics.eventSent(eventName, true)
 	}
 }
 
@@ -218,8 +479,13 @@ type SubscriptionRegistry struct {
 }
 
 func NewSubscriptionRegistry() SubscriptionRegistry {
+	return newSubscriptionRegistry(nil, nil)
+}
+
+func newSubscriptionRegistry(panics *panicConfig, metrics *Metrics) SubscriptionRegistry {
 	return SubscriptionRegistry{
-		active: make(map[string]*subscriptionData),
+		callback: JsEventCall{panics: panics, metrics: metrics},
+		active:   make(map[string]*subscriptionData),
 	}
 }
 
@@ -227,6 +493,22 @@ func (registry *SubscriptionRegistry) SetCallback(callback JsEvent) {
 	registry.callback.SetCallback(callback)
 }
 
+// Active sorted keys of the subscriptions which are not cancelled
+func (registry *SubscriptionRegistry) Active() []string {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	keys := make([]string, 0, len(registry.active))
+	for key, subscription := range registry.active {
+		if subscription != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 func (registry *SubscriptionRegistry) CancelSubscription(eventName string) {
 	registry.lock.Lock()
 	defer registry.lock.Unlock()
@@ -234,6 +516,7 @@ func (registry *SubscriptionRegistry) CancelSubscription(eventName string) {
 	subscription := registry.active[eventName]
 	if subscription != nil {
 		subscription.counter--
+		registry.callback.metrics.subscribed(eventName, -1)
 		if subscription.counter == 0 {
 			registry.active[eventName] = nil
 			subscription.subscription.Cancel()
@@ -264,6 +547,7 @@ func (registry *SubscriptionRegistry) RegisterSubscription(eventName string, par
 	}
 
 	subscription.counter++
+	registry.callback.metrics.subscribed(eventName, 1)
 
 	return nil
 }
@@ -0,0 +1,413 @@
+package goapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets upper bounds of the latency histogram in seconds
+var LatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// UnknownFunction name of the metrics of the calls of functions which are not registered
+const UnknownFunction = "unknown"
+
+// FunctionMetrics metrics of one function
+type FunctionMetrics struct {
+	Calls    int64 `json:"calls"`
+	Errors   int64 `json:"errors"`
+	InFlight int64 `json:"inFlight"`
+	// Buckets counts of calls with latency at most LatencyBuckets[i], the last one counts every call
+	Buckets []int64 `json:"buckets"`
+	// LatencySum total latency in seconds
+	LatencySum float64 `json:"latencySum"`
+}
+
+// SubscriptionMetrics metrics of one subscription event, like "watchUser", for all its arguments
+type SubscriptionMetrics struct {
+	Active  int64 `json:"active"`
+	Emitted int64 `json:"emitted"`
+	Dropped int64 `json:"dropped"`
+}
+
+// StreamMetrics metrics of one streaming function
+type StreamMetrics struct {
+	Opened int64 `json:"opened"`
+	Active int64 `json:"active"`
+	Items  int64 `json:"items"`
+}
+
+// MetricsSnapshot copy of the metrics at some moment
+type MetricsSnapshot struct {
+	Time          time.Time                      `json:"time"`
+	Buckets       []float64                      `json:"buckets"`
+	Functions     map[string]FunctionMetrics     `json:"functions"`
+	Subscriptions map[string]SubscriptionMetrics `json:"subscriptions"`
+	Streams       map[string]StreamMetrics       `json:"streams"`
+}
+
+// Metrics metrics of calls, subscriptions and streams of a registry
+type Metrics struct {
+	functions     map[string]*FunctionMetrics
+	subscriptions map[string]*SubscriptionMetrics
+	streams       map[string]*StreamMetrics
+	lock          sync.Mutex
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		functions:     make(map[string]*FunctionMetrics),
+		subscriptions: make(map[string]*SubscriptionMetrics),
+		streams:       make(map[string]*StreamMetrics),
+	}
+}
+
+func (metrics *Metrics) function(name string) *FunctionMetrics {
+	function := metrics.functions[name]
+	if function == nil {
+		function = &FunctionMetrics{Buckets: make([]int64, len(LatencyBuckets)+1)}
+		metrics.functions[name] = function
+	}
+
+	return function
+}
+
+// subscription metrics of the event of the subscription name, arguments of the name
+// are dropped, so the number of the series does not grow with them
+func (metrics *Metrics) subscription(name string) *SubscriptionMetrics {
+	event := strings.SplitN(name, ":", 2)[0]
+
+	subscription := metrics.subscriptions[event]
+	if subscription == nil {
+		subscription = &SubscriptionMetrics{}
+		metrics.subscriptions[event] = subscription
+	}
+
+	return subscription
+}
+
+func (metrics *Metrics) stream(name string) *StreamMetrics {
+	stream := metrics.streams[name]
+	if stream == nil {
+		stream = &StreamMetrics{}
+		metrics.streams[name] = stream
+	}
+
+	return stream
+}
+
+// callStarted counts the call as in flight
+func (metrics *Metrics) callStarted(name string) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.function(name).InFlight++
+}
+
+// callFinished counts the finished call and its latency
+func (metrics *Metrics) callFinished(name string, latency time.Duration, failed bool) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	function := metrics.function(name)
+	function.InFlight--
+	function.Calls++
+	if failed {
+		function.Errors++
+	}
+
+	seconds := latency.Seconds()
+	function.LatencySum += seconds
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			function.Buckets[i]++
+		}
+	}
+	function.Buckets[len(LatencyBuckets)]++
+}
+
+// subscribed changes the number of js subscribers of the subscription by delta
+func (metrics *Metrics) subscribed(name string, delta int) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.subscription(name).Active += int64(delta)
+}
+
+// eventSent counts the event of the subscription, dropped when nobody receives it
+func (metrics *Metrics) eventSent(name string, dropped bool) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	if dropped {
+		metrics.subscription(name).Dropped++
+	} else {
+		metrics.subscription(name).Emitted++
+	}
+}
+
+// streamOpened counts the stream of the function as active
+func (metrics *Metrics) streamOpened(name string) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	stream := metrics.stream(name)
+	stream.Opened++
+	stream.Active++
+}
+
+// streamClosed counts the end of the stream of the function
+func (metrics *Metrics) streamClosed(name string) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.stream(name).Active--
+}
+
+// streamItem counts the item sent by the stream of the function
+func (metrics *Metrics) streamItem(name string) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.stream(name).Items++
+}
+
+// Snapshot copies the metrics
+func (metrics *Metrics) Snapshot() MetricsSnapshot {
+	snapshot := MetricsSnapshot{
+		Time:          time.Now(),
+		Buckets:       LatencyBuckets,
+		Functions:     make(map[string]FunctionMetrics),
+		Subscriptions: make(map[string]SubscriptionMetrics),
+		Streams:       make(map[string]StreamMetrics),
+	}
+
+	if metrics == nil {
+		return snapshot
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	for name, function := range metrics.functions {
+		copied := *function
+		copied.Buckets = append([]int64{}, function.Buckets...)
+		snapshot.Functions[name] = copied
+	}
+
+	for event, subscription := range metrics.subscriptions {
+		snapshot.Subscriptions[event] = *subscription
+	}
+
+	for name, stream := range metrics.streams {
+		snapshot.Streams[name] = *stream
+	}
+
+	return snapshot
+}
+
+// WritePrometheus writes the metrics in prometheus text format
+func (metrics *Metrics) WritePrometheus(w io.Writer) error {
+	snapshot := metrics.Snapshot()
+
+	functions := make([]string, 0, len(snapshot.Functions))
+	for name := range snapshot.Functions {
+		functions = append(functions, name)
+	}
+	sort.Strings(functions)
+
+	subscriptions := make([]string, 0, len(snapshot.Subscriptions))
+	for event := range snapshot.Subscriptions {
+		subscriptions = append(subscriptions, event)
+	}
+	sort.Strings(subscriptions)
+
+	streams := make([]string, 0, len(snapshot.Streams))
+	for name := range snapshot.Streams {
+		streams = append(streams, name)
+	}
+	sort.Strings(streams)
+
+	out := &promWriter{w: w}
+
+	out.header("goapi_calls_total", "counter", "Calls of the function from js")
+	for _, name := range functions {
+		out.sample("goapi_calls_total", "function", name, "", "", float64(snapshot.Functions[name].Calls))
+	}
+
+	out.header("goapi_call_errors_total", "counter", "Calls of the function finished with an error")
+	for _, name := range functions {
+		out.sample("goapi_call_errors_total", "function", name, "", "", float64(snapshot.Functions[name].Errors))
+	}
+
+	out.header("goapi_calls_in_flight", "gauge", "Calls of the function waiting for the result")
+	for _, name := range functions {
+		out.sample("goapi_calls_in_flight", "function", name, "", "", float64(snapshot.Functions[name].InFlight))
+	}
+
+	out.header("goapi_call_duration_seconds", "histogram", "Latency of the calls")
+	for _, name := range functions {
+		function := snapshot.Functions[name]
+		for i, bound := range LatencyBuckets {
+			out.sample("goapi_call_duration_seconds_bucket", "function", name, "le", strconv.FormatFloat(bound, 'g', -1, 64), float64(function.Buckets[i]))
+		}
+		out.sample("goapi_call_duration_seconds_bucket", "function", name, "le", "+Inf", float64(function.Buckets[len(LatencyBuckets)]))
+		out.sample("goapi_call_duration_seconds_sum", "function", name, "", "", function.LatencySum)
+		out.sample("goapi_call_duration_seconds_count", "function", name, "", "", float64(function.Calls))
+	}
+
+	out.header("goapi_subscriptions_active", "gauge", "Js subscribers of the subscription event")
+	for _, event := range subscriptions {
+		out.sample("goapi_subscriptions_active", "event", event, "", "", float64(snapshot.Subscriptions[event].Active))
+	}
+
+	out.header("goapi_events_emitted_total", "counter", "Events sent to js")
+	for _, event := range subscriptions {
+		out.sample("goapi_events_emitted_total", "event", event, "", "", float64(snapshot.Subscriptions[event].Emitted))
+	}
+
+	out.header("goapi_events_dropped_total", "counter", "Events dropped without js event callback")
+	for _, event := range subscriptions {
+		out.sample("goapi_events_dropped_total", "event", event, "", "", float64(snapshot.Subscriptions[event].Dropped))
+	}
+
+	out.header("goapi_streams_opened_total", "counter", "Streams of the function opened by js")
+	for _, name := range streams {
+		out.sample("goapi_streams_opened_total", "function", name, "", "", float64(snapshot.Streams[name].Opened))
+	}
+
+	out.header("goapi_streams_active", "gauge", "Streams of the function which are not finished")
+	for _, name := range streams {
+		out.sample("goapi_streams_active", "function", name, "", "", float64(snapshot.Streams[name].Active))
+	}
+
+	out.header("goapi_stream_items_total", "counter", "Items sent by the streams of the function")
+	for _, name := range streams {
+		out.sample("goapi_stream_items_total", "function", name, "", "", float64(snapshot.Streams[name].Items))
+	}
+
+	return out.err
+}
+
+// ServeHTTP serves the metrics in prometheus text format
+func (metrics *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics.WritePrometheus(w)
+}
+
+// promWriter writes prometheus text format, it keeps the first error
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (out *promWriter) printf(format string, args ...interface{}) {
+	if out.err == nil {
+		_, out.err = fmt.Fprintf(out.w, format, args...)
+	}
+}
+
+func (out *promWriter) header(name string, kind string, help string) {
+	out.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (out *promWriter) sample(name string, label string, value string, extraLabel string, extraValue string, sample float64) {
+	labels := label + "=" + labelValue(value)
+	if extraLabel != "" {
+		labels += "," + extraLabel + "=" + labelValue(extraValue)
+	}
+
+	out.printf("%s{%s} %s\n", name, labels, strconv.FormatFloat(sample, 'g', -1, 64))
+}
+
+// labelEscaper escapes label values as prometheus text format does, other characters stay as they are
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// meteredStreamSink counts the items of the stream, they are not events of subscriptions
+type meteredStreamSink struct {
+	sink    StreamSink
+	metrics *Metrics
+	method  string
+}
+
+func (metered meteredStreamSink) OnItem(item interface{}) {
+	metered.metrics.streamItem(metered.method)
+	metered.sink.OnItem(item)
+}
+
+func (metered meteredStreamSink) OnDone(err interface{}) {
+	metered.sink.OnDone(err)
+}
+
+// meteredCallback counts the call when the result is sent
+type meteredCallback struct {
+	callback JsCallback
+	metrics  *Metrics
+	method   string
+	start    time.Time
+	once     sync.Once
+}
+
+func newMeteredCallback(callback JsCallback, metrics *Metrics, method string) *meteredCallback {
+	metrics.callStarted(method)
+
+	return &meteredCallback{
+		callback: callback,
+		metrics:  metrics,
+		method:   method,
+		start:    time.Now(),
+	}
+}
+
+func (metered *meteredCallback) finish(failed bool) {
+	metered.once.Do(func() {
+		metered.metrics.callFinished(metered.method, time.Since(metered.start), failed)
+	})
+}
+
+func (metered *meteredCallback) OnSuccess(result interface{}) {
+	metered.finish(false)
+	metered.callback.OnSuccess(result)
+}
+
+func (metered *meteredCallback) OnError(err interface{}) {
+	metered.finish(true)
+	metered.callback.OnError(err)
+}
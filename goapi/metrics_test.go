@@ -0,0 +1,119 @@
+package goapi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resultCallback keeps the result of the call
+type resultCallback struct {
+	results chan interface{}
+	errors  chan interface{}
+}
+
+func newResultCallback() *resultCallback {
+	return &resultCallback{results: make(chan interface{}, 1), errors: make(chan interface{}, 1)}
+}
+
+func (callback *resultCallback) OnSuccess(result interface{}) {
+	callback.results <- result
+}
+
+func (callback *resultCallback) OnError(err interface{}) {
+	callback.errors <- err
+}
+
+func prometheusText(t *testing.T, metrics *Metrics) string {
+	out := &bytes.Buffer{}
+	if err := metrics.WritePrometheus(out); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+
+	return out.String()
+}
+
+func TestWritePrometheus(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.callStarted("getUser")
+	metrics.callFinished("getUser", 3*time.Millisecond, false)
+	metrics.callStarted("getUser")
+	metrics.callFinished("getUser", 2*time.Second, true)
+	metrics.callStarted("findUsers")
+	metrics.subscribed("watchUser:42", 1)
+	metrics.eventSent("watchUser:42", false)
+	metrics.eventSent("watchUser:43", true)
+	metrics.streamOpened("ticks")
+	metrics.streamItem("ticks")
+	metrics.streamItem("ticks")
+
+	text := prometheusText(t, metrics)
+	expected := []string{
+		"# TYPE goapi_calls_total counter",
+		`goapi_calls_total{function="getUser"} 2`,
+		`goapi_call_errors_total{function="getUser"} 1`,
+		`goapi_calls_in_flight{function="findUsers"} 1`,
+		`goapi_call_duration_seconds_bucket{function="getUser",le="0.005"} 1`,
+		`goapi_call_duration_seconds_bucket{function="getUser",le="2.5"} 2`,
+		`goapi_call_duration_seconds_bucket{function="getUser",le="+Inf"} 2`,
+		`goapi_call_duration_seconds_count{function="getUser"} 2`,
+		`goapi_subscriptions_active{event="watchUser"} 1`,
+		`goapi_events_emitted_total{event="watchUser"} 1`,
+		`goapi_events_dropped_total{event="watchUser"} 1`,
+		`goapi_streams_opened_total{function="ticks"} 1`,
+		`goapi_streams_active{function="ticks"} 1`,
+		`goapi_stream_items_total{function="ticks"} 2`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("no %s in\n%s", line, text)
+		}
+	}
+
+	if strings.Contains(text, "watchUser:42") {
+		t.Errorf("subscription series are keyed by arguments:\n%s", text)
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"plain":       "plain",
+		`back\slash`:  `back\\slash`,
+		`"quoted"`:    `\"quoted\"`,
+		"new\nline":   `new\nline`,
+		"юникод\ttab": "юникод\ttab",
+	}
+
+	for value, expected := range tests {
+		if escaped := labelValue(value); escaped != `"`+expected+`"` {
+			t.Errorf("labelValue(%q) = %s, want %s", value, escaped, expected)
+		}
+	}
+}
+
+func TestUnknownFunctionsShareSeries(t *testing.T) {
+	registry := NewJsRegistry()
+	registry.RegisterFunction("known", func(callData map[string]interface{}, callback JsCallback) error {
+		callback.OnSuccess(true)
+		return nil
+	})
+
+	for _, method := range []string{"known", "missing1", "missing2"} {
+		callback := newResultCallback()
+		registry.Call(map[string]interface{}{"method": method, "args": []interface{}{}}, callback)
+
+		select {
+		case <-callback.results:
+		case <-callback.errors:
+		case <-time.After(time.Second):
+			t.Fatalf("%s did not answer", method)
+		}
+	}
+
+	functions := registry.Metrics().Snapshot().Functions
+	if len(functions) != 2 || functions["known"].Calls != 1 || functions[UnknownFunction].Errors != 2 {
+		t.Errorf("functions = %+v, want known and %s", functions, UnknownFunction)
+	}
+}
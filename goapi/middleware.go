@@ -0,0 +1,156 @@
+package goapi
+
+import (
+	"context"
+	"sync"
+)
+
+// CallInfo call from js as middlewares see it
+type CallInfo struct {
+	// Context of the call, middlewares may replace it to pass values to the next middlewares.
+	// It does not reach the functions: they don't take a context, and streaming functions
+	// get the context of OpenStream from Stream.Context, the handler only finds the stream in it
+	Context context.Context
+	// Method js name of the function
+	Method string
+	// Args positional arguments decoded from json, middlewares may change them
+	Args []interface{}
+	// Kwargs named arguments decoded from json, middlewares may change them
+	Kwargs map[string]interface{}
+	// CallData the call as it came from js
+	CallData map[string]interface{}
+}
+
+// CallHandler handles the call, the result is sent to the callback, the error is sent to js
+type CallHandler func(info *CallInfo, callback JsCallback) error
+
+// Middleware wraps the handler of calls. It may return an error without calling
+// next to reject the call or pass its own JsCallback to next to see the result.
+type Middleware func(next CallHandler) CallHandler
+
+// SubscriptionInfo subscription or its cancellation as middlewares see it
+type SubscriptionInfo struct {
+	// Context of the subscription, middlewares may replace it to pass values to the next
+	// middlewares, subscription functions don't get it
+	Context context.Context
+	// Event js name of the subscription function
+	Event string
+	// Args positional arguments decoded from json
+	Args []interface{}
+	// Kwargs named arguments decoded from json
+	Kwargs map[string]interface{}
+	// CallData the subscription as it came from js
+	CallData map[string]interface{}
+	// Cancel is true when the subscription is cancelled
+	Cancel bool
+}
+
+// SubscriptionHandler subscribes or cancels the subscription
+type SubscriptionHandler func(info *SubscriptionInfo) error
+
+// SubscriptionMiddleware wraps the handler of subscriptions and their cancellations
+type SubscriptionMiddleware func(next SubscriptionHandler) SubscriptionHandler
+
+// middlewareChain middlewares added by Use, calls read them while they may be added
+type middlewareChain struct {
+	lock          sync.RWMutex
+	calls         []Middleware
+	subscriptions []SubscriptionMiddleware
+}
+
+func (chain *middlewareChain) use(middleware []Middleware) {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+
+	chain.calls = append(chain.calls, middleware...)
+}
+
+func (chain *middlewareChain) useSubscription(middleware []SubscriptionMiddleware) {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+
+	chain.subscriptions = append(chain.subscriptions, middleware...)
+}
+
+// snapshot copies of the middlewares, appended to the given ones
+func (chain *middlewareChain) snapshot(calls []Middleware, subscriptions []SubscriptionMiddleware) ([]Middleware, []SubscriptionMiddleware) {
+	chain.lock.RLock()
+	defer chain.lock.RUnlock()
+
+	return append(calls, chain.calls...), append(subscriptions, chain.subscriptions...)
+}
+
+// globalMiddlewares middlewares of every registry
+var globalMiddlewares = &middlewareChain{}
+
+// Use adds middlewares of calls of every registry. The source package may
+// call it in init, before the generated code has any calls.
+func Use(middleware ...Middleware) {
+	globalMiddlewares.use(middleware)
+}
+
+// UseSubscription adds middlewares of subscriptions of every registry
+func UseSubscription(middleware ...SubscriptionMiddleware) {
+	globalMiddlewares.useSubscription(middleware)
+}
+
+func NewCallInfo(ctx context.Context, method string, callData map[string]interface{}) *CallInfo {
+	args, kwargs := splitArguments(callData)
+
+	return &CallInfo{
+		Context:  ctx,
+		Method:   method,
+		Args:     args,
+		Kwargs:   kwargs,
+		CallData: callData,
+	}
+}
+
+func NewSubscriptionInfo(ctx context.Context, event string, callData map[string]interface{}, cancel bool) *SubscriptionInfo {
+	args, kwargs := splitArguments(callData)
+
+	return &SubscriptionInfo{
+		Context:  ctx,
+		Event:    event,
+		Args:     args,
+		Kwargs:   kwargs,
+		CallData: callData,
+		Cancel:   cancel,
+	}
+}
+
+func splitArguments(callData map[string]interface{}) ([]interface{}, map[string]interface{}) {
+	args, _ := callData["args"].([]interface{})
+	kwargs, _ := callData["kwargs"].(map[string]interface{})
+
+	return args, kwargs
+}
+
+// callData the call data with arguments changed by middlewares
+func (info *CallInfo) callData() map[string]interface{} {
+	return withArguments(info.CallData, info.Args, info.Kwargs)
+}
+
+func (info *SubscriptionInfo) callData() map[string]interface{} {
+	return withArguments(info.CallData, info.Args, info.Kwargs)
+}
+
+func withArguments(callData map[string]interface{}, args []interface{}, kwargs map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(callData))
+	for key, value := range callData {
+		data[key] = value
+	}
+
+	delete(data, "args")
+	delete(data, "kwargs")
+
+	if args != nil {
+		data["args"] = args
+	}
+
+	if kwargs != nil {
+		data["kwargs"] = kwargs
+	}
+
+	return data
+}
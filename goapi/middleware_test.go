@@ -0,0 +1,133 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type middlewareKey struct{}
+
+// tracingMiddleware adds its name to the trace before and after the next handler
+func tracingMiddleware(name string, trace *[]string) Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(info *CallInfo, callback JsCallback) error {
+			*trace = append(*trace, name)
+			err := next(info, callback)
+			*trace = append(*trace, "/"+name)
+			return err
+		}
+	}
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	trace := make([]string, 0)
+	registry := NewJsRegistry()
+	registry.RegisterFunction("echo", func(callData map[string]interface{}, callback JsCallback) error {
+		trace = append(trace, "echo")
+		callback.OnSuccess(callData["args"])
+		return nil
+	})
+	registry.Use(tracingMiddleware("first", &trace), tracingMiddleware("second", &trace))
+	registry.Use(func(next CallHandler) CallHandler {
+		return func(info *CallInfo, callback JsCallback) error {
+			info.Args = append(info.Args, "added")
+			return next(info, callback)
+		}
+	})
+
+	result, err := registry.CallSync(context.Background(), map[string]interface{}{"method": "echo", "args": []interface{}{"a"}})
+	if err != nil {
+		t.Fatalf("CallSync() failed: %v", err)
+	}
+
+	if expected := []interface{}{"a", "added"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("result = %v, want %v", result, expected)
+	}
+
+	if expected := []string{"first", "second", "echo", "/second", "/first"}; !reflect.DeepEqual(trace, expected) {
+		t.Errorf("trace = %v, want %v", trace, expected)
+	}
+}
+
+func TestMiddlewareRejects(t *testing.T) {
+	called := false
+	registry := NewJsRegistry()
+	registry.RegisterFunction("secret", func(callData map[string]interface{}, callback JsCallback) error {
+		called = true
+		callback.OnSuccess(nil)
+		return nil
+	})
+	registry.Use(func(next CallHandler) CallHandler {
+		return func(info *CallInfo, callback JsCallback) error {
+			return errors.New("forbidden")
+		}
+	})
+
+	_, err := registry.CallSync(context.Background(), map[string]interface{}{"method": "secret"})
+	if err != "forbidden" || called {
+		t.Errorf("CallSync() = %v, called %v, want forbidden", err, called)
+	}
+}
+
+func TestMiddlewareContextReachesStream(t *testing.T) {
+	registry := NewJsRegistry()
+	registry.RegisterStream("user", func(callData map[string]interface{}, stream Stream) error {
+		return stream.Send(stream.Context().Value(middlewareKey{}))
+	})
+	registry.Use(func(next CallHandler) CallHandler {
+		return func(info *CallInfo, callback JsCallback) error {
+			info.Context = context.WithValue(info.Context, middlewareKey{}, "admin")
+			return next(info, callback)
+		}
+	})
+
+	sink := newRecordingSink()
+	registry.OpenStream(context.WithValue(context.Background(), middlewareKey{}, "js"), "1", map[string]interface{}{"method": "user"}, 1, sink)
+
+	select {
+	case err := <-sink.done:
+		if err != nil {
+			t.Fatalf("stream failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("stream did not end")
+	}
+
+	// the stream keeps the context it was opened with, the middleware's one only finds it
+	if items := sink.received(); !reflect.DeepEqual(items, []interface{}{"js"}) {
+		t.Errorf("items = %v, want the value of the opening context", items)
+	}
+}
+
+// recordingSink keeps the items of the stream and sends its end to done
+type recordingSink struct {
+	lock  sync.Mutex
+	items []interface{}
+	done  chan interface{}
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{done: make(chan interface{}, 1)}
+}
+
+func (sink *recordingSink) OnItem(item interface{}) {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	sink.items = append(sink.items, item)
+}
+
+func (sink *recordingSink) OnDone(err interface{}) {
+	sink.done <- err
+}
+
+func (sink *recordingSink) received() []interface{} {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	return append([]interface{}{}, sink.items...)
+}
@@ -0,0 +1,174 @@
+package goapi
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Frame one frame of the panic stack
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (frame Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+}
+
+// PanicReport panic recovered in a call, a subscription or an event
+type PanicReport struct {
+	// Method js name of the function or the subscription, event name for events
+	Method string
+	Args   []interface{}
+	Kwargs map[string]interface{}
+	// Value passed to panic
+	Value interface{}
+	// Frames of the panicking goroutine, the panicking function first
+	Frames []Frame
+}
+
+// PanicHandler receives every recovered panic
+type PanicHandler interface {
+	HandlePanic(report *PanicReport)
+}
+
+// PanicHandlerFunc function as PanicHandler
+type PanicHandlerFunc func(report *PanicReport)
+
+func (handler PanicHandlerFunc) HandlePanic(report *PanicReport) {
+	handler(report)
+}
+
+// PanicError error sent to js instead of the panic. Release builds get only
+// the message, dev builds get the value and the frames.
+type PanicError struct {
+	Message string  `json:"message"`
+	Method  string  `json:"method"`
+	Frames  []Frame `json:"frames,omitempty"`
+}
+
+func (err *PanicError) Error() string {
+	return err.Method + ": " + err.Message
+}
+
+var (
+	// panicLock guards defaultPanicHandler, panics are recovered on any goroutine
+	panicLock           sync.RWMutex
+	defaultPanicHandler PanicHandler = PanicHandlerFunc(LogPanic)
+)
+
+// SetPanicHandler sets the handler of registries without their own handler
+func SetPanicHandler(handler PanicHandler) {
+	panicLock.Lock()
+	defer panicLock.Unlock()
+
+	defaultPanicHandler = handler
+}
+
+// LogPanic default handler, it logs the panic with its frames
+func LogPanic(report *PanicReport) {
+	frames := make([]string, 0, len(report.Frames))
+	for _, frame := range report.Frames {
+		frames = append(frames, frame.String())
+	}
+
+	log.WithField("method", report.Method).Errorf("[!!!] panic: %v\n%s", report.Value, strings.Join(frames, "\n"))
+}
+
+// panicConfig how the registry reports panics, it is shared with event callbacks
+type panicConfig struct {
+	lock    sync.RWMutex
+	handler PanicHandler
+	dev     bool
+}
+
+func (config *panicConfig) setHandler(handler PanicHandler) {
+	config.lock.Lock()
+	defer config.lock.Unlock()
+
+	config.handler = handler
+}
+
+func (config *panicConfig) setDev(dev bool) {
+	config.lock.Lock()
+	defer config.lock.Unlock()
+
+	config.dev = dev
+}
+
+// settings handler of the panics, the registry's one or the default, and dev mode
+func (config *panicConfig) settings() (PanicHandler, bool) {
+	panicLock.RLock()
+	handler := defaultPanicHandler
+	panicLock.RUnlock()
+
+	if config == nil {
+		return handler, false
+	}
+
+	config.lock.RLock()
+	defer config.lock.RUnlock()
+
+	if config.handler != nil {
+		handler = config.handler
+	}
+
+	return handler, config.dev
+}
+
+// report sends the panic to the handler and returns the error for js,
+// it must be called by the deferred function which recovered the panic
+func (config *panicConfig) report(value interface{}, method string, callData map[string]interface{}) *PanicError {
+	args, kwargs := splitArguments(callData)
+	report := &PanicReport{
+		Method: method,
+		Args:   args,
+		Kwargs: kwargs,
+		Value:  value,
+		Frames: panicFrames(),
+	}
+
+	handler, dev := config.settings()
+	if handler != nil {
+		handler.HandlePanic(report)
+	}
+
+	if dev {
+		return &PanicError{Message: fmt.Sprint(value), Method: method, Frames: report.Frames}
+	}
+
+	return &PanicError{Message: "internal error", Method: method}
+}
+
+// panicFrames frames of the goroutine below runtime.gopanic
+func panicFrames() []Frame {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	list := make([]Frame, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			list = list[:0]
+		} else {
+			list = append(list, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	// runtime frames of the panic itself, like runtime.panicmem
+	for len(list) > 0 && strings.HasPrefix(list[0].Function, "runtime.") {
+		list = list[1:]
+	}
+
+	return list
+}
@@ -0,0 +1,87 @@
+package goapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func crashingRegistry(dev bool, reports chan *PanicReport) *JsRegistry {
+	registry := NewJsRegistry()
+	registry.RegisterFunction("crash", func(callData map[string]interface{}, callback JsCallback) error {
+		panic("database password is 42")
+	})
+	registry.SetPanicHandler(PanicHandlerFunc(func(report *PanicReport) {
+		reports <- report
+	}))
+	registry.SetDev(dev)
+
+	return &registry
+}
+
+func callPanic(t *testing.T, registry *JsRegistry) *PanicError {
+	callback := newResultCallback()
+	registry.Call(map[string]interface{}{"method": "crash", "args": []interface{}{"a"}}, callback)
+
+	select {
+	case err := <-callback.errors:
+		panicErr, ok := err.(*PanicError)
+		if !ok {
+			t.Fatalf("error = %#v, want *PanicError", err)
+		}
+		return panicErr
+	case <-time.After(time.Second):
+		t.Fatalf("crash did not answer")
+	}
+
+	return nil
+}
+
+func TestPanicSanitised(t *testing.T) {
+	reports := make(chan *PanicReport, 1)
+	panicErr := callPanic(t, crashingRegistry(false, reports))
+
+	if panicErr.Message != "internal error" || panicErr.Method != "crash" || len(panicErr.Frames) != 0 {
+		t.Errorf("release error = %+v, want internal error without frames", panicErr)
+	}
+
+	report := <-reports
+	if report.Value != "database password is 42" || report.Method != "crash" || len(report.Args) != 1 {
+		t.Errorf("report = %+v, want the value and the arguments", report)
+	}
+
+	if len(report.Frames) == 0 || !strings.Contains(report.Frames[0].Function, "crashingRegistry") {
+		t.Errorf("frames = %v, want the panicking function first", report.Frames)
+	}
+}
+
+func TestPanicDev(t *testing.T) {
+	reports := make(chan *PanicReport, 1)
+	panicErr := callPanic(t, crashingRegistry(true, reports))
+	<-reports
+
+	if panicErr.Message != "database password is 42" || len(panicErr.Frames) == 0 {
+		t.Errorf("dev error = %+v, want the value and the frames", panicErr)
+	}
+}
+
+func TestPanicOfSubscription(t *testing.T) {
+	reports := make(chan *PanicReport, 1)
+	registry := crashingRegistry(false, reports)
+	registry.RegisterSubscription("watch", func(callData map[string]interface{}, callback EventCallback) (Subscription, error) {
+		panic("watch crashed")
+	}, func(args []interface{}) ([]interface{}, error) {
+		return args, nil
+	})
+
+	registry.Subscribe(map[string]interface{}{"event": "watch", "args": []interface{}{"a"}})
+
+	select {
+	case report := <-reports:
+		if report.Value != "watch crashed" || report.Method != "watch" {
+			t.Errorf("report = %+v, want the panic of watch", report)
+		}
+	default:
+		t.Errorf("panic of the subscription is not reported")
+	}
+}
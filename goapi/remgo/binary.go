@@ -0,0 +1,125 @@
+package remgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/gorilla/websocket"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// Binary frames carry byte slices without base64: uint32 length of json message, the message,
+// then uint32 length and bytes of every attachment. The message refers to the attachments
+// with {"$binary": index} markers, frames of both directions have the same layout.
+
+// frame outbound message of the connection, binary frames are not batched with text ones
+type frame struct {
+	data   []byte
+	binary bool
+}
+
+// newFrame marshals the message to text frame, or to binary frame when it has byte slices
+func newFrame(message interface{}) frame {
+	attachments := make([][]byte, 0)
+	encoded := goapi.EncodeBinary(message, func(data []byte) interface{} {
+		attachments = append(attachments, data)
+		return map[string]interface{}{goapi.AttachmentMarker: len(attachments) - 1}
+	})
+
+	data, _ := json.Marshal(encoded)
+	if len(attachments) == 0 {
+		return frame{data: data}
+	}
+
+	return frame{data: joinFrame(data, attachments), binary: true}
+}
+
+func joinFrame(message []byte, attachments [][]byte) []byte {
+	size := 4 + len(message)
+	for _, attachment := range attachments {
+		size += 4 + len(attachment)
+	}
+
+	data := make([]byte, 0, size)
+	data = appendChunk(data, message)
+	for _, attachment := range attachments {
+		data = appendChunk(data, attachment)
+	}
+
+	return data
+}
+
+func appendChunk(data []byte, chunk []byte) []byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(chunk)))
+
+	return append(append(data, length[:]...), chunk...)
+}
+
+// splitFrame json message and attachments of binary frame
+func splitFrame(data []byte) ([]byte, [][]byte, error) {
+	chunks := make([][]byte, 0, 2)
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, nil, errors.New("broken binary frame")
+		}
+
+		length := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(length) > uint64(len(data)) {
+			return nil, nil, errors.New("broken binary frame")
+		}
+
+		chunks = append(chunks, data[:length])
+		data = data[length:]
+	}
+
+	if len(chunks) == 0 {
+		return nil, nil, errors.New("empty binary frame")
+	}
+
+	return chunks[0], chunks[1:], nil
+}
+
+// readRequests reads the next frame of the connection, it is a request or a batch of requests
+// as json array, requests of the batch share the attachments of binary frame
+func readRequests(conn *websocket.Conn) ([]CallRequest, [][]byte, error) {
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var attachments [][]byte
+	if messageType == websocket.BinaryMessage {
+		data, attachments, err = splitFrame(data)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) > 0 && data[0] == '[' {
+		requests := make([]CallRequest, 0)
+		err = json.Unmarshal(data, &requests)
+		return requests, attachments, err
+	}
+
+	request := CallRequest{}
+	err = json.Unmarshal(data, &request)
+	return []CallRequest{request}, attachments, err
+}
+
+// resolveAttachments replaces {"$binary": index} markers of the request with the attachments,
+// {"$file": uri} markers are rejected, files of the device are not read for websocket peers
+func (request *CallRequest) resolveAttachments(attachments [][]byte) error {
+	for _, data := range []map[string]interface{}{request.Call, request.Subscribe, request.Cancel, request.Stream} {
+		if _, err := goapi.ResolveBinary(data, attachments, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
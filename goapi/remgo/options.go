@@ -0,0 +1,60 @@
+package remgo
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxMessageSize limit of the inbound message when Options have none
+	DefaultMaxMessageSize = 16 << 20
+
+	// DefaultMaxBatchSize limit of the requests in one batch when Options have none
+	DefaultMaxBatchSize = 64
+)
+
+// Options limits and compression of the websocket, zero values are defaults
+type Options struct {
+	// MaxMessageSize limit of the inbound message in bytes, the connection is closed when it is exceeded
+	MaxMessageSize int64
+	// Compression enables permessage-deflate when the client supports it
+	Compression bool
+	// CompressionLevel flate level from -2 to 9, 0 is no compression, the level of gorilla/websocket when nil
+	CompressionLevel *int
+	// MaxBatchSize limit of the requests in one batch, every call and stream of a larger batch gets an error
+	MaxBatchSize int
+}
+
+func (options Options) withDefaults() Options {
+	if options.MaxMessageSize <= 0 {
+		options.MaxMessageSize = DefaultMaxMessageSize
+	}
+
+	if options.MaxBatchSize <= 0 {
+		options.MaxBatchSize = DefaultMaxBatchSize
+	}
+
+	return options
+}
+
+// upgrade upgrades the request to websocket with compression of the options
+func (options Options) upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	upgrader := upgrader
+	upgrader.EnableCompression = options.Compression
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetReadLimit(options.MaxMessageSize)
+	if options.Compression && options.CompressionLevel != nil {
+		if err := conn.SetCompressionLevel(*options.CompressionLevel); err != nil {
+			log.Errorf("Compression level %d: %v", *options.CompressionLevel, err)
+		}
+	}
+
+	return conn, nil
+}
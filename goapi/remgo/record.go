@@ -0,0 +1,100 @@
+package remgo
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// RecordEnv environment variable with the session file, the dev server records into it when it is set
+const RecordEnv = "WAND_RECORD"
+
+// Record one line of the session file: a request, a response or an event
+type Record struct {
+	// Offset time since the start of the session
+	Offset time.Duration
+	// Client id of the websocket connection, responses are paired with requests by client and ID
+	Client   string        `json:",omitempty"`
+	Request  *CallRequest  `json:",omitempty"`
+	Response *ResponseBody `json:",omitempty"`
+	Event    *EventBody    `json:",omitempty"`
+	// EventName name of the events of Subscribe and Cancel requests, as the dev server built it
+	EventName string `json:",omitempty"`
+}
+
+// Recorder writes the session file as JSON lines
+type Recorder struct {
+	file  *os.File
+	start time.Time
+	lock  sync.Mutex
+}
+
+func NewRecorder(fileName string) (*Recorder, error) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		file:  file,
+		start: time.Now(),
+	}, nil
+}
+
+// write appends the record, nil recorder records nothing
+func (recorder *Recorder) write(record Record) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	record.Offset = time.Since(recorder.start)
+	// byte slices are kept as {"$base64": "..."} markers, replay sends them to js as they are
+	line, err := json.Marshal(goapi.EncodeBinary(record, goapi.InlineBinary))
+	if err != nil {
+		return
+	}
+
+	recorder.file.Write(append(line, '\n'))
+}
+
+func (recorder *Recorder) Close() error {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	return recorder.file.Close()
+}
+
+// ReadSession reads records of the session file
+func ReadSession(fileName string) ([]Record, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]Record, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := Record{}
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
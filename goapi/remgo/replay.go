@@ -0,0 +1,366 @@
+package remgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// Match how replayed calls are matched with recorded ones
+type Match string
+
+const (
+	// MatchArgs calls match by method and arguments
+	MatchArgs Match = "args"
+	// MatchMethod calls match by method only
+	MatchMethod Match = "method"
+)
+
+// Timing when replayed responses and events are sent
+type Timing string
+
+const (
+	// TimingRealTime keeps recorded latency of responses and delays of events
+	TimingRealTime Timing = "realtime"
+	// TimingImmediate sends responses and events at once
+	TimingImmediate Timing = "immediate"
+)
+
+// ReplayOptions how the session is served
+type ReplayOptions struct {
+	Match  Match
+	Timing Timing
+}
+
+type recordedCall struct {
+	request  map[string]interface{}
+	response ResponseBody
+	latency  time.Duration
+	used     bool
+}
+
+type recordedEvent struct {
+	body  interface{}
+	delay time.Duration
+}
+
+type recordedSubscription struct {
+	request map[string]interface{}
+	// eventName name of the recorded events of the subscription
+	eventName string
+	offset    time.Duration
+	events    []recordedEvent
+	used      bool
+}
+
+// Replayer serves recorded session over the websocket protocol of the dev server. Streams
+// are not recorded, they are answered with the end of the stream with an error
+type Replayer struct {
+	options       ReplayOptions
+	calls         []*recordedCall
+	subscriptions []*recordedSubscription
+	lock          sync.Mutex
+}
+
+// NewReplayer pairs recorded requests with their responses and events
+func NewReplayer(records []Record, options ReplayOptions) *Replayer {
+	replayer := &Replayer{options: options}
+
+	type pending struct {
+		call   *recordedCall
+		offset time.Duration
+	}
+
+	calls := make(map[string]pending)
+	active := make(map[string][]*recordedSubscription)
+
+	for _, record := range records {
+		switch {
+		case record.Request != nil && record.Request.Call != nil:
+			call := &recordedCall{request: record.Request.Call}
+			calls[record.Client+":"+requestKey(record.Request.ID)] = pending{call: call, offset: record.Offset}
+
+		case record.Response != nil:
+			key := record.Client + ":" + requestKey(record.Response.ID)
+			if request, ok := calls[key]; ok {
+				request.call.response = *record.Response
+				request.call.latency = record.Offset - request.offset
+				replayer.calls = append(replayer.calls, request.call)
+				delete(calls, key)
+			}
+
+		case record.Request != nil && record.Request.Subscribe != nil:
+			key := recordedEventName(record, record.Request.Subscribe)
+			subscription := &recordedSubscription{request: record.Request.Subscribe, eventName: key, offset: record.Offset}
+			active[key] = append(active[key], subscription)
+			replayer.subscriptions = append(replayer.subscriptions, subscription)
+
+		case record.Request != nil && record.Request.Cancel != nil:
+			key := recordedEventName(record, record.Request.Cancel)
+			if len(active[key]) > 0 {
+				active[key] = active[key][1:]
+			}
+
+		case record.Event != nil:
+			if len(active[record.Event.EventName]) == 0 {
+				log.Warnf("event %s at %v has no recorded subscription, it is not replayed", record.Event.EventName, record.Offset)
+			}
+
+			for _, subscription := range active[record.Event.EventName] {
+				subscription.events = append(subscription.events, recordedEvent{
+					body:  record.Event.Body,
+					delay: record.Offset - subscription.offset,
+				})
+			}
+		}
+	}
+
+	return replayer
+}
+
+// recordedEventName name of the events of the recorded subscribe or cancel, sessions
+// recorded without it get the name built from the arguments js sent
+func recordedEventName(record Record, request map[string]interface{}) string {
+	if record.EventName != "" {
+		return record.EventName
+	}
+
+	return subscriptionKey(request)
+}
+
+func requestKey(id int) string {
+	return strconv.Itoa(id)
+}
+
+// subscriptionKey name of the events of the subscription built from the arguments js sent,
+// go builds it from typed arguments, so the names may differ, e.g. for floats
+func subscriptionKey(request map[string]interface{}) string {
+	event, _ := request["event"].(string)
+	args, _ := request["args"].([]interface{})
+
+	return goapi.BuildSubscriptionName(event, args)
+}
+
+// matches tells if the recorded request matches the replayed one
+func (replayer *Replayer) matches(recorded map[string]interface{}, request map[string]interface{}, name string) bool {
+	if recorded[name] != request[name] {
+		return false
+	}
+
+	if replayer.options.Match == MatchMethod {
+		return true
+	}
+
+	return reflect.DeepEqual(recorded["args"], request["args"]) && reflect.DeepEqual(recorded["kwargs"], request["kwargs"])
+}
+
+// findCall first unused matching call, the last matching one when all of them are used
+func (replayer *Replayer) findCall(request map[string]interface{}) *recordedCall {
+	replayer.lock.Lock()
+	defer replayer.lock.Unlock()
+
+	var last *recordedCall
+	for _, call := range replayer.calls {
+		if !replayer.matches(call.request, request, "method") {
+			continue
+		}
+
+		if !call.used {
+			call.used = true
+			return call
+		}
+
+		last = call
+	}
+
+	return last
+}
+
+func (replayer *Replayer) findSubscription(request map[string]interface{}) *recordedSubscription {
+	replayer.lock.Lock()
+	defer replayer.lock.Unlock()
+
+	var last *recordedSubscription
+	for _, subscription := range replayer.subscriptions {
+		if !replayer.matches(subscription.request, request, "event") {
+			continue
+		}
+
+		if !subscription.used {
+			subscription.used = true
+			return subscription
+		}
+
+		last = subscription
+	}
+
+	return last
+}
+
+// replayClient websocket connection served by the replayer
+type replayClient struct {
+	replayer      *Replayer
+	conn          *websocket.Conn
+	subscriptions map[string]chan struct{}
+	done          chan struct{}
+	lock          sync.Mutex
+}
+
+// wait waits for the recorded delay, false when the subscription is cancelled or the connection is closed
+func (c *replayClient) wait(delay time.Duration, stop chan struct{}) bool {
+	if c.replayer.options.Timing == TimingImmediate || delay <= 0 {
+		select {
+		case <-stop:
+			return false
+		case <-c.done:
+			return false
+		default:
+			return true
+		}
+	}
+
+	select {
+	case <-time.After(delay):
+		return true
+	case <-stop:
+		return false
+	case <-c.done:
+		return false
+	}
+}
+
+func (c *replayClient) write(message interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	resp, _ := json.Marshal(message)
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	err := c.conn.WriteMessage(websocket.TextMessage, resp)
+	if err != nil {
+		log.Errorf("replay write failed: %v", err)
+	}
+}
+
+func (c *replayClient) call(request CallRequest) {
+	call := c.replayer.findCall(request.Call)
+	if call == nil {
+		log.Errorf("no recorded response for %+v", request.Call)
+		c.write(ResponseBody{ID: request.ID, Error: "no recorded response"})
+		return
+	}
+
+	if !c.wait(call.latency, nil) {
+		return
+	}
+
+	response := call.response
+	response.ID = request.ID
+	c.write(response)
+}
+
+func (c *replayClient) subscribe(request CallRequest, stop chan struct{}) {
+	subscription := c.replayer.findSubscription(request.Subscribe)
+	if subscription == nil {
+		log.Errorf("no recorded subscription for %+v", request.Subscribe)
+		return
+	}
+
+	// the events go with the recorded name, the same as the dev server sent them
+	eventName := subscription.eventName
+	previous := time.Duration(0)
+	for _, event := range subscription.events {
+		if !c.wait(event.delay-previous, stop) {
+			return
+		}
+		previous = event.delay
+
+		uuid, _ := uuid.NewV4()
+		c.write(EventBody{ID: uuid.String(), EventName: eventName, Body: event.body})
+	}
+}
+
+func (c *replayClient) readPump() {
+	defer func() {
+		close(c.done)
+		c.conn.Close()
+	}()
+
+	for {
+		requests, attachments, err := readRequests(c.conn)
+		if err != nil {
+			break
+		}
+
+		for _, request := range requests {
+			c.handleRequest(request, attachments)
+		}
+	}
+}
+
+func (c *replayClient) handleRequest(request CallRequest, attachments [][]byte) {
+	// recorded byte slices are {"$base64": "..."} markers
+	if err := request.resolveAttachments(attachments); err == nil && request.Call != nil {
+		request.Call, _ = goapi.EncodeBinary(request.Call, goapi.InlineBinary).(map[string]interface{})
+	}
+
+	if request.Call != nil {
+		go c.call(request)
+	} else if request.Subscribe != nil {
+		stop := make(chan struct{})
+		c.cancel(subscriptionKey(request.Subscribe))
+
+		c.lock.Lock()
+		c.subscriptions[subscriptionKey(request.Subscribe)] = stop
+		c.lock.Unlock()
+
+		go c.subscribe(request, stop)
+	} else if request.Cancel != nil {
+		c.cancel(subscriptionKey(request.Cancel))
+	} else if request.Stream != nil {
+		// streams are not recorded, js gets the end of the stream instead of hanging
+		method, _ := request.Stream["method"].(string)
+		log.Errorf("no recorded stream for %s", method)
+		c.write(StreamDoneBody{ID: request.ID, Done: true, Error: "streams are not replayed"})
+	} else if request.Credit == nil && request.Close == nil {
+		// credits and closes are skipped, they come for the streams which are already done
+		log.Errorf("Unknown request %+v", request)
+	}
+}
+
+func (c *replayClient) cancel(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stop := c.subscriptions[key]
+	if stop != nil {
+		delete(c.subscriptions, key)
+		close(stop)
+	}
+}
+
+// ServeWs serves the session to the websocket connection
+func (replayer *Replayer) ServeWs(w http.ResponseWriter, r *http.Request) {
+	conn, err := Options{Compression: true}.withDefaults().upgrade(w, r)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	client := &replayClient{
+		replayer:      replayer,
+		conn:          conn,
+		subscriptions: make(map[string]chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go client.readPump()
+}
@@ -0,0 +1,102 @@
+package remgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// float32 argument is formatted by go differently from the number js sent
+const floatEventName = "watchReading:s1:0.10000000149011612"
+
+func replaySession() []Record {
+	subscribe := map[string]interface{}{"event": "watchReading", "args": []interface{}{"s1", 0.1}}
+	call := map[string]interface{}{"method": "scale", "args": []interface{}{float64(2)}}
+
+	return []Record{
+		{Client: "c", Request: &CallRequest{ID: 1, Call: call}},
+		{Client: "c", Response: &ResponseBody{ID: 1, Success: float64(4)}, Offset: time.Millisecond},
+		{Client: "c", Request: &CallRequest{ID: 2, Subscribe: subscribe}, EventName: floatEventName},
+		{Event: &EventBody{EventName: floatEventName, Body: "first"}},
+		{Event: &EventBody{EventName: "watchReading:other", Body: "lost"}},
+		{Event: &EventBody{EventName: floatEventName, Body: "second"}},
+	}
+}
+
+func dialReplayer(t *testing.T, records []Record) *websocket.Conn {
+	replayer := NewReplayer(records, ReplayOptions{Match: MatchArgs, Timing: TimingImmediate})
+	server := httptest.NewServer(http.HandlerFunc(replayer.ServeWs))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func readReplayed(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	message := make(map[string]interface{})
+	if err := json.Unmarshal(data, &message); err != nil {
+		t.Fatalf("wrong message %s: %v", data, err)
+	}
+
+	return message
+}
+
+func TestReplayCall(t *testing.T) {
+	conn := dialReplayer(t, replaySession())
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"id": 7, "call": {"method": "scale", "args": [2]}}`))
+
+	response := readReplayed(t, conn)
+	if response["ID"] != float64(7) || response["Success"] != float64(4) {
+		t.Errorf("response = %v, want recorded success with ID 7", response)
+	}
+}
+
+func TestReplaySubscriptionEventNames(t *testing.T) {
+	conn := dialReplayer(t, replaySession())
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"id": 3, "subscribe": {"event": "watchReading", "args": ["s1", 0.1]}}`))
+
+	for _, body := range []string{"first", "second"} {
+		event := readReplayed(t, conn)
+		if event["EventName"] != floatEventName || event["Body"] != body {
+			t.Errorf("event = %v, want %s of %s", event, body, floatEventName)
+		}
+	}
+}
+
+func TestReplayStream(t *testing.T) {
+	conn := dialReplayer(t, replaySession())
+	conn.WriteMessage(websocket.TextMessage, []byte(`[{"id": 4, "stream": {"method": "ticks", "credits": 16}}, {"id": 5, "close": {"stream": 4}}]`))
+
+	done := readReplayed(t, conn)
+	if done["ID"] != float64(4) || done["Done"] != true || done["Error"] == nil {
+		t.Errorf("stream = %v, want its end with an error", done)
+	}
+}
+
+func TestRecordedEventName(t *testing.T) {
+	request := map[string]interface{}{"event": "watchReading", "args": []interface{}{"s1", 0.1}}
+
+	if name := recordedEventName(Record{EventName: "watchReading:x"}, request); name != "watchReading:x" {
+		t.Errorf("recordedEventName() = %s, want the recorded name", name)
+	}
+
+	if name := recordedEventName(Record{}, request); name != "watchReading:s1:0.1" {
+		t.Errorf("recordedEventName() without the recorded name = %s", name)
+	}
+}
@@ -0,0 +1,117 @@
+package remgo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultCredits items the stream may send before js grants credits, for clients which do not tell
+const defaultCredits = 16
+
+// StreamControl credit or close of the stream opened by the request with ID Stream
+type StreamControl struct {
+	Stream int
+	Count  int
+}
+
+// StreamItemBody item of the stream opened by the request with ID
+type StreamItemBody struct {
+	ID   int
+	Item interface{}
+}
+
+// StreamDoneBody end of the stream opened by the request with ID, Error is nil when the function succeeded
+type StreamDoneBody struct {
+	ID    int
+	Done  bool
+	Error interface{}
+}
+
+type streamSink struct {
+	id     int
+	client *Client
+}
+
+func (sink streamSink) OnItem(item interface{}) {
+	sink.client.write(StreamItemBody{ID: sink.id, Item: item})
+}
+
+func (sink streamSink) OnDone(err interface{}) {
+	sink.client.forgetStream(sink.id)
+	sink.client.write(StreamDoneBody{ID: sink.id, Done: true, Error: err})
+}
+
+// streamKey key of the stream in the registry, ids of the requests are unique only in the connection
+func (c *Client) streamKey(id int) string {
+	return c.id + ":" + strconv.Itoa(id)
+}
+
+func (c *Client) openStream(id int, callData map[string]interface{}) {
+	credits := defaultCredits
+	if value, ok := callData["credits"].(float64); ok {
+		credits = int(value)
+	}
+
+	c.lock.Lock()
+	c.streams[id] = true
+	c.lock.Unlock()
+
+	c.registry.OpenStream(context.Background(), c.streamKey(id), callData, credits, streamSink{id: id, client: c})
+}
+
+func (c *Client) forgetStream(id int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.streams, id)
+}
+
+// closeStreams stops the streams of the connection which is closed
+func (c *Client) closeStreams() {
+	c.lock.Lock()
+	c.closed = true
+	ids := make([]int, 0, len(c.streams))
+	for id := range c.streams {
+		ids = append(ids, id)
+	}
+	c.streams = make(map[int]bool)
+	c.lock.Unlock()
+
+	for _, id := range ids {
+		c.registry.CloseStream(c.streamKey(id))
+	}
+}
+
+// write sends the body to the connection unless it is closed, it gives up
+// after writeWait when the connection does not take messages
+func (c *Client) write(body interface{}) {
+	message := newFrame(body)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.send <- message:
+	case <-c.done:
+	case <-time.After(writeWait):
+		log.Errorf("dropping message, connection %s is stuck", c.id)
+	}
+}
+
+// closeSend closes send for the hub, writers do not send to it after that
+func (c *Client) closeSend() {
+	close(c.done)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+	close(c.send)
+}
@@ -2,9 +2,11 @@ package remgo
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/satori/go.uuid"
@@ -37,21 +39,29 @@ const (
 
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
-
-	// Maximum message size allowed from peer.
-	maxMessageSize = 512
 )
 
 type Client struct {
 	registry *goapi.JsRegistry
 
+	// id of the connection in the session file
+	id string
+
 	// The websocket connection.
 	conn *websocket.Conn
 
 	// Buffered channel of outbound messages.
-	send chan []byte
+	send chan frame
 
 	hub *Hub
+
+	// done is closed by the hub before send, writers waiting for send give up
+	done chan struct{}
+
+	// lock guards streams and closed, streams write to send from their goroutines
+	lock    sync.Mutex
+	streams map[int]bool
+	closed  bool
 }
 
 type Hub struct {
@@ -59,13 +69,19 @@ type Hub struct {
 	clients map[*Client]bool
 
 	// Inbound messages from the clients.
-	broadcast chan []byte
+	broadcast chan frame
 
 	// Register requests from the clients.
 	register chan *Client
 
 	// Unregister requests from clients.
 	unregister chan *Client
+
+	// Session recorder, nil when the session is not recorded.
+	recorder *Recorder
+
+	// Limits and compression of the connections.
+	options Options
 }
 
 /**
@@ -77,10 +93,11 @@ type Hook interface {
 
 func NewHub() *Hub {
 	hub := &Hub{
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan frame),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
+		options:    Options{}.withDefaults(),
 	}
 
 	log.AddHook(hub)
@@ -88,6 +105,16 @@ func NewHub() *Hub {
 	return hub
 }
 
+// Configure sets limits and compression of the connections accepted after it
+func (h *Hub) Configure(options Options) {
+	h.options = options.withDefaults()
+}
+
+// Record writes every request, response and event to the recorder
+func (h *Hub) Record(recorder *Recorder) {
+	h.recorder = recorder
+}
+
 func (h *Hub) Levels() []log.Level {
 	return log.AllLevels
 }
@@ -164,7 +191,7 @@ func (h *Hub) Fire(entry *log.Entry) error {
 
 func (h *Hub) SendEvent(event LogEvent) {
 	resp, _ := json.Marshal(event)
-	h.broadcast <- resp
+	h.broadcast <- frame{data: resp}
 }
 
 func (h *Hub) OnEvent(eventName string, body interface{}) {
@@ -174,8 +201,9 @@ func (h *Hub) OnEvent(eventName string, body interface{}) {
 	id := uuid.String()
 
 	event := EventBody{EventName: eventName, Body: body, ID: id}
-	resp, _ := json.Marshal(event)
-	h.broadcast <- resp
+	h.recorder.write(Record{Event: &event})
+
+	h.broadcast <- newFrame(event)
 }
 
 func (h *Hub) Run(registry *goapi.JsRegistry) {
@@ -188,17 +216,21 @@ func (h *Hub) Run(registry *goapi.JsRegistry) {
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				close(client.send)
+				client.closeSend()
 			}
 		case message := <-h.broadcast:
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
-			}
+			h.sendAll(message)
+		}
+	}
+}
+
+func (h *Hub) sendAll(message frame) {
+	for client := range h.clients {
+		select {
+		case client.send <- message:
+		default:
+			client.closeSend()
+			delete(h.clients, client)
 		}
 	}
 }
@@ -208,6 +240,12 @@ type CallRequest struct {
 	Call      map[string]interface{}
 	Subscribe map[string]interface{}
 	Cancel    map[string]interface{}
+	// Stream opens streaming function, like Call with optional "credits"
+	Stream map[string]interface{}
+	// Credit lets the stream send Count more items
+	Credit *StreamControl
+	// Close stops the stream when js stopped reading
+	Close *StreamControl
 }
 
 type ResponseBody struct {
@@ -233,18 +271,20 @@ type EventBody struct {
 type callMeOnResult struct {
 	Time      time.Time
 	ID        int
+	client    *Client
 	request   interface{}
-	response  chan []byte
-	broadcast chan []byte
+	broadcast chan frame
+	recorder  *Recorder
 }
 
-func newRequestHanler(id int, request interface{}, response chan []byte, broadcast chan []byte) *callMeOnResult {
+func newRequestHanler(id int, request interface{}, client *Client) *callMeOnResult {
 	return &callMeOnResult{
 		Time:      time.Now(),
 		ID:        id,
+		client:    client,
 		request:   request,
-		response:  response,
-		broadcast: broadcast,
+		broadcast: client.hub.broadcast,
+		recorder:  client.hub.recorder,
 	}
 }
 
@@ -261,23 +301,40 @@ func (call callMeOnResult) SendStat(data ResponseBody) {
 	}
 
 	resp, _ := json.Marshal(statBody)
-	call.broadcast <- resp
+	call.broadcast <- frame{data: resp}
 }
 
 func (call callMeOnResult) OnSuccess(data interface{}) {
 	respBody := ResponseBody{Success: data, ID: call.ID}
 	call.SendStat(respBody)
+	call.recorder.write(Record{Client: call.client.id, Response: &respBody})
 
-	resp, _ := json.Marshal(respBody)
-	call.response <- resp
+	call.client.write(respBody)
 }
 
 func (call callMeOnResult) OnError(data interface{}) {
 	respBody := ResponseBody{Error: data, ID: call.ID}
 	call.SendStat(respBody)
+	call.recorder.write(Record{Client: call.client.id, Response: &respBody})
 
-	resp, _ := json.Marshal(respBody)
-	call.response <- resp
+	call.client.write(respBody)
+}
+
+// record writes the request to the session, subscriptions keep the name of their events,
+// replay pairs them with the recorded events by it
+func (c *Client) record(request CallRequest) {
+	if c.hub.recorder == nil {
+		return
+	}
+
+	record := Record{Client: c.id, Request: &request}
+	for _, data := range []map[string]interface{}{request.Subscribe, request.Cancel} {
+		if data != nil {
+			record.EventName, _ = c.registry.SubscriptionName(data)
+		}
+	}
+
+	c.hub.recorder.write(record)
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -287,32 +344,81 @@ func (call callMeOnResult) OnError(data interface{}) {
 // reads from this goroutine.
 func (c *Client) readPump() {
 	defer func() {
+		c.closeStreams()
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
-	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 	for {
 		debug.SetPanicOnFault(true)
 
-		request := CallRequest{}
-		err := c.conn.ReadJSON(&request)
+		requests, attachments, err := readRequests(c.conn)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 			}
 			break
 		}
+
+		if len(requests) > c.hub.options.MaxBatchSize {
+			c.rejectBatch(requests, attachments)
+			continue
+		}
+
+		// responses of the batch come one by one, js matches them by ID
+		for _, request := range requests {
+			c.handleRequest(request, attachments)
+		}
+	}
+}
+
+// rejectBatch answers every call and stream of the batch which is larger than the limit
+// with an error. Subscriptions, their cancellations and the credits of the streams have
+// no answer to carry the error, js would wait for them forever, so they are handled.
+func (c *Client) rejectBatch(requests []CallRequest, attachments [][]byte) {
+	message := fmt.Sprintf("batch of %d requests exceeds the limit of %d", len(requests), c.hub.options.MaxBatchSize)
+	log.Errorf("%s", message)
+
+	for _, request := range requests {
 		if request.Call != nil {
-			callback := newRequestHanler(request.ID, request, c.send, c.hub.broadcast)
-			c.registry.Call(request.Call, callback)
-		} else if request.Subscribe != nil {
-			c.registry.Subscribe(request.Subscribe)
-		} else if request.Cancel != nil {
-			c.registry.CancelSubscription(request.Cancel)
+			newRequestHanler(request.ID, request, c).OnError(message)
+		} else if request.Stream != nil {
+			c.write(StreamDoneBody{ID: request.ID, Done: true, Error: message})
 		} else {
-			log.Errorf("Unknown request %+v", request)
+			c.handleRequest(request, attachments)
+		}
+	}
+}
+
+func (c *Client) handleRequest(request CallRequest, attachments [][]byte) {
+	if err := request.resolveAttachments(attachments); err != nil {
+		log.Errorf("Wrong attachments of request %d: %v", request.ID, err)
+		if request.Call != nil {
+			newRequestHanler(request.ID, request, c).OnError(err.Error())
 		}
+		return
+	}
+
+	if request.Call != nil || request.Subscribe != nil || request.Cancel != nil {
+		c.record(request)
+	}
+
+	if request.Call != nil {
+		callback := newRequestHanler(request.ID, request, c)
+		c.registry.Call(request.Call, callback)
+	} else if request.Subscribe != nil {
+		c.registry.Subscribe(request.Subscribe)
+	} else if request.Cancel != nil {
+		c.registry.CancelSubscription(request.Cancel)
+	} else if request.Stream != nil {
+		c.openStream(request.ID, request.Stream)
+	} else if request.Credit != nil {
+		c.registry.StreamCredit(c.streamKey(request.Credit.Stream), request.Credit.Count)
+	} else if request.Close != nil {
+		c.forgetStream(request.Close.Stream)
+		c.registry.CloseStream(c.streamKey(request.Close.Stream))
+	} else {
+		log.Errorf("Unknown request %+v", request)
 	}
 }
 
@@ -337,20 +443,14 @@ func (c *Client) writePump() {
 				return
 			}
 
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			// Add queued chat messages to the current websocket message.
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write(newline)
-				w.Write(<-c.send)
+			// Add queued messages to the current websocket message,
+			// binary frames are written by themselves
+			queued := []frame{message}
+			for n := len(c.send); n > 0; n-- {
+				queued = append(queued, <-c.send)
 			}
 
-			if err := w.Close(); err != nil {
+			if err := c.writeFrames(queued); err != nil {
 				return
 			}
 		case <-ticker.C:
@@ -362,18 +462,54 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeFrames writes binary frames one by one and joins text frames with newline
+func (c *Client) writeFrames(frames []frame) error {
+	for len(frames) > 0 {
+		if frames[0].binary {
+			if err := c.conn.WriteMessage(websocket.BinaryMessage, frames[0].data); err != nil {
+				return err
+			}
+			frames = frames[1:]
+			continue
+		}
+
+		w, err := c.conn.NextWriter(websocket.TextMessage)
+		if err != nil {
+			return err
+		}
+
+		w.Write(frames[0].data)
+		frames = frames[1:]
+		for len(frames) > 0 && !frames[0].binary {
+			w.Write(newline)
+			w.Write(frames[0].data)
+			frames = frames[1:]
+		}
+
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // serveWs handles websocket requests from the peer.
 func ServeWs(registry *goapi.JsRegistry, hub *Hub, w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := hub.options.upgrade(w, r)
 	if err != nil {
 		log.Println(err)
 		return
 	}
+	uuid, _ := uuid.NewV4()
 	client := &Client{
 		registry: registry,
+		id:       uuid.String(),
 		hub:      hub,
 		conn:     conn,
-		send:     make(chan []byte, 256),
+		send:     make(chan frame, 256),
+		done:     make(chan struct{}),
+		streams:  make(map[int]bool),
 	}
 
 	client.hub.register <- client
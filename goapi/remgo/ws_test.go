@@ -0,0 +1,148 @@
+package remgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+type testSubscription struct{}
+
+func (testSubscription) Cancel() {}
+
+func testRegistry() *goapi.JsRegistry {
+	registry := goapi.NewJsRegistry()
+	registry.RegisterFunction("ping", func(callData map[string]interface{}, callback goapi.JsCallback) error {
+		callback.OnSuccess("pong")
+		return nil
+	})
+	registry.RegisterSubscription("watch", func(callData map[string]interface{}, callback goapi.EventCallback) (goapi.Subscription, error) {
+		return testSubscription{}, nil
+	}, func(args []interface{}) ([]interface{}, error) {
+		return args, nil
+	})
+
+	return &registry
+}
+
+func dialHub(t *testing.T, registry *goapi.JsRegistry, options Options, dialer *websocket.Dialer) (*websocket.Conn, *http.Response) {
+	hub := NewHub()
+	hub.Configure(options)
+	go hub.Run(registry)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(registry, hub, w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, response, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, response
+}
+
+// readResponses reads the messages until every ID of the requests is answered,
+// logs and stats of the hub are skipped
+func readResponses(t *testing.T, conn *websocket.Conn, ids ...int) map[int]map[string]interface{} {
+	responses := make(map[int]map[string]interface{})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for len(responses) < len(ids) {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed, answered %v of %v: %v", responses, ids, err)
+		}
+
+		for _, line := range bytes.Split(data, newline) {
+			message := make(map[string]interface{})
+			if err := json.Unmarshal(line, &message); err != nil {
+				t.Fatalf("wrong message %s: %v", line, err)
+			}
+
+			if id, ok := message["ID"].(float64); ok {
+				responses[int(id)] = message
+			}
+		}
+	}
+
+	return responses
+}
+
+func TestRejectBatch(t *testing.T) {
+	registry := testRegistry()
+	conn, _ := dialHub(t, registry, Options{MaxBatchSize: 2}, websocket.DefaultDialer)
+
+	batch := `[{"id": 1, "call": {"method": "ping"}}, {"id": 2, "stream": {"method": "ticks"}}, {"id": 3, "subscribe": {"event": "watch", "args": ["a"]}}]`
+	conn.WriteMessage(websocket.TextMessage, []byte(batch))
+
+	responses := readResponses(t, conn, 1, 2)
+	if responses[1]["Error"] == nil || responses[1]["Success"] != nil {
+		t.Errorf("call = %v, want an error", responses[1])
+	}
+
+	if responses[2]["Done"] != true || responses[2]["Error"] == nil {
+		t.Errorf("stream = %v, want its end with an error", responses[2])
+	}
+
+	// the subscription is handled after the answers of the call and the stream
+	deadline := time.Now().Add(5 * time.Second)
+	for len(registry.ActiveSubscriptions()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if active := registry.ActiveSubscriptions(); len(active) != 1 || active[0] != "watch:a" {
+		t.Errorf("subscriptions = %v, want the subscription of the rejected batch", active)
+	}
+}
+
+func TestBatchUnderLimit(t *testing.T) {
+	conn, _ := dialHub(t, testRegistry(), Options{MaxBatchSize: 2}, websocket.DefaultDialer)
+	conn.WriteMessage(websocket.TextMessage, []byte(`[{"id": 1, "call": {"method": "ping"}}, {"id": 2, "call": {"method": "ping"}}]`))
+
+	for id, response := range readResponses(t, conn, 1, 2) {
+		if response["Success"] != "pong" {
+			t.Errorf("call %d = %v, want pong", id, response)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	level := 9
+	tests := []struct {
+		name       string
+		options    Options
+		compressed bool
+	}{
+		{"default", Options{}, false},
+		{"enabled", Options{Compression: true}, true},
+		{"level", Options{Compression: true, CompressionLevel: &level}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialer := &websocket.Dialer{EnableCompression: true}
+			conn, response := dialHub(t, testRegistry(), test.options, dialer)
+
+			extensions := response.Header.Get("Sec-Websocket-Extensions")
+			if compressed := strings.Contains(extensions, "permessage-deflate"); compressed != test.compressed {
+				t.Errorf("extensions = %q, want compression %v", extensions, test.compressed)
+			}
+
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"id": 1, "call": {"method": "ping"}}`))
+			if response := readResponses(t, conn, 1)[1]; response["Success"] != "pong" {
+				t.Errorf("call = %v, want pong", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrStreamClosed is returned by Send when js stopped reading the stream
+var ErrStreamClosed = errors.New("stream is closed")
+
+// Stream writer of the items of streaming function, js reads them as AsyncIterable
+type Stream interface {
+	// Send sends the item to js, it waits until js asks for more items
+	// and returns ErrStreamClosed when js stopped reading
+	Send(item interface{}) error
+	// Context is done when js stopped reading
+	Context() context.Context
+}
+
+// StreamSink transport of the stream to js
+type StreamSink interface {
+	// OnItem sends the item
+	OnItem(item interface{})
+	// OnDone ends the stream, err is nil when the function succeeded
+	OnDone(err interface{})
+}
+
+// StreamFunc adapter of streaming function, it returns when all the items are sent
+type StreamFunc func(callData map[string]interface{}, stream Stream) error
+
+type streamContextKey struct{}
+
+// credit stream with backpressure, Send waits for credits which js grants for items it is ready to read
+type creditStream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sink    StreamSink
+	lock    sync.Mutex
+	changed chan struct{}
+	credits int
+	done    bool
+}
+
+func newCreditStream(ctx context.Context, credits int, sink StreamSink) *creditStream {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &creditStream{
+		ctx:     ctx,
+		cancel:  cancel,
+		sink:    sink,
+		changed: make(chan struct{}),
+		credits: credits,
+	}
+}
+
+func (stream *creditStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *creditStream) Send(item interface{}) error {
+	for {
+		stream.lock.Lock()
+		if stream.done || stream.ctx.Err() != nil {
+			stream.lock.Unlock()
+			return ErrStreamClosed
+		}
+
+		if stream.credits > 0 {
+			// the sink may block on the transport, grant and finish must not wait for it
+			stream.credits--
+			stream.lock.Unlock()
+			stream.sink.OnItem(item)
+			return nil
+		}
+
+		changed := stream.changed
+		stream.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-stream.ctx.Done():
+		}
+	}
+}
+
+// grant gives count more credits and wakes up Send
+func (stream *creditStream) grant(count int) {
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+
+	stream.credits += count
+	close(stream.changed)
+	stream.changed = make(chan struct{})
+}
+
+// finish ends the stream once, items and results after it are dropped
+func (stream *creditStream) finish(err interface{}, notify bool) {
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+
+	if stream.done {
+		return
+	}
+
+	stream.done = true
+	stream.cancel()
+	if notify {
+		stream.sink.OnDone(err)
+	}
+}
+
+func (stream *creditStream) OnSuccess(result interface{}) {
+	stream.finish(nil, true)
+}
+
+func (stream *creditStream) OnError(err interface{}) {
+	stream.finish(err, true)
+}
+
+// streamFromContext stream of the call opened with OpenStream
+func streamFromContext(ctx context.Context) Stream {
+	stream, _ := ctx.Value(streamContextKey{}).(Stream)
+	return stream
+}
+
+type streamRegistry struct {
+	lock   sync.Mutex
+	active map[string]*creditStream
+}
+
+func newStreamRegistry() *streamRegistry {
+	return &streamRegistry{active: make(map[string]*creditStream)}
+}
+
+func (registry *streamRegistry) add(key string, stream *creditStream) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if previous := registry.active[key]; previous != nil {
+		previous.finish(nil, false)
+	}
+	registry.active[key] = stream
+}
+
+func (registry *streamRegistry) get(key string) *creditStream {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return registry.active[key]
+}
+
+func (registry *streamRegistry) remove(key string, stream *creditStream) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if registry.active[key] == stream {
+		delete(registry.active, key)
+	}
+}
+
+// eventStreamSink sends the items as events of the registry, native transport of the streams
+type eventStreamSink struct {
+	eventName string
+	callback  *JsEventCall
+}
+
+func (sink eventStreamSink) OnItem(item interface{}) {
+	if sink.callback.callback != nil {
+		sink.callback.callback.OnEvent(sink.eventName, map[string]interface{}{"Item": item})
+	}
+}
+
+func (sink eventStreamSink) OnDone(err interface{}) {
+	if sink.callback.callback != nil {
+		sink.callback.callback.OnEvent(sink.eventName, map[string]interface{}{"Done": true, "Error": err})
+	}
+}
@@ -0,0 +1,146 @@
+package goapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// blockingSink holds OnItem until release is closed
+type blockingSink struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (sink blockingSink) OnItem(item interface{}) {
+	sink.entered <- struct{}{}
+	<-sink.release
+}
+
+func (sink blockingSink) OnDone(err interface{}) {}
+
+func sendAsync(stream Stream, item interface{}) chan error {
+	sent := make(chan error, 1)
+	go func() { sent <- stream.Send(item) }()
+
+	return sent
+}
+
+func TestStreamCredits(t *testing.T) {
+	sink := newRecordingSink()
+	stream := newCreditStream(context.Background(), 1, sink)
+
+	if err := stream.Send(1); err != nil {
+		t.Fatalf("Send() with credit failed: %v", err)
+	}
+
+	sent := sendAsync(stream, 2)
+	select {
+	case err := <-sent:
+		t.Fatalf("Send() without credits = %v, want it to wait", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stream.grant(1)
+	select {
+	case err := <-sent:
+		if err != nil {
+			t.Fatalf("Send() after grant failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Send() waits after grant")
+	}
+
+	if items := sink.received(); !reflect.DeepEqual(items, []interface{}{1, 2}) {
+		t.Errorf("items = %v, want [1 2]", items)
+	}
+}
+
+func TestStreamFinish(t *testing.T) {
+	sink := newRecordingSink()
+	stream := newCreditStream(context.Background(), 0, sink)
+
+	sent := sendAsync(stream, 1)
+	stream.finish(nil, false)
+
+	select {
+	case err := <-sent:
+		if err != ErrStreamClosed {
+			t.Errorf("waiting Send() = %v, want ErrStreamClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Send() waits after finish")
+	}
+
+	if stream.Context().Err() == nil {
+		t.Errorf("context of the finished stream is not done")
+	}
+
+	stream.OnError("late")
+	select {
+	case err := <-sink.done:
+		t.Errorf("finished stream ended again with %v", err)
+	default:
+	}
+}
+
+func TestStreamSinkOutOfLock(t *testing.T) {
+	sink := blockingSink{entered: make(chan struct{}), release: make(chan struct{})}
+	stream := newCreditStream(context.Background(), 1, sink)
+
+	sent := sendAsync(stream, 1)
+	<-sink.entered
+
+	granted := make(chan struct{})
+	go func() {
+		stream.grant(1)
+		stream.finish(nil, false)
+		close(granted)
+	}()
+
+	select {
+	case <-granted:
+	case <-time.After(time.Second):
+		t.Fatalf("grant and finish wait for the sink")
+	}
+
+	close(sink.release)
+	if err := <-sent; err != nil {
+		t.Errorf("Send() = %v, want the item sent", err)
+	}
+}
+
+func TestOpenStreamResult(t *testing.T) {
+	registry := NewJsRegistry()
+	registry.RegisterStream("count", func(callData map[string]interface{}, stream Stream) error {
+		for i := 0; i < 3; i++ {
+			if err := stream.Send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	sink := newRecordingSink()
+	registry.OpenStream(context.Background(), "1", map[string]interface{}{"method": "count"}, 2, sink)
+	registry.StreamCredit("1", 1)
+
+	select {
+	case err := <-sink.done:
+		if err != nil {
+			t.Fatalf("stream failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("stream did not end")
+	}
+
+	if items := sink.received(); !reflect.DeepEqual(items, []interface{}{0, 1, 2}) {
+		t.Errorf("items = %v, want [0 1 2]", items)
+	}
+
+	_, err := registry.CallSync(context.Background(), map[string]interface{}{"method": "count"})
+	if err == nil {
+		t.Errorf("call of the stream as a function succeeded")
+	}
+}
@@ -0,0 +1,330 @@
+// Package testbridge calls functions of a goapi.JsRegistry from Go tests the
+// way js calls them: arguments and results pass through json, so the tests
+// exercise the same decoding of the generated adapters.
+//
+//	bridge := testbridge.New(demolink.Registry())
+//	defer bridge.Close()
+//
+//	user := demo.User{}
+//	err := bridge.CallInto(&user, "getUser", "42")
+//
+//	sub, err := bridge.Subscribe("watchUser", "42")
+//	event, err := sub.Next()
+//	err = sub.Cancel()
+//
+//	bridge.AssertNoLeaks(t)
+package testbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// DefaultTimeout how long calls and events are waited for
+const DefaultTimeout = 5 * time.Second
+
+// ErrTimeout the result or the event did not come in time
+var ErrTimeout = errors.New("testbridge: timeout")
+
+// Bridge calls functions and subscriptions of the registry
+type Bridge struct {
+	// Timeout of calls and events
+	Timeout time.Duration
+
+	registry      *goapi.JsRegistry
+	subscriptions map[string][]*Subscription
+	lock          sync.Mutex
+}
+
+type errorKey struct{}
+
+// installed registries with the middleware which reports errors of subscriptions
+var installed sync.Map
+
+// New wraps the registry, it becomes the event callback of the registry
+func New(registry *goapi.JsRegistry) *Bridge {
+	bridge := &Bridge{
+		Timeout:       DefaultTimeout,
+		registry:      registry,
+		subscriptions: make(map[string][]*Subscription),
+	}
+
+	registry.RegisterEventCallback(bridge)
+	if _, ok := installed.LoadOrStore(registry, true); ok {
+		return bridge
+	}
+
+	registry.UseSubscription(func(next goapi.SubscriptionHandler) goapi.SubscriptionHandler {
+		return func(info *goapi.SubscriptionInfo) error {
+			err := next(info)
+			if result, ok := info.Context.Value(errorKey{}).(*error); ok {
+				*result = err
+			}
+
+			return err
+		}
+	})
+
+	return bridge
+}
+
+// Close removes the event callback of the registry
+func (bridge *Bridge) Close() {
+	bridge.registry.RegisterEventCallback(nil)
+}
+
+// Call calls the method with positional arguments and returns the result decoded from json
+func (bridge *Bridge) Call(method string, args ...interface{}) (interface{}, error) {
+	return bridge.CallNamed(method, args, nil)
+}
+
+// CallInto calls the method and decodes the result into out
+func (bridge *Bridge) CallInto(out interface{}, method string, args ...interface{}) error {
+	result, err := bridge.Call(method, args...)
+	if err != nil {
+		return err
+	}
+
+	return convert(result, out)
+}
+
+// CallNamed calls the method with positional and named arguments
+func (bridge *Bridge) CallNamed(method string, args []interface{}, kwargs map[string]interface{}) (interface{}, error) {
+	callData, err := jsonCallData(map[string]interface{}{
+		"method": method,
+		"args":   args,
+		"kwargs": kwargs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	callback := &resultCallback{done: make(chan struct{})}
+	bridge.registry.Call(callData, callback)
+
+	select {
+	case <-callback.done:
+	case <-time.After(bridge.Timeout):
+		return nil, ErrTimeout
+	}
+
+	if callback.failed {
+		return nil, callError(callback.err)
+	}
+
+	var result interface{}
+	err = convert(callback.result, &result)
+	return result, err
+}
+
+// Subscribe subscribes to the event with the arguments, events are collected by the subscription
+func (bridge *Bridge) Subscribe(event string, args ...interface{}) (*Subscription, error) {
+	callData, err := jsonCallData(map[string]interface{}{
+		"event": event,
+		"args":  args,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jsonArgs, _ := callData["args"].([]interface{})
+	subscription := &Subscription{
+		bridge:   bridge,
+		key:      goapi.BuildSubscriptionName(event, jsonArgs),
+		callData: callData,
+		events:   make(chan interface{}, 1024),
+	}
+
+	bridge.lock.Lock()
+	bridge.subscriptions[subscription.key] = append(bridge.subscriptions[subscription.key], subscription)
+	bridge.lock.Unlock()
+
+	var subscribeErr error
+	ctx := context.WithValue(context.Background(), errorKey{}, &subscribeErr)
+	bridge.registry.SubscribeContext(ctx, callData)
+
+	if subscribeErr != nil {
+		bridge.remove(subscription)
+		return nil, subscribeErr
+	}
+
+	return subscription, nil
+}
+
+// Leaks keys of the subscriptions which are not cancelled
+func (bridge *Bridge) Leaks() []string {
+	return bridge.registry.ActiveSubscriptions()
+}
+
+// AssertNoLeaks fails the test when any subscription is not cancelled
+func (bridge *Bridge) AssertNoLeaks(t testing.TB) {
+	t.Helper()
+
+	if leaks := bridge.Leaks(); len(leaks) > 0 {
+		t.Errorf("subscriptions are not cancelled: %s", strings.Join(leaks, ", "))
+	}
+}
+
+// OnEvent receives events of the registry, as the js event callback
+func (bridge *Bridge) OnEvent(eventName string, data interface{}) {
+	var event interface{}
+	err := convert(data, &event)
+	if err != nil {
+		event = err
+	}
+
+	bridge.lock.Lock()
+	defer bridge.lock.Unlock()
+
+	for _, subscription := range bridge.subscriptions[eventName] {
+		select {
+		case subscription.events <- event:
+		default:
+		}
+	}
+}
+
+func (bridge *Bridge) remove(subscription *Subscription) {
+	bridge.lock.Lock()
+	defer bridge.lock.Unlock()
+
+	list := bridge.subscriptions[subscription.key]
+	for i, item := range list {
+		if item == subscription {
+			bridge.subscriptions[subscription.key] = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+}
+
+// Subscription events of one subscription of the bridge
+type Subscription struct {
+	bridge   *Bridge
+	key      string
+	callData map[string]interface{}
+	events   chan interface{}
+}
+
+// Key name of the events, as js builds it
+func (subscription *Subscription) Key() string {
+	return subscription.key
+}
+
+// Next waits for the next event and returns it decoded from json
+func (subscription *Subscription) Next() (interface{}, error) {
+	select {
+	case event := <-subscription.events:
+		if err, ok := event.(error); ok {
+			return nil, err
+		}
+
+		return event, nil
+	case <-time.After(subscription.bridge.Timeout):
+		return nil, ErrTimeout
+	}
+}
+
+// NextInto waits for the next event and decodes it into out
+func (subscription *Subscription) NextInto(out interface{}) error {
+	event, err := subscription.Next()
+	if err != nil {
+		return err
+	}
+
+	return convert(event, out)
+}
+
+// Collect waits for count events
+func (subscription *Subscription) Collect(count int) ([]interface{}, error) {
+	events := make([]interface{}, 0, count)
+	for len(events) < count {
+		event, err := subscription.Next()
+		if err != nil {
+			return events, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// Cancel cancels the subscription
+func (subscription *Subscription) Cancel() error {
+	subscription.bridge.remove(subscription)
+
+	var cancelErr error
+	ctx := context.WithValue(context.Background(), errorKey{}, &cancelErr)
+	subscription.bridge.registry.CancelSubscriptionContext(ctx, subscription.callData)
+
+	return cancelErr
+}
+
+// CallError error sent to js which is not an error in go, like a message of the function
+type CallError struct {
+	Data interface{}
+}
+
+func (err *CallError) Error() string {
+	return fmt.Sprintf("call failed: %v", err.Data)
+}
+
+// callError error of the call, *goapi.ValidationError and *goapi.PanicError are returned as is
+func callError(data interface{}) error {
+	if err, ok := data.(error); ok {
+		return err
+	}
+
+	if text, ok := data.(string); ok {
+		return errors.New(text)
+	}
+
+	return &CallError{Data: data}
+}
+
+type resultCallback struct {
+	result interface{}
+	err    interface{}
+	failed bool
+	once   sync.Once
+	done   chan struct{}
+}
+
+func (callback *resultCallback) OnSuccess(result interface{}) {
+	callback.once.Do(func() {
+		callback.result = result
+		close(callback.done)
+	})
+}
+
+func (callback *resultCallback) OnError(err interface{}) {
+	callback.once.Do(func() {
+		callback.err = err
+		callback.failed = true
+		close(callback.done)
+	})
+}
+
+// jsonCallData passes the call data through json, like it comes from js
+func jsonCallData(data map[string]interface{}) (map[string]interface{}, error) {
+	callData := make(map[string]interface{})
+	err := convert(data, &callData)
+	return callData, err
+}
+
+func convert(value interface{}, out interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bytes, out)
+}
@@ -0,0 +1,214 @@
+package testbridge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// watchEvents event callbacks of the "watch" subscriptions by their argument
+type watchEvents struct {
+	lock      sync.Mutex
+	callbacks map[string]goapi.EventCallback
+}
+
+func (events *watchEvents) emit(key string, data interface{}) {
+	events.lock.Lock()
+	defer events.lock.Unlock()
+
+	events.callbacks[key].OnEvent(data)
+}
+
+type watchSubscription struct{}
+
+func (watchSubscription) Cancel() {}
+
+func testRegistry() (*goapi.JsRegistry, *watchEvents) {
+	registry := goapi.NewJsRegistry()
+	registry.RegisterFunction("add", func(callData map[string]interface{}, callback goapi.JsCallback) error {
+		args, err := goapi.NewArguments(callData, []goapi.Param{{Name: "a"}, {Name: "b", Optional: true, Default: "1"}})
+		if err != nil {
+			return err
+		}
+
+		a, _ := args.Get(0)
+		b, _ := args.Get(1)
+		callback.OnSuccess(map[string]interface{}{"sum": a.(float64) + b.(float64)})
+		return nil
+	})
+	registry.RegisterFunction("fail", func(callData map[string]interface{}, callback goapi.JsCallback) error {
+		return errors.New("no luck")
+	})
+	registry.RegisterFunction("crash", func(callData map[string]interface{}, callback goapi.JsCallback) error {
+		panic("secret")
+	})
+	registry.RegisterFunction("silent", func(callData map[string]interface{}, callback goapi.JsCallback) error {
+		return nil
+	})
+
+	events := &watchEvents{callbacks: make(map[string]goapi.EventCallback)}
+	registry.RegisterSubscription("watch", func(callData map[string]interface{}, callback goapi.EventCallback) (goapi.Subscription, error) {
+		args, _ := callData["args"].([]interface{})
+		if args[0] == "" {
+			return nil, errors.New("nothing to watch")
+		}
+
+		events.lock.Lock()
+		defer events.lock.Unlock()
+
+		events.callbacks[args[0].(string)] = callback
+		return watchSubscription{}, nil
+	}, func(args []interface{}) ([]interface{}, error) {
+		return args, nil
+	})
+
+	return &registry, events
+}
+
+func TestCall(t *testing.T) {
+	registry, _ := testRegistry()
+	bridge := New(registry)
+	defer bridge.Close()
+
+	result, err := bridge.Call("add", 2, 3)
+	if err != nil {
+		t.Fatalf("Call() failed: %v", err)
+	}
+
+	if expected := map[string]interface{}{"sum": float64(5)}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Call() = %v, want %v", result, expected)
+	}
+
+	sum := struct{ Sum int }{}
+	if err := bridge.CallInto(&sum, "add", 2); err != nil || sum.Sum != 3 {
+		t.Errorf("CallInto() = %+v, %v, want the default of b", sum, err)
+	}
+
+	result, err = bridge.CallNamed("add", []interface{}{2}, map[string]interface{}{"b": 5})
+	if err != nil || result.(map[string]interface{})["sum"] != float64(7) {
+		t.Errorf("CallNamed() = %v, %v, want 7", result, err)
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	registry, _ := testRegistry()
+	bridge := New(registry)
+	bridge.Timeout = 100 * time.Millisecond
+	defer bridge.Close()
+
+	tests := []struct {
+		name    string
+		method  string
+		args    []interface{}
+		message string
+	}{
+		{"function error", "fail", nil, "no luck"},
+		{"too many arguments", "add", []interface{}{1, 2, 3}, "at most 2 arguments"},
+		{"missing argument", "add", nil, "missing argument a"},
+		{"unknown method", "missing", nil, "no such method: missing"},
+		{"no answer", "silent", nil, ErrTimeout.Error()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := bridge.Call(test.method, test.args...)
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("Call() = %v, want error with %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestCallPanic(t *testing.T) {
+	registry, _ := testRegistry()
+	registry.SetPanicHandler(goapi.PanicHandlerFunc(func(report *goapi.PanicReport) {}))
+	bridge := New(registry)
+	defer bridge.Close()
+
+	_, err := bridge.Call("crash")
+	panicErr, ok := err.(*goapi.PanicError)
+	if !ok {
+		t.Fatalf("Call() = %#v, want *goapi.PanicError", err)
+	}
+
+	if panicErr.Message != "internal error" || panicErr.Method != "crash" {
+		t.Errorf("Call() = %+v, want sanitised panic of crash", panicErr)
+	}
+}
+
+func TestSubscription(t *testing.T) {
+	registry, events := testRegistry()
+	bridge := New(registry)
+	defer bridge.Close()
+
+	subscription, err := bridge.Subscribe("watch", "a")
+	if err != nil {
+		t.Fatalf("Subscribe() failed: %v", err)
+	}
+
+	if subscription.Key() != "watch:a" {
+		t.Errorf("Key() = %s, want watch:a", subscription.Key())
+	}
+
+	events.emit("a", map[string]interface{}{"n": 1})
+	events.emit("a", map[string]interface{}{"n": 2})
+
+	collected, err := subscription.Collect(2)
+	expected := []interface{}{map[string]interface{}{"n": float64(1)}, map[string]interface{}{"n": float64(2)}}
+	if err != nil || !reflect.DeepEqual(collected, expected) {
+		t.Errorf("Collect() = %v, %v, want %v", collected, err, expected)
+	}
+
+	if err := subscription.Cancel(); err != nil {
+		t.Errorf("Cancel() failed: %v", err)
+	}
+
+	bridge.AssertNoLeaks(t)
+
+	if _, err := bridge.Subscribe("watch", ""); err == nil || err.Error() != "nothing to watch" {
+		t.Errorf("Subscribe() of nothing = %v, want the error of the subscription", err)
+	}
+}
+
+// recordingTB keeps the errors of the assertion
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, format)
+}
+
+func TestLeaks(t *testing.T) {
+	registry, _ := testRegistry()
+	bridge := New(registry)
+	defer bridge.Close()
+
+	subscription, err := bridge.Subscribe("watch", "b")
+	if err != nil {
+		t.Fatalf("Subscribe() failed: %v", err)
+	}
+
+	if leaks := bridge.Leaks(); !reflect.DeepEqual(leaks, []string{"watch:b"}) {
+		t.Errorf("Leaks() = %v, want watch:b", leaks)
+	}
+
+	tb := &recordingTB{}
+	bridge.AssertNoLeaks(tb)
+	if len(tb.errors) != 1 {
+		t.Errorf("AssertNoLeaks() with the active subscription reported %v", tb.errors)
+	}
+
+	subscription.Cancel()
+	if leaks := bridge.Leaks(); len(leaks) != 0 {
+		t.Errorf("Leaks() after Cancel() = %v", leaks)
+	}
+}
@@ -0,0 +1,258 @@
+package goapi
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gitlab.vmassive.ru/wand/rules"
+)
+
+// FieldError failed rule of an argument or a field
+type FieldError struct {
+	// Path of the field, like filter.items[0].name
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError every failed rule of the call arguments, it is sent to js as is
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (err *ValidationError) Error() string {
+	messages := make([]string, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		messages = append(messages, field.Path+" "+field.Message)
+	}
+
+	return "validation failed: " + strings.Join(messages, ", ")
+}
+
+// Validator collects failed rules of the arguments
+type Validator struct {
+	fields []FieldError
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		fields: make([]FieldError, 0),
+	}
+}
+
+// Check checks the value with the rules and fields of structs with validate tags
+func (validator *Validator) Check(path string, value interface{}, rules string) {
+	validator.check(path, reflect.ValueOf(value), rules)
+}
+
+// Err returns *ValidationError when any rule failed
+func (validator *Validator) Err() error {
+	if len(validator.fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Fields: validator.fields}
+}
+
+func (validator *Validator) check(path string, value reflect.Value, tag string) {
+	for _, rule := range rules.Parse(tag) {
+		message := checkRule(value, rule)
+		if message != "" {
+			validator.fields = append(validator.fields, FieldError{
+				Path:    path,
+				Rule:    rule.Name,
+				Param:   rule.Param,
+				Message: message,
+			})
+		}
+	}
+
+	validator.walk(path, value)
+}
+
+// walk checks fields of structs, items of slices and values of maps are walked too
+func (validator *Validator) walk(path string, value reflect.Value) {
+	value = indirect(value)
+	if !value.IsValid() {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := jsonName(field)
+			if name == "-" {
+				continue
+			}
+
+			validator.check(path+"."+name, value.Field(i), field.Tag.Get("validate"))
+		}
+
+	case reflect.Slice, reflect.Array:
+		// items without fields, like bytes of []byte, have nothing to walk
+		if !walkable(value.Type().Elem()) {
+			return
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			validator.walk(path+"["+strconv.Itoa(i)+"]", value.Index(i))
+		}
+
+	case reflect.Map:
+		if !walkable(value.Type().Elem()) {
+			return
+		}
+
+		// keys are sorted to keep the order of the errors stable
+		keys := make(map[string]reflect.Value, value.Len())
+		names := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			name := fmt.Sprint(key.Interface())
+			keys[name] = key
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			validator.walk(path+"["+strconv.Quote(name)+"]", value.MapIndex(keys[name]))
+		}
+	}
+}
+
+// walkable tells if values of the type may have fields with validate tags
+func walkable(tp reflect.Type) bool {
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+
+	switch tp.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+
+	return false
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// indirect value of pointers and interfaces, invalid value for nil
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
+
+func isEmpty(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array:
+		return value.Len() == 0
+	case reflect.Map:
+		return value.IsNil()
+	}
+
+	return false
+}
+
+// measure number value or length of the value
+func measure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true
+	}
+
+	return 0, false
+}
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// checkRule returns the message when the rule failed
+func checkRule(value reflect.Value, rule rules.Rule) string {
+	value = indirect(value)
+
+	if rule.Name == "required" {
+		if isEmpty(value) {
+			return "is required"
+		}
+
+		return ""
+	}
+
+	if !value.IsValid() {
+		return ""
+	}
+
+	switch rule.Name {
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(rule.Param, 64)
+		size, ok := measure(value)
+		if err != nil || !ok {
+			return ""
+		}
+
+		if rule.Name == "min" && size < limit {
+			return "must be at least " + rule.Param
+		}
+
+		if rule.Name == "max" && size > limit {
+			return "must be at most " + rule.Param
+		}
+
+		if rule.Name == "len" && size != limit {
+			return "must be exactly " + rule.Param
+		}
+
+	case "email":
+		if value.Kind() == reflect.String && value.Len() > 0 && !emailPattern.MatchString(value.String()) {
+			return "must be an email"
+		}
+
+	case "oneof":
+		text := fmt.Sprint(value.Interface())
+		for _, item := range strings.Fields(rule.Param) {
+			if item == text {
+				return ""
+			}
+		}
+
+		return "must be one of " + rule.Param
+	}
+
+	return ""
+}
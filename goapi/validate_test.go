@@ -0,0 +1,105 @@
+package goapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+type validatedItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type validatedFilter struct {
+	Email   string                   `json:"email" validate:"email"`
+	Status  string                   `json:"status" validate:"oneof=active blocked"`
+	Items   []validatedItem          `json:"items" validate:"max=2"`
+	Team    map[string]validatedItem `json:"team"`
+	Owner   *validatedItem           `json:"owner"`
+	Avatar  []byte                   `json:"avatar" validate:"max=4"`
+	private validatedItem
+}
+
+func validationPaths(err error) []string {
+	paths := make([]string, 0)
+	if err == nil {
+		return paths
+	}
+
+	for _, field := range err.(*ValidationError).Fields {
+		paths = append(paths, field.Path+" "+field.Rule)
+	}
+
+	return paths
+}
+
+func TestValidatorPaths(t *testing.T) {
+	filter := validatedFilter{
+		Email:  "nobody",
+		Status: "deleted",
+		Items:  []validatedItem{{Name: "a"}, {}, {}},
+		Team:   map[string]validatedItem{"b": {}, "a": {Name: "a"}, "c": {}},
+		Owner:  &validatedItem{},
+		Avatar: []byte{1, 2, 3, 4, 5},
+	}
+
+	validator := NewValidator()
+	validator.Check("filter", filter, "required")
+
+	expected := []string{
+		"filter.email email",
+		"filter.status oneof",
+		"filter.items max",
+		"filter.items[1].name required",
+		"filter.items[2].name required",
+		`filter.team["b"].name required`,
+		`filter.team["c"].name required`,
+		"filter.owner.name required",
+		"filter.avatar max",
+	}
+	if paths := validationPaths(validator.Err()); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("paths = %q, want %q", paths, expected)
+	}
+}
+
+func TestValidatorRules(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		rules string
+		fails bool
+	}{
+		{"", "required", true},
+		{"a", "required", false},
+		{[]int{}, "required", true},
+		{"абв", "len=3", false},
+		{3, "min=4", true},
+		{5, "max=4", true},
+		{"active", "oneof=active blocked", false},
+		{"", "email", false},
+		{"a@b.c", "email", false},
+		{nil, "min=1", false},
+	}
+
+	for _, test := range tests {
+		validator := NewValidator()
+		validator.Check("value", test.value, test.rules)
+		if failed := validator.Err() != nil; failed != test.fails {
+			t.Errorf("Check(%#v, %q) failed = %v, want %v", test.value, test.rules, failed, test.fails)
+		}
+	}
+}
+
+func TestValidatorSkipsBytes(t *testing.T) {
+	if walkable(reflect.TypeOf([]byte{}).Elem()) {
+		t.Errorf("bytes are walked")
+	}
+
+	if !walkable(reflect.TypeOf([]*validatedItem{}).Elem()) {
+		t.Errorf("pointers to structs are not walked")
+	}
+
+	validator := NewValidator()
+	validator.Check("picture", make([]byte, 1<<20), "required")
+	if err := validator.Err(); err != nil {
+		t.Errorf("Check() of bytes failed: %v", err)
+	}
+}
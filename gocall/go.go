@@ -1,350 +1,325 @@
 package gocall
 
 import (
-	"go/ast"
-	"html/template"
+	"bytes"
+	"go/format"
 	"io"
-	"io/ioutil"
-	"os"
-	"os/exec"
 	"path"
 	"strings"
-	"unicode"
 
 	log "github.com/sirupsen/logrus"
-	"gitlab.vmassive.ru/wand/assets"
 	"gitlab.vmassive.ru/wand/generator"
+	"gitlab.vmassive.ru/wand/rules"
 )
 
+// Function data of func.go.tmpl, pure.go.tmpl and stream.go.tmpl
 type Function struct {
-	Name         string
-	Comments     []string
-	ReturnType   string
-	Params       []Field
+	// Name of the go function
+	Name string
+	// Comments doc comment lines
+	Comments []string
+	// ReturnType type of @callback annotation
+	ReturnType string
+	// Params parameters without the callback
+	Params []Field
+	// Subscription type of @subscription annotation, nil for calls
 	Subscription *string
-	Package      string
+	// Package name of the source package
+	Package string
+	// Validate is true when any parameter is validated
+	Validate bool
+	// Results variables the results of pure function are assigned to, "_" for the ones js does not get
+	Results []string
+	// HasResult is true when pure function returns a value to js
+	HasResult bool
+	// HasError is true when the last result of pure or streaming function is an error
+	HasError bool
+	// Stream is true for streaming functions
+	Stream bool
+	// StreamChannel is true when streaming function returns <-chan, otherwise it takes goapi.Stream
+	StreamChannel bool
 }
 
+// Type go type of a parameter
 type Type struct {
-	Name       string
-	Map        bool
-	Array      bool
+	// Name go type, types of the source package are prefixed with the package
+	Name string
+	// Map is true for maps, SimpleType is the key and InnerType is the value
+	Map bool
+	// Array is true for slices and arrays of SimpleType
+	Array bool
+	// Bytes is true for byte slices, they are decoded from binary markers
+	Bytes bool
+	// SimpleType name of the type without pointer or slice
 	SimpleType string
-	Pointer    bool
-	InnerType  *Type
-	Object     bool
+	// Elem go type decoded from js value, the element of slices or the type itself
+	Elem string
+	// Pointer is true for pointers to SimpleType
+	Pointer bool
+	// InnerType value type of a map
+	InnerType *Type
+	// Object is true for exported types of the source package
+	Object bool
+	// Package name of the source package
+	Package string
 }
 
+// Field parameter of a function
 type Field struct {
-	Name       string
-	Type       string
-	Comment    []string
-	RichType   Type
-	Array      bool
+	// Name of the parameter
+	Name string
+	// Type go type, types of the source package are prefixed with the package
+	Type string
+	// Comment doc comment lines
+	Comment []string
+	// RichType parsed type
+	RichType Type
+	Array    bool
+	// SimpleType name of the type without pointer or slice
 	SimpleType string
-	Package    string
+	// Package name of the source package
+	Package string
+	// Rules validation rules, like "required,min=1"
+	Rules string
+	// Validate is true when the parameter has rules or its structure has rules
+	Validate bool
+	// Optional is true when the parameter may be missing
+	Optional bool
+	// Default json value of @default annotation
+	Default string
 }
 
 type GoCodeGenerator struct {
 	outDirectory string
 	packageName  string
+	templates    *generator.Templates
 }
 
-func New(outDirectory string, packageName string) generator.Generator {
+func New(outDirectory string, packageName string, templates *generator.Templates) generator.Generator {
 	return &GoCodeGenerator{
 		outDirectory: outDirectory,
 		packageName:  packageName,
+		templates:    templates,
 	}
 }
 
-func (generator GoCodeGenerator) CreateCode(source *generator.CodeList) error {
-	err := generator.writeCode(source)
+func (gen GoCodeGenerator) CreateCode(source *generator.CodeList, sink generator.Sink) error {
+	return gen.writeCode(source, sink)
+}
+
+func (gen GoCodeGenerator) writeMap(f io.Writer, source *generator.CodeList) error {
+	return gen.templates.Execute(f, "callmap.go.tmpl", source)
+}
+
+func (gen GoCodeGenerator) writeCode(source *generator.CodeList, sink generator.Sink) error {
+	outFile := "call.go"
+	log.Printf("createing %s", outFile)
+
+	code, err := gen.renderCode(source)
 	if err != nil {
+		log.Errorf("failed to generate %s: %v", outFile, err)
 		return err
 	}
 
-	// cmdImport := exec.Command("goimports", "-w", generator.outDirectory)
-	// cmdImport.Dir = generator.outDirectory
-	// cmdImport.Start()
-
-	if !source.Dev {
-		cmd := exec.Command("go", "fmt")
-		cmd.Dir = generator.outDirectory
-		cmd.Start()
+	err = sink.WriteFile(path.Join(gen.outDirectory, outFile), code)
+	if err != nil {
+		log.Errorf("failed to write file %s", outFile)
 	}
 
-	return nil
+	return err
 }
 
-func writeMap(f io.Writer, source *generator.CodeList) error {
-	// headBytes, err := ioutil.ReadFile("callmap.go.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return err
-	// }
+// renderCode renders call.go and formats it like go fmt does
+func (gen GoCodeGenerator) renderCode(source *generator.CodeList) ([]byte, error) {
+	buffer := bytes.Buffer{}
 
-	file, err := assets.Assets.Open("/templates/callmap.go.tmpl")
-	defer file.Close()
+	err := gen.writeHeader(&buffer, source)
 	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
+		return nil, err
 	}
 
-	headBytes, err := ioutil.ReadAll(file)
+	err = gen.writeMap(&buffer, source)
 	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
+		return nil, err
 	}
 
-	headTemplate, err := template.New("header").Parse(string(headBytes))
+	validated := source.ValidatedStructures()
+
+	err = gen.writeFunctions(&buffer, gen.packageName, source.Functions, validated)
 	if err != nil {
-		log.Errorf("failed to write head with error %v", err)
-		return err
+		return nil, err
 	}
 
-	return headTemplate.Execute(f, source)
-}
-
-func (generator GoCodeGenerator) writeCode(source *generator.CodeList) error {
-	outFile := "call.go"
-	log.Printf("createing %s", outFile)
-
-	f, err := os.Create(path.Join(generator.outDirectory, outFile))
+	err = gen.writePureFunctions(&buffer, gen.packageName, source.Pure, validated)
 	if err != nil {
-		log.Errorf("failed to create file %s", outFile)
-		return err
+		return nil, err
 	}
 
-	defer f.Close()
-	writeHeader(f, source)
-	writeMap(f, source)
-	writeFunctions(f, generator.packageName, source)
-	writePureFunctions(f, generator.packageName, source)
-	return nil
+	return format.Source(buffer.Bytes())
 }
 
-func writeFunctions(wr io.Writer, pack string, source *generator.CodeList) {
-	for _, function := range source.Functions {
-		writeFunction(wr, pack, function)
+func (gen GoCodeGenerator) writeFunctions(wr io.Writer, pack string, functions []generator.FunctionData, validated map[string]bool) error {
+	for _, function := range functions {
+		err := gen.writeFunction(wr, pack, function, validated)
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func writePureFunctions(wr io.Writer, pack string, source *generator.CodeList) {
-	for _, function := range source.Pure {
-		writePureFunction(wr, pack, function)
+func (gen GoCodeGenerator) writePureFunctions(wr io.Writer, pack string, functions []generator.FunctionData, validated map[string]bool) error {
+	for _, function := range functions {
+		err := gen.writePureFunction(wr, pack, function, validated)
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func writePureFunction(wr io.Writer, pack string, function generator.FunctionData) {
-	// b, err := ioutil.ReadFile("func.go.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return
-	// }
+func (gen GoCodeGenerator) writePureFunction(wr io.Writer, pack string, function generator.FunctionData, validated map[string]bool) error {
+	return gen.templates.Execute(wr, "pure.go.tmpl", createPureFunction(pack, function, validated))
+}
 
-	file, err := assets.Assets.Open("/templates/pure.go.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
+// createPureFunction - function with the variables of its results, the first
+// value goes to js and the trailing error rejects the call
+func createPureFunction(pack string, function generator.FunctionData, validated map[string]bool) Function {
+	data := createFunction(pack, function, validated)
+	data.HasError = function.ReturnsError()
+
+	for index := range function.Results {
+		switch {
+		case data.HasError && index == len(function.Results)-1:
+			data.Results = append(data.Results, "________err")
+		case !data.HasResult && !generator.IsError(function.Results[index].Type):
+			data.Results = append(data.Results, "________result")
+			data.HasResult = true
+		default:
+			data.Results = append(data.Results, "_")
+		}
 	}
 
-	b, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
-	}
+	return data
+}
 
-	t, err := template.New("structType").Parse(string(b))
-	if err != nil {
-		log.Errorf("failed with error %v", err)
-		return
+func (gen GoCodeGenerator) writeFunction(wr io.Writer, pack string, function generator.FunctionData, validated map[string]bool) error {
+	if function.Stream != nil {
+		return gen.templates.Execute(wr, "stream.go.tmpl", createStreamFunction(pack, function, validated))
 	}
 
-	err = t.Execute(wr, createFunction(pack, function))
-	if err != nil {
-		log.Errorf("template failed with error %v", err)
-	}
+	return gen.templates.Execute(wr, "func.go.tmpl", createFunction(pack, function, validated))
 }
 
-func writeFunction(wr io.Writer, pack string, function generator.FunctionData) {
-	// b, err := ioutil.ReadFile("func.go.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return
-	// }
+// createStreamFunction - function which sends its items to goapi.Stream, directly
+// or by reading the returned channel
+func createStreamFunction(pack string, function generator.FunctionData, validated map[string]bool) Function {
+	data := createFunction(pack, function, validated)
+	data.Stream = true
+	data.StreamChannel = function.StreamChannel
+	data.HasError = function.ReturnsError()
 
-	file, err := assets.Assets.Open("/templates/func.go.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
-	}
-
-	b, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
-	}
+	return data
+}
 
-	t, err := template.New("function").Parse(string(b))
-	if err != nil {
-		log.Errorf("failed with error %v", err)
-		return
-	}
+func createFunction(pack string, function generator.FunctionData, validated map[string]bool) Function {
+	params := createListOfFields(function.Params, pack, validated)
 
-	err = t.Execute(wr, createFunction(pack, function))
-	if err != nil {
-		log.Errorf("template failed with error %v", err)
+	validate := false
+	for _, param := range params {
+		validate = validate || param.Validate
 	}
-}
 
-func createFunction(pack string, function generator.FunctionData) Function {
 	return Function{
 		Name:         function.Name,
 		Comments:     function.Comments,
 		ReturnType:   function.ReturnType,
-		Params:       createListOfFields(function.Params, pack),
+		Params:       params,
 		Subscription: function.Subscription,
 		Package:      pack,
+		Validate:     validate,
 	}
 }
 
-func writeHeader(f io.Writer, sourceList *generator.CodeList) error {
-	// headBytes, err := ioutil.ReadFile("head.go.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return err
-	// }
-
-	file, err := assets.Assets.Open("/templates/head.go.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
-	}
-
-	headBytes, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
-	}
-
-	headTemplate, err := template.New("header").Parse(string(headBytes))
-	if err != nil {
-		log.Errorf("failed to write head with error %v", err)
-		return err
-	}
-
-	return headTemplate.Execute(f, sourceList)
+func (gen GoCodeGenerator) writeHeader(f io.Writer, sourceList *generator.CodeList) error {
+	return gen.templates.Execute(f, "head.go.tmpl", sourceList)
 }
 
-func createListOfFields(list *ast.FieldList, pack string) []Field {
-	fields := make([]Field, 0, 100)
-	for _, field := range list.List {
-
-		typeName := createType(field.Type)
-		if typeName == "" {
-			typeName = "interface{}"
-		}
-
-		richType := createRichType(field.Type)
-
-		// Skip callback type
-		if typeName == "JsCallback" || typeName == "EventCallback" {
-			continue
-		}
-
-		for _, name := range field.Names {
-			fieldInfo := Field{
-				Name:     name.Name,
-				Type:     typeName,
-				Comment:  getComments(field.Doc),
-				RichType: richType,
-				Package:  pack,
-			}
-
-			fields = append(fields, fieldInfo)
-		}
+func createListOfFields(list []generator.Field, pack string, validated map[string]bool) []Field {
+	fields := make([]Field, 0, len(list))
+	for _, field := range list {
+		fields = append(fields, Field{
+			Name:     field.Name,
+			Type:     generator.GoType(field.Type, pack),
+			Comment:  field.Comments,
+			RichType: createRichType(field.Type, pack),
+			Package:  pack,
+			Rules:    formatRules(field.Rules),
+			Validate: generator.NeedsValidation(field, validated),
+			Optional: field.Optional,
+			Default:  field.Default,
+		})
 	}
 
 	return fields
 }
 
-func getComments(commGroup *ast.CommentGroup) []string {
-	comments := make([]string, 0, 6)
-	if commGroup != nil {
-		for _, comment := range commGroup.List {
-			comments = append(comments, strings.TrimLeft(strings.TrimPrefix(comment.Text, "//"), " "))
-		}
+func createRichType(tp *generator.Type, pack string) Type {
+	richType := Type{
+		Name:       generator.GoType(tp, pack),
+		SimpleType: generator.GoType(tp, ""),
+		Elem:       generator.GoType(tp, pack),
+		Package:    pack,
 	}
 
-	return comments
-}
+	switch tp.Kind {
+	case generator.KindMap:
+		inner := createRichType(tp.Elem, pack)
+		richType.Map = true
+		richType.SimpleType = generator.GoType(tp.Key, "")
+		richType.InnerType = &inner
+
+	case generator.KindPointer:
+		richType.Pointer = true
+		richType.SimpleType = generator.GoType(tp.Elem, "")
+		richType.Object = isObject(tp.Elem)
+
+	case generator.KindSlice:
+		richType.Bytes = generator.IsBytes(tp)
+		richType.Array = !richType.Bytes
+		richType.SimpleType = generator.GoType(tp.Elem, "")
+		richType.Elem = generator.GoType(tp.Elem, pack)
+		richType.Object = isObject(tp.Elem)
+
+	default:
+		richType.Object = isObject(tp)
+	}
 
-func isObject(name string) bool {
-	return unicode.IsUpper([]rune(name)[0])
+	return richType
 }
 
-func createRichType(tp ast.Expr) Type {
-	Map := false
-	Array := false
-	SimpleType := "interface{}"
-	Pointer := false
-
-	var InnerType *Type
-
-	switch x := tp.(type) {
-	case *ast.Ident:
-		SimpleType = toTypeName(x.Name)
-
-	case *ast.MapType:
-		// return "map[" + createType(x.Key) + "]" + createType(x.Value)
-		Map = true
-		SimpleType = createType(x.Key)
-		inner := createRichType(x.Value)
-		InnerType = &inner
-
-	case *ast.StarExpr:
-		SimpleType = createType(x.X)
-		Pointer = true
-
-	case *ast.ArrayType:
-		SimpleType = createType(x.Elt)
-		Array = true
+// isObject - type is declared in the source package
+func isObject(tp *generator.Type) bool {
+	switch tp.Kind {
+	case generator.KindNamed, generator.KindEnum, generator.KindGeneric:
+		return tp.Package == ""
 	}
 
-	object := isObject(SimpleType)
-
-	return Type{
-		SimpleType: SimpleType,
-		Array:      Array,
-		Map:        Map,
-		Pointer:    Pointer,
-		InnerType:  InnerType,
-		Object:     object,
-	}
+	return false
 }
 
-func createType(tp ast.Expr) string {
-	switch x := tp.(type) {
-	case *ast.Ident:
-		return toTypeName(x.Name)
-
-	case *ast.SelectorExpr:
-		return toTypeName(x.Sel.Name)
-
-	case *ast.MapType:
-		return "map[" + createType(x.Key) + "]" + createType(x.Value)
-	case *ast.StarExpr:
-		return "*" + createType(x.X)
-	case *ast.ArrayType:
-		return "[]" + createType(x.Elt)
+// formatRules - rules in the format of validate tag
+func formatRules(list []rules.Rule) string {
+	items := make([]string, 0, len(list))
+	for _, rule := range list {
+		if rule.Param != "" {
+			items = append(items, rule.Name+"="+rule.Param)
+		} else {
+			items = append(items, rule.Name)
+		}
 	}
 
-	return ""
-}
-
-func toTypeName(name string) string {
-	return name
+	return strings.Join(items, ",")
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/generator"
+	"gitlab.vmassive.ru/wand/golden"
+)
+
+var errGolden = errors.New("golden files differ or generated code does not compile")
+
+// goldenApplication runs the generators on the fixtures of the testdata directory
+func goldenApplication(dir string, update bool, skipBuild bool) error {
+	results, err := golden.Run(golden.Options{
+		Dir:       dir,
+		Update:    update,
+		SkipBuild: skipBuild,
+		GoPath:    getGoPath(),
+	}, generateFixture)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, result := range results {
+		name := result.Fixture + " (" + result.Variant + ")"
+
+		for _, diff := range result.Diffs {
+			fmt.Print(diff)
+		}
+
+		for _, message := range result.Errors {
+			fmt.Println(message)
+		}
+
+		for _, skipped := range result.Skipped {
+			log.Warnf("%s: skipped %s", name, skipped)
+		}
+
+		if len(result.Updated) > 0 {
+			log.Printf("%s: updated %s", name, strings.Join(result.Updated, ", "))
+		}
+
+		if result.Failed() {
+			failed = true
+			log.Errorf("%s: FAIL", name)
+		} else {
+			log.Printf("%s: ok", name)
+		}
+	}
+
+	if failed {
+		return errGolden
+	}
+
+	return nil
+}
+
+// generateFixture parses the fixture source and generates the code, as wand generate does
+func generateFixture(codeList *generator.CodeList, sink generator.Sink) error {
+	err := ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	return Generate(codeList, sink)
+}
@@ -0,0 +1,421 @@
+// Package golden runs the generators on the fixture packages of a testdata
+// directory and compares the output with the expected files:
+//
+//	testdata/<fixture>/src/*.go                annotated source package
+//	testdata/<fixture>/expected/release/...    output of wand generate
+//	testdata/<fixture>/expected/dev/...        output of wand generate --dev
+//
+// Generated go code is checked with go vet in a temporary GOPATH, generated
+// js is checked with flow when it is installed.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/config"
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+// root of the generated files, names of the expected files are relative to it
+const root = "/golden"
+
+// Variants of the generated code, directories of expected
+var Variants = []string{"release", "dev"}
+
+// Generate parses the source of the code list and generates the code into the sink
+type Generate func(codeList *generator.CodeList, sink generator.Sink) error
+
+// Options of the run
+type Options struct {
+	// Dir testdata directory with the fixtures
+	Dir string
+	// Update writes generated files as expected ones instead of comparing them
+	Update bool
+	// SkipBuild skips go vet of generated go code
+	SkipBuild bool
+	// GoPath with the dependencies of generated code, like gitlab.vmassive.ru/wand/goapi
+	GoPath string
+}
+
+// Result of one variant of the fixture
+type Result struct {
+	Fixture string
+	Variant string
+	// Diffs unified diffs of the files which differ from expected ones
+	Diffs []string
+	// Updated expected files written by Update
+	Updated []string
+	// Errors of go vet and flow
+	Errors []string
+	// Skipped checks which could not run
+	Skipped []string
+}
+
+// Failed tells if the generated code differs or does not compile
+func (result Result) Failed() bool {
+	return len(result.Diffs) > 0 || len(result.Errors) > 0
+}
+
+// Fixtures names of the fixture directories
+func Fixtures(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Run runs every variant of every fixture
+func Run(options Options, generate Generate) ([]Result, error) {
+	fixtures, err := Fixtures(options.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(fixtures)*len(Variants))
+	for _, fixture := range fixtures {
+		fixtureResults, err := RunFixture(options, fixture, generate)
+		results = append(results, fixtureResults...)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// RunFixture runs every variant of the fixture
+func RunFixture(options Options, fixture string, generate Generate) ([]Result, error) {
+	results := make([]Result, 0, len(Variants))
+	for _, variant := range Variants {
+		log.Printf("golden %s (%s)", fixture, variant)
+
+		result, err := runFixture(options, fixture, variant, generate)
+		if err != nil {
+			return results, fmt.Errorf("%s (%s): %v", fixture, variant, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// CodeList code list of the fixture, outputs go to the root
+func CodeList(dir string, fixture string, dev bool) *generator.CodeList {
+	wrapper := fixture + "link"
+	configuration := &config.Configuration{
+		Source:  config.Source{Package: "golden/" + fixture},
+		Wrapper: config.Wrapper{Package: wrapper, Port: 9009},
+		Js:      config.Js{Path: path.Join(root, "js"), Mocks: true},
+		Native: config.Native{
+			Android: config.Android{Path: path.Join(root, "android"), Package: "com.golden." + fixture},
+			Ios:     config.Ios{Path: path.Join(root, "ios")},
+		},
+	}
+
+	packageName := wrapper
+	if dev {
+		packageName = "main"
+	}
+
+	return &generator.CodeList{
+		Package:       packageName,
+		Dev:           dev,
+		Port:          configuration.Wrapper.Port,
+		SourcePackage: "golden/" + fixture + "/src",
+		Config:        configuration,
+		PathMap: generator.PathMap{
+			Source: path.Join(dir, fixture, "src"),
+			Target: path.Join(root, "go"),
+			Js:     configuration.Js.Path,
+		},
+	}
+}
+
+func runFixture(options Options, fixture string, variant string, generate Generate) (Result, error) {
+	result := Result{Fixture: fixture, Variant: variant}
+
+	sink := generator.NewMemorySink()
+	err := generate(CodeList(options.Dir, fixture, variant == "dev"), sink)
+	if err != nil {
+		return result, err
+	}
+
+	files := make(map[string][]byte)
+	for name, data := range sink.Files {
+		files[strings.TrimPrefix(name, root+"/")] = data
+	}
+
+	expectedDir := path.Join(options.Dir, fixture, "expected", variant)
+	if options.Update {
+		result.Updated, err = update(expectedDir, files)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		result.Diffs, err = compare(expectedDir, files)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if options.SkipBuild {
+		result.Skipped = append(result.Skipped, "go vet")
+	} else {
+		result.Errors = append(result.Errors, vet(options, fixture, files)...)
+	}
+
+	flowErrors, err := flow(files)
+	if err != nil {
+		result.Skipped = append(result.Skipped, "flow: "+err.Error())
+	}
+	result.Errors = append(result.Errors, flowErrors...)
+
+	return result, nil
+}
+
+// expectedFiles names of the files of the expected directory
+func expectedFiles(dir string) ([]string, error) {
+	names := make([]string, 0)
+	err := filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			relative, _ := filepath.Rel(dir, name)
+			names = append(names, filepath.ToSlash(relative))
+		}
+
+		return nil
+	})
+
+	return names, err
+}
+
+func compare(dir string, files map[string][]byte) ([]string, error) {
+	expected, err := expectedFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	for _, name := range expected {
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]string, 0)
+	for _, name := range names {
+		current, err := ioutil.ReadFile(path.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if bytes.Equal(current, files[name]) {
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(current)),
+			B:        difflib.SplitLines(string(files[name])),
+			FromFile: name + " (expected)",
+			ToFile:   name + " (generated)",
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// update replaces the expected directory with generated files
+func update(dir string, files map[string][]byte) ([]string, error) {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := generator.NewDiskSink()
+	updated := make([]string, 0, len(files))
+	for name, data := range files {
+		err := sink.WriteFile(path.Join(dir, name), data)
+		if err != nil {
+			return updated, err
+		}
+
+		updated = append(updated, name)
+	}
+
+	sort.Strings(updated)
+	return updated, nil
+}
+
+// vet runs go vet on generated go code in a GOPATH with the fixture source
+func vet(options Options, fixture string, files map[string][]byte) []string {
+	goPath, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer os.RemoveAll(goPath)
+
+	source := path.Join(goPath, "src", "golden", fixture, "src")
+	err = copyDir(path.Join(options.Dir, fixture, "src"), source)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	wrapper := path.Join(goPath, "src", "golden", fixture+"link")
+	sink := generator.NewDiskSink()
+	for name, data := range files {
+		if strings.HasPrefix(name, "go/") {
+			err := sink.WriteFile(path.Join(wrapper, strings.TrimPrefix(name, "go/")), data)
+			if err != nil {
+				return []string{err.Error()}
+			}
+		}
+	}
+
+	command := exec.Command("go", "vet", "./...")
+	command.Dir = wrapper
+	command.Env = append(os.Environ(), "GO111MODULE=off", "GOPATH="+goPath+string(os.PathListSeparator)+options.GoPath)
+
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return []string{fmt.Sprintf("go vet: %v\n%s", err, output)}
+	}
+
+	return nil
+}
+
+func copyDir(from string, to string) error {
+	infos, err := ioutil.ReadDir(from)
+	if err != nil {
+		return err
+	}
+
+	sink := generator.NewDiskSink()
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path.Join(from, info.Name()))
+		if err != nil {
+			return err
+		}
+
+		err = sink.WriteFile(path.Join(to, info.Name()), data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flowConfig checks every generated file, modules of react native are stubbed
+const flowConfig = `[options]
+all=true
+
+[libs]
+stubs.js
+`
+
+const flowStubs = `declare module 'react-native' { declare module.exports: any; }
+declare module 'react' { declare module.exports: any; }
+declare module 'recompose' { declare module.exports: any; }
+declare var expect: any;
+declare var global: any;
+`
+
+// flowBinary flow of node_modules of the working directory or of PATH
+func flowBinary() (string, error) {
+	local, err := filepath.Abs(path.Join("node_modules", ".bin", "flow"))
+	if err == nil {
+		if _, err := os.Stat(local); err == nil {
+			return local, nil
+		}
+	}
+
+	return exec.LookPath("flow")
+}
+
+// flow type checks generated js, the error is returned when flow is not installed
+func flow(files map[string][]byte) ([]string, error) {
+	binary, err := flowBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "golden-flow")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	sink := generator.NewDiskSink()
+	files = copyFiles(files)
+	files["js/.flowconfig"] = []byte(flowConfig)
+	files["js/stubs.js"] = []byte(flowStubs)
+	for name, data := range files {
+		if strings.HasPrefix(name, "js/") {
+			err := sink.WriteFile(path.Join(dir, name), data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	command := exec.Command(binary, "check", path.Join(dir, "js"))
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return []string{fmt.Sprintf("flow: %v\n%s", err, output)}, nil
+	}
+
+	return nil, nil
+}
+
+func copyFiles(files map[string][]byte) map[string][]byte {
+	copied := make(map[string][]byte, len(files))
+	for name, data := range files {
+		copied[name] = data
+	}
+
+	return copied
+}
@@ -0,0 +1,660 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"gitlab.vmassive.ru/wand/goapi"
+	"gitlab.vmassive.ru/wand/goapi/remgo"
+	"golden/numbers/src"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// Registry for all calls
+var registry goapi.JsRegistry = goapi.NewJsRegistry()
+
+// Registry - the registry of the functions, for go tests with goapi/testbridge
+func Registry() *goapi.JsRegistry {
+	return &registry
+}
+
+// Use installs middlewares of calls from JS, call it at startup before the first call
+func Use(middleware ...goapi.Middleware) {
+	registry.Use(middleware...)
+}
+
+// UseSubscription installs middlewares of subscriptions and their cancellations
+func UseSubscription(middleware ...goapi.SubscriptionMiddleware) {
+	registry.UseSubscription(middleware...)
+}
+
+// SetPanicHandler sets the handler of panics in calls, subscriptions and events
+func SetPanicHandler(handler goapi.PanicHandler) {
+	registry.SetPanicHandler(handler)
+}
+
+// binaryDir directory of the files with large byte slices of the results, see SetBinaryDir
+var binaryDir string
+
+// binaryEncoder encoder of byte slices of the results
+var binaryEncoder goapi.BinaryEncoder = goapi.InlineBinary
+
+// SetBinaryDir - byte slices of the results larger than threshold go to JS as files of the directory,
+// like the cache directory of the app, instead of inline base64. JS passes files of the directory
+// to byte slice parameters, other files are not read
+func SetBinaryDir(dir string, threshold int) {
+	binaryDir = dir
+	binaryEncoder = goapi.FileBinary(dir, threshold)
+	registry.SetBinaryDir(dir)
+}
+
+// ReleaseBinary - JS read the file with byte slice of the result, it is removed
+func ReleaseBinary(uri string) {
+	if err := goapi.ReleaseBinaryFile(binaryDir, uri); err != nil {
+		log.Printf("release of binary failed: %v", err)
+	}
+}
+
+// Metrics - json snapshot of the metrics of calls and subscriptions
+func Metrics() string {
+	bytes, _ := json.Marshal(registry.Metrics().Snapshot())
+	return string(bytes)
+}
+
+type X_____xxxx struct{ Val string }
+
+func Ping____(number int) string {
+	return strconv.Itoa(number)
+}
+
+var _ = errors.New
+
+func Ping____XXX(val interface{}) string {
+	data := X_____xxxx{}
+	mapstructure.Decode(val, &val)
+	return data.Val
+}
+
+func serveHome(w http.ResponseWriter, r *http.Request) {
+	log.Println(r.URL)
+	if r.URL.Path != "/" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	http.ServeFile(w, r, "home.html")
+}
+
+func SetCors(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+
+		if (*r).Method == "OPTIONS" {
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func main() {
+	registry.SetDev(true)
+
+	hub := remgo.NewHub()
+	options := remgo.Options{
+		MaxMessageSize: 0,
+		Compression:    false,
+		MaxBatchSize:   0,
+	}
+	hub.Configure(options)
+	if fileName := os.Getenv(remgo.RecordEnv); fileName != "" {
+		recorder, err := remgo.NewRecorder(fileName)
+		if err != nil {
+			log.Fatal("Recorder: ", err)
+		}
+		defer recorder.Close()
+
+		hub.Record(recorder)
+	}
+	go hub.Run(&registry)
+
+	goPath := os.Getenv("GOPATH")
+	fs := http.FileServer(http.Dir(goPath + "/src/"))
+	handler := http.StripPrefix("/file"+goPath+"/src/", fs)
+
+	fmt.Printf("serving files in %s\n", goPath)
+
+	http.Handle("/file/", SetCors(handler))
+
+	http.Handle("/metrics", registry.Metrics())
+	http.HandleFunc("/", serveHome)
+	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		remgo.ServeWs(&registry, hub, w, r)
+	})
+	err := http.ListenAndServe("0.0.0.0:9009", nil)
+	if err != nil {
+		log.Fatal("ListenAndServe: ", err)
+	}
+}
+
+// JsCallback the interface for any callbacks
+type JsCallback interface {
+	OnSuccess(json string)
+	OnError(json string)
+}
+
+// JsEvent the interface for any events
+type JsEvent interface {
+	OnEvent(eventName string, json string)
+}
+
+type eventerSender struct {
+	event JsEvent
+}
+
+func newEventSender(event JsEvent) goapi.JsEvent {
+	return &eventerSender{
+		event: event,
+	}
+}
+
+func (eventer eventerSender) OnEvent(eventName string, data interface{}) {
+	log.Printf(" >> + << %#v", data)
+	bytes, _ := json.Marshal(goapi.EncodeBinary(data, goapi.InlineBinary))
+	eventer.event.OnEvent(eventName, string(bytes))
+}
+
+type callbackCaller struct {
+	callback JsCallback
+}
+
+func (caller callbackCaller) OnSuccess(data interface{}) {
+	log.Printf(" >> + << %#v", data)
+	bytes, _ := json.Marshal(goapi.EncodeBinary(data, binaryEncoder))
+	caller.callback.OnSuccess(string(bytes))
+}
+
+func (caller callbackCaller) OnError(data interface{}) {
+	bytes, _ := json.Marshal(data)
+	caller.callback.OnError(string(bytes))
+}
+
+func newCaller(callback JsCallback) goapi.JsCallback {
+	return &callbackCaller{
+		callback: callback,
+	}
+}
+
+// CallMethod - call from JS
+func CallMethod(callData string, callback JsCallback) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	caller := newCaller(callback)
+	registry.Call(methodCallData, caller)
+}
+
+// CallMethodSync - blocking call from JS of the function which answers before it returns,
+// the result is {"Success": value} or {"Error": error}
+func CallMethodSync(callData string) string {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	result, err := registry.CallSync(context.Background(), methodCallData)
+	if err != nil {
+		bytes, _ := json.Marshal(map[string]interface{}{"Error": err})
+		return string(bytes)
+	}
+
+	bytes, _ := json.Marshal(map[string]interface{}{"Success": goapi.EncodeBinary(result, binaryEncoder)})
+	return string(bytes)
+}
+
+// OpenStream - open stream of streaming function from JS, its items and its end are sent
+// as "stream:<streamID>" events, credits is the number of items JS is ready to read
+func OpenStream(callData string, streamID string, credits int) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	registry.OpenStream(context.Background(), streamID, methodCallData, credits, registry.NewEventStreamSink("stream:"+streamID))
+}
+
+// StreamCredit - JS is ready to read count more items of the stream
+func StreamCredit(streamID string, count int) {
+	registry.StreamCredit(streamID, count)
+}
+
+// CloseStream - JS stopped reading the stream
+func CloseStream(streamID string) {
+	registry.CloseStream(streamID)
+}
+
+func RegisterEventCallback(callback JsEvent) {
+	registry.RegisterEventCallback(newEventSender(callback))
+}
+
+func RemoveEventCallback() {
+	registry.RegisterEventCallback(nil)
+}
+
+// Subscribe - subsribe from JS
+func Subscribe(callData string) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	registry.Subscribe(methodCallData)
+}
+
+// Cancel - cancel subscription from JS
+func Cancel(callData string) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	registry.CancelSubscription(methodCallData)
+}
+
+func init() {
+
+	registry.RegisterFunction("scale", callAdapterForScale)
+	registry.RegisterFunction("sum", callAdapterForSum)
+	registry.RegisterNamedSubscription("watchReading", subscribeToWatchReading, subscriptionTypesWatchReading)
+
+	registry.RegisterFunction("average", callAdapterForAverage)
+}
+
+func callAdapterForScale(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "value", Optional: false, Default: ""},
+		{Name: "factor", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var value float32
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		value, err = func(arg interface{}) (float32, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				fl, err := strconv.ParseFloat(str, 32)
+				if err != nil {
+					return 0, errors.New("invalid data")
+				}
+				return float32(fl), nil
+			}
+
+			fl, ok := arg.(float64)
+			if ok {
+				return float32(fl), nil
+			}
+
+			return arg.(float32), nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var factor float64
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		factor, err = func(arg interface{}) (float64, error) {
+
+			var obj float64
+			err := mapstructure.Decode(arg, &obj)
+
+			return obj, err
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	numbers.Scale(value, factor, callback)
+	return nil
+}
+
+func callAdapterForSum(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "values", Optional: true, Default: ""},
+		{Name: "counts", Optional: true, Default: ""},
+		{Name: "flags", Optional: true, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var values []float32
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		values, err = func(arg interface{}) ([]float32, error) {
+			argsSlice := arg.([]interface{})
+			outArray := make([]float32, 0, len(argsSlice))
+
+			for _, element := range argsSlice {
+				item, err := func(arg interface{}) (float32, error) {
+
+					if arg == nil {
+						return 0, errors.New("wrong type")
+					}
+
+					str, ok := arg.(string)
+					if ok {
+						fl, err := strconv.ParseFloat(str, 32)
+						if err != nil {
+							return 0, errors.New("invalid data")
+						}
+						return float32(fl), nil
+					}
+
+					fl, ok := arg.(float64)
+					if ok {
+						return float32(fl), nil
+					}
+
+					return arg.(float32), nil
+
+				}(element)
+
+				if err != nil {
+					return outArray, err
+				}
+
+				outArray = append(outArray, item)
+			}
+
+			return outArray, nil
+
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var counts []int32
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		counts, err = func(arg interface{}) ([]int32, error) {
+			argsSlice := arg.([]interface{})
+			outArray := make([]int32, 0, len(argsSlice))
+
+			for _, element := range argsSlice {
+				item, err := func(arg interface{}) (int32, error) {
+
+					if arg == nil {
+						return 0, errors.New("wrong type")
+					}
+
+					str, ok := arg.(string)
+					if ok {
+						val, err := strconv.Atoi(str)
+						return int32(val), err
+					}
+
+					fl, ok := arg.(float64)
+					if ok {
+						return int32(fl), nil
+					}
+
+					return arg.(int32), nil
+
+				}(element)
+
+				if err != nil {
+					return outArray, err
+				}
+
+				outArray = append(outArray, item)
+			}
+
+			return outArray, nil
+
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var flags []bool
+	if ________arg, ________ok := ________args.Get(2); ________ok {
+		flags, err = func(arg interface{}) ([]bool, error) {
+			argsSlice := arg.([]interface{})
+			outArray := make([]bool, 0, len(argsSlice))
+
+			for _, element := range argsSlice {
+				item, err := func(arg interface{}) (bool, error) {
+
+					var obj bool
+					err := mapstructure.Decode(arg, &obj)
+
+					return obj, err
+
+				}(element)
+
+				if err != nil {
+					return outArray, err
+				}
+
+				outArray = append(outArray, item)
+			}
+
+			return outArray, nil
+
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	numbers.Sum(values, counts, flags, callback)
+	return nil
+}
+
+/**
+ * WatchReading sends readings of the sensor
+ */
+func subscribeToWatchReading(callData map[string]interface{}, event goapi.EventCallback) (goapi.Subscription, error) {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "sensor", Optional: false, Default: ""},
+		{Name: "threshold", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sensor string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		sensor, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (sensor)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var threshold float32
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		threshold, err = func(arg interface{}) (float32, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				fl, err := strconv.ParseFloat(str, 32)
+				if err != nil {
+					return 0, errors.New("invalid data")
+				}
+				return float32(fl), nil
+			}
+
+			fl, ok := arg.(float64)
+			if ok {
+				return float32(fl), nil
+			}
+
+			return arg.(float32), nil
+		}(________arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return numbers.WatchReading(sensor, threshold, event)
+}
+
+/**
+ * WatchReading sends readings of the sensor
+ */
+func subscriptionTypesWatchReading(callData map[string]interface{}) ([]interface{}, error) {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "sensor", Optional: false, Default: ""},
+		{Name: "threshold", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sensor string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		sensor, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (sensor)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var threshold float32
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		threshold, err = func(arg interface{}) (float32, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				fl, err := strconv.ParseFloat(str, 32)
+				if err != nil {
+					return 0, errors.New("invalid data")
+				}
+				return float32(fl), nil
+			}
+
+			fl, ok := arg.(float64)
+			if ok {
+				return float32(fl), nil
+			}
+
+			return arg.(float32), nil
+		}(________arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]interface{}, 0, 2)
+	if ________args.Passed(0) {
+		result = append(result, sensor)
+	}
+	if ________args.Passed(1) {
+		result = append(result, threshold)
+	}
+
+	return result, nil
+}
+
+/**
+ * Average averages the values, it answers before it returns
+ */
+func callAdapterForAverage(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "values", Optional: true, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var values []float64
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		values, err = func(arg interface{}) ([]float64, error) {
+			argsSlice := arg.([]interface{})
+			outArray := make([]float64, 0, len(argsSlice))
+
+			for _, element := range argsSlice {
+				item, err := func(arg interface{}) (float64, error) {
+
+					var obj float64
+					err := mapstructure.Decode(arg, &obj)
+
+					return obj, err
+
+				}(element)
+
+				if err != nil {
+					return outArray, err
+				}
+
+				outArray = append(outArray, item)
+			}
+
+			return outArray, nil
+
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________result, ________err := numbers.Average(values)
+	if ________err != nil {
+		return ________err
+	}
+
+	callback.OnSuccess(________result)
+	return nil
+}
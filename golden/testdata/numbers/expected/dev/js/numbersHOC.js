@@ -0,0 +1,91 @@
+/**
+ * GoCall library binding
+ * flow
+ */
+
+import React, {Component, PureComponent, type ComponentType} from 'react';
+
+import {
+  View,
+  NativeModules,
+} from 'react-native';
+
+import {
+  branch,
+  renderNothing,
+} from 'recompose';
+
+export function RenderWhenReady(fields) {
+  return function(target) {
+    return branch(
+      (props) => {
+        if (Array.isArray(fields)) {
+          return !fields.reduce((acc, val) => acc && (props[val] !== undefined), true)
+        }
+
+        return props[fields] === undefined
+      },
+      renderNothing,
+    )(target)
+  }
+}
+
+import {
+  cancelSubscriptionApiCall,
+
+  scale, 
+  sum, 
+  watchReading, 
+
+  type Reading, 
+
+} from './numbers'
+
+const emptyArray = []
+
+type ReadingProp = {
+  
+  sensor: string,
+  threshold: number,
+}
+
+export function withReading<Props: {} & ReadingProp>(
+  WrappedComponent : ComponentType<ReadingProp & { reading : Reading }>
+) : ComponentType<Props> {
+  return class ReadingDataView extends Component<Props, *> {
+    subscription = undefined 
+
+    constructor(props: Props) {
+      super(props);
+      this.state = {  reading :  undefined }
+    }
+
+    componentDidMount() {
+      
+      const { sensor, threshold} = this.props
+      
+
+      
+      
+      this.subscription = watchReading(sensor,threshold,this.onValue)
+      
+    }
+
+    onValue = (reading: Reading) => {
+      this.setState({ reading })
+    }
+
+    componentWillUnmount() {
+     cancelSubscriptionApiCall(this.subscription)
+     
+    }
+
+    render() {
+      const {  reading  } = this.state;
+
+      return <WrappedComponent
+                reading={  reading  }
+                {...this.props} />
+    }
+  }
+}
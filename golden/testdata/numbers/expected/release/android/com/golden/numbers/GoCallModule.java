@@ -0,0 +1,101 @@
+package com.golden.numbers;
+
+import com.facebook.react.bridge.Promise;
+import com.facebook.react.bridge.ReactApplicationContext;
+import com.facebook.react.bridge.ReactContextBaseJavaModule;
+import com.facebook.react.bridge.ReactMethod;
+import com.facebook.react.modules.core.DeviceEventManagerModule;
+
+import numberslink.Numberslink;
+import numberslink.JsCallback;
+import numberslink.JsEvent;
+
+/**
+ * GoCall library binding, generated by wand
+ */
+public class GoCallModule extends ReactContextBaseJavaModule {
+
+    // byte slices of the results larger than this go to JS as files of the cache directory
+    private static final long BINARY_THRESHOLD = 64 * 1024;
+
+    public GoCallModule(ReactApplicationContext reactContext) {
+        super(reactContext);
+    }
+
+    @Override
+    public String getName() {
+        return "GoCall";
+    }
+
+    @Override
+    public void initialize() {
+        super.initialize();
+
+        Numberslink.setBinaryDir(getReactApplicationContext().getCacheDir().getAbsolutePath(), BINARY_THRESHOLD);
+        Numberslink.registerEventCallback(new JsEvent() {
+            @Override
+            public void onEvent(String eventName, String json) {
+                getReactApplicationContext()
+                    .getJSModule(DeviceEventManagerModule.RCTDeviceEventEmitter.class)
+                    .emit(eventName, json);
+            }
+        });
+    }
+
+    @Override
+    public void onCatalystInstanceDestroy() {
+        Numberslink.removeEventCallback();
+        super.onCatalystInstanceDestroy();
+    }
+
+    @ReactMethod
+    public void callMethod(String callData, final Promise promise) {
+        Numberslink.callMethod(callData, new JsCallback() {
+            @Override
+            public void onSuccess(String json) {
+                promise.resolve(json);
+            }
+
+            @Override
+            public void onError(String json) {
+                promise.reject("GoCallError", json);
+            }
+        });
+    }
+
+    @ReactMethod(isBlockingSynchronousMethod = true)
+    public String callMethodSync(String callData) {
+        return Numberslink.callMethodSync(callData);
+    }
+
+    @ReactMethod
+    public void openStream(String callData, String streamID, int credits) {
+        Numberslink.openStream(callData, streamID, credits);
+    }
+
+    @ReactMethod
+    public void streamCredit(String streamID, int count) {
+        Numberslink.streamCredit(streamID, count);
+    }
+
+    @ReactMethod
+    public void closeStream(String streamID) {
+        Numberslink.closeStream(streamID);
+    }
+
+    @ReactMethod
+    public void releaseBinary(String uri) {
+        Numberslink.releaseBinary(uri);
+    }
+
+    @ReactMethod
+    public void subscribe(String callData) {
+        Numberslink.subscribe(callData);
+    }
+
+    @ReactMethod
+    public void cancel(String callData, Promise promise) {
+        Numberslink.cancel(callData);
+        promise.resolve(null);
+    }
+}
@@ -0,0 +1,12 @@
+//
+// GoCall library binding, generated by wand
+//
+
+#import <React/RCTBridgeModule.h>
+#import <React/RCTEventEmitter.h>
+
+@interface GoCall : RCTEventEmitter <RCTBridgeModule>
+
+- (void)sendGoEvent:(NSString *)eventName json:(NSString *)json;
+
+@end
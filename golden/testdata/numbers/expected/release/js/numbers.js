@@ -0,0 +1,536 @@
+/**
+ * GoCall library binding
+ * flow
+ */
+
+import {
+  NativeModules,
+  NativeEventEmitter,
+  DeviceEventEmitter,
+  EmitterSubscription,
+  Platform,
+} from 'react-native';
+
+type GoSubscription = {
+   subscription: EmitterSubscription,
+   name: string,
+   args: any[],
+   devId: number,
+};
+
+type StreamListener = {
+   onItem: (item: any) => void,
+   onDone: (error: any) => void,
+};
+
+// StreamControl of the opened stream, credit lets go send count more items
+type StreamControl = {
+   credit: (count: number) => void,
+   close: () => void,
+};
+
+
+// iOS module is an RCTEventEmitter with a single event, re-dispatch it by name
+if (Platform.OS === 'ios') {
+  const goCallEmitter = new NativeEventEmitter(NativeModules.GoCall)
+  goCallEmitter.addListener('GoCallEvent', ({ name, json }) => {
+    DeviceEventEmitter.emit(name, json)
+  })
+}
+
+let nativeStreamId = 1
+
+// openNativeStream opens the stream in native module, its items and its end come as "stream:<id>" events
+function openNativeStream(name: string, args :any[], kwargs: ?{ [name: string]: any }, credits: number, listener: StreamListener) : StreamControl {
+  const streamID = `${Date.now()}:${nativeStreamId++}`
+
+  const subscription = DeviceEventEmitter.addListener(`stream:${streamID}`, (json: string) => {
+    const response = JSON.parse(json, reviveBinary)
+    if (response.Done) {
+      subscription.remove()
+      listener.onDone(response.Error)
+    } else {
+      listener.onItem(response.Item)
+    }
+  })
+
+  const callData = JSON.stringify({
+    args,
+    kwargs,
+    method: name,
+  }, binaryReplacer)
+
+  NativeModules.GoCall.openStream(callData, streamID, credits)
+
+  return {
+    credit: (count: number) => NativeModules.GoCall.streamCredit(streamID, count),
+    close: () => {
+      subscription.remove()
+      NativeModules.GoCall.closeStream(streamID)
+    },
+  }
+}
+
+
+
+// Byte slices of go are Uint8Array, they come as markers: {$base64: "..."} inline,
+// {$binary: index} attached to binary frame of the dev server, or {$file: uri, size}
+// written to the cache directory by native module when they are large
+
+// binaryFile passes the file to go function as []byte parameter, go reads the file
+// itself, so large payloads, like photos, don't go through the bridge. The file must be
+// in the cache directory of the app, go does not read other files, nor any file in dev
+export function binaryFile(uri: string) : Uint8Array {
+   return ({ $file: uri }: any)
+}
+
+// reviveBinary is JSON.parse reviver which turns the markers into Uint8Array
+export function reviveBinary(key: string, value: any) : any {
+   if (value === null || typeof value !== 'object' || Array.isArray(value)) {
+      return value
+   }
+
+   if (typeof value.$base64 === 'string') {
+      return decodeBase64(value.$base64)
+   }
+   
+   return value
+}
+
+// parseResult parses the result of go function, byte slices written to files are
+// read before it resolves
+export async function parseResult(json: string) : Promise<any> {
+   const files = []
+   const value = JSON.parse(json, (key: string, item: any) => {
+      if (item !== null && typeof item === 'object' && typeof item.$file === 'string') {
+         const data = new Uint8Array(item.size || 0)
+         files.push(readBinaryFile(item.$file, data))
+
+         return data
+      }
+
+      return reviveBinary(key, item)
+   })
+
+   await Promise.all(files)
+   return value
+}
+
+function readBinaryFile(uri: string, data: Uint8Array) : Promise<void> {
+   return new Promise((resolve, reject) => {
+      const request = new XMLHttpRequest()
+      request.open('GET', uri)
+      request.responseType = 'arraybuffer'
+      request.onload = () => {
+         data.set(new Uint8Array(request.response))
+         NativeModules.GoCall.releaseBinary(uri)
+         resolve()
+      }
+      request.onerror = () => reject(`failed to read ${uri}`)
+      request.send()
+   })
+}
+
+// binaryReplacer is JSON.stringify replacer which turns Uint8Array into {$base64: "..."}
+function binaryReplacer(key: string, value: any) : any {
+   return value instanceof Uint8Array ? { $base64: encodeBase64(value) } : value
+}
+
+const base64Alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/'
+
+function encodeBase64(data: Uint8Array) : string {
+   let text = ''
+   for (let index = 0; index < data.length; index += 3) {
+      const chunk = (data[index] << 16) | ((data[index + 1] || 0) << 8) | (data[index + 2] || 0)
+      text += base64Alphabet[(chunk >> 18) & 63] + base64Alphabet[(chunk >> 12) & 63]
+      text += index + 1 < data.length ? base64Alphabet[(chunk >> 6) & 63] : '='
+      text += index + 2 < data.length ? base64Alphabet[chunk & 63] : '='
+   }
+
+   return text
+}
+
+function decodeBase64(text: string) : Uint8Array {
+   const clean = text.replace(/=+$/, '')
+   const data = new Uint8Array(Math.floor(clean.length * 3 / 4))
+
+   let chunk = 0
+   let bits = 0
+   let offset = 0
+   for (let index = 0; index < clean.length; index++) {
+      chunk = (chunk << 6) | base64Alphabet.indexOf(clean[index])
+      bits += 6
+      if (bits >= 8) {
+         bits -= 8
+         data[offset++] = (chunk >> bits) & 0xff
+      }
+   }
+
+   return data
+}
+
+function getName(name: string, args :any[]) : string {
+   body = args.reduce((acc:string, value: any) => {
+      if (acc == "") {
+         return value
+      }
+
+      return acc + ":" + value
+   }, "")
+
+   return `${name}:${body}`
+}
+
+type ValidationFieldError = {
+   path: string,
+   rule: string,
+   param: string,
+   message: string,
+};
+
+// ValidationError is thrown before the call when arguments break validation rules,
+// go side rejects the call with the same fields
+export class ValidationError extends Error {
+   fields: ValidationFieldError[]
+
+   constructor(fields: ValidationFieldError[]) {
+      super('validation failed: ' + fields.map(field => `${field.path} ${field.message}`).join(', '))
+      this.fields = fields
+   }
+}
+
+function isEmpty(value: any) : boolean {
+   if (value === undefined || value === null) {
+      return true
+   }
+
+   if (typeof value === 'string' || Array.isArray(value) || value instanceof Uint8Array) {
+      return value.length === 0
+   }
+
+   return false
+}
+
+// number value or length of the value, same as go validator does
+function measure(value: any) : ?number {
+   if (typeof value === 'number') {
+      return value
+   }
+
+   if (typeof value === 'string') {
+      return [...value].length
+   }
+
+   if (Array.isArray(value) || value instanceof Uint8Array) {
+      return value.length
+   }
+
+   if (typeof value === 'object') {
+      return Object.keys(value).length
+   }
+
+   return undefined
+}
+
+const emailPattern = /^[^@\s]+@[^@\s]+\.[^@\s]+$/
+
+function checkRule(value: any, [name, param]: [string, string]) : ?string {
+   if (name === 'required') {
+      return isEmpty(value) ? 'is required' : undefined
+   }
+
+   if (value === undefined || value === null) {
+      return undefined
+   }
+
+   const size = measure(value)
+
+   switch (name) {
+      case 'min':
+         return size !== undefined && size < Number(param) ? `must be at least ${param}` : undefined
+      case 'max':
+         return size !== undefined && size > Number(param) ? `must be at most ${param}` : undefined
+      case 'len':
+         return size !== undefined && size !== Number(param) ? `must be exactly ${param}` : undefined
+      case 'email':
+         return typeof value === 'string' && value.length > 0 && !emailPattern.test(value) ? 'must be an email' : undefined
+      case 'oneof':
+         return param.split(' ').filter(item => item !== '').includes(String(value)) ? undefined : `must be one of ${param}`
+   }
+
+   return undefined
+}
+
+function validateValue(errors: ValidationFieldError[], path: string, value: any, rules: [string, string][], type: ?string) {
+   rules.forEach((rule) => {
+      const message = checkRule(value, rule)
+      if (message) {
+         errors.push({ path, rule: rule[0], param: rule[1], message })
+      }
+   })
+
+   if (!type || value === undefined || value === null) {
+      return
+   }
+
+   if (Array.isArray(value)) {
+      value.forEach((item, index) => validateValue(errors, `${path}[${index}]`, item, [], type))
+      return
+   }
+
+   if (type.startsWith('{}')) {
+      const valueType = type.slice(2)
+      Object.keys(value).sort().forEach((key) => {
+         validateValue(errors, `${path}[${JSON.stringify(key)}]`, value[key], [], valueType)
+      })
+      return
+   }
+
+   const fields = validationRules[type] || {}
+   Object.keys(fields).forEach((name) => {
+      validateValue(errors, `${path}.${name}`, value[name], fields[name].rules, fields[name].type)
+   })
+}
+
+function validateArgs(args: [string, any, [string, string][], ?string][]) {
+   const errors = []
+   args.forEach(([path, value, rules, type]) => validateValue(errors, path, value, rules, type))
+
+   if (errors.length > 0) {
+      throw new ValidationError(errors)
+   }
+}
+
+export function subribeApiCall(name: string, args :any[], callback: (json : String) => void) : GoSubscription {
+   args = trimArgs(args)
+   
+   const subscriptionName = getName(name, args)
+   subscription = DeviceEventEmitter.addListener(subscriptionName, callback);
+
+   const callData = JSON.stringify({
+      args,
+      event: name,
+   }, binaryReplacer)
+
+   NativeModules.GoCall.subscribe(callData)
+   
+   return {
+      args,
+      name,
+      subscription,
+   }
+   
+}
+
+export async function cancelSubscriptionApiCall(subs : GoSubscription) : Promise<any> {
+  
+  const {name, args, subscription} = subs
+
+  const callData = JSON.stringify({
+    args,
+    event: name,
+  }, binaryReplacer)
+
+  subscription.remove()
+
+  return NativeModules.GoCall.cancel(callData)
+  
+}
+
+// trimArgs drops missing trailing arguments, go side gives them default values
+function trimArgs(args: any[]) : any[] {
+   let length = args.length
+   while (length > 0 && args[length - 1] === undefined) {
+      length--
+   }
+
+   return args.slice(0, length)
+}
+
+// runApiCall calls go function with positional args and, optionally, with
+// arguments named after go parameters
+export async function runApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {
+   
+    const callData = JSON.stringify({
+      args: trimArgs(args),
+      kwargs,
+      method: name,
+    }, binaryReplacer)
+
+    return NativeModules.GoCall.callMethod(callData)
+   
+}
+
+// runPureApiCall calls go function which answers before it returns, native module
+// calls it synchronously when the bridge can, e.g. not under remote debugger
+export async function runPureApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {
+   
+    const callData = JSON.stringify({
+      args: trimArgs(args),
+      kwargs,
+      method: name,
+    }, binaryReplacer)
+
+    if (!global.nativeCallSyncHook) {
+      return NativeModules.GoCall.callMethod(callData)
+    }
+
+    const response = JSON.parse(NativeModules.GoCall.callMethodSync(callData))
+    if (response.Error !== undefined && response.Error !== null) {
+      throw JSON.stringify(response.Error)
+    }
+
+    return JSON.stringify(response.Success)
+   
+}
+
+// streamWindow items go sends ahead of the reader, the read ones are granted back by half of the window
+const streamWindow = 16
+
+// streamApiCall opens go streaming function for every for await loop, go sends no more
+// items than the loop is ready to read and break of the loop stops the function
+export function streamApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterable<any> {
+   return {
+      [Symbol.asyncIterator]: () => createStreamIterator(name, trimArgs(args), kwargs),
+   }
+}
+
+function createStreamIterator(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterator<any> {
+   const buffer = []
+   const waiting = []
+   let finished = false
+   let failure = undefined
+   let consumed = 0
+   let control: ?StreamControl = null
+
+   const take = () => {
+      const value = buffer.shift()
+
+      consumed++
+      if (!finished && control && consumed >= streamWindow / 2) {
+         control.credit(consumed)
+         consumed = 0
+      }
+
+      return { value, done: false }
+   }
+
+   const flush = () => {
+      while (waiting.length > 0 && (buffer.length > 0 || finished)) {
+         const { resolve, reject } = waiting.shift()
+         if (buffer.length > 0) {
+            resolve(take())
+         } else if (failure !== undefined) {
+            reject(failure)
+            failure = undefined
+         } else {
+            resolve({ value: undefined, done: true })
+         }
+      }
+   }
+
+   const listener = {
+      onItem: (item: any) => {
+         buffer.push(item)
+         flush()
+      },
+      onDone: (error: any) => {
+         finished = true
+         if (error !== undefined && error !== null) {
+            failure = JSON.stringify(error)
+         }
+         flush()
+      },
+   }
+
+   
+   control = openNativeStream(name, args, kwargs, streamWindow, listener)
+   
+
+   return {
+      next: () => new Promise((resolve, reject) => {
+         waiting.push({ resolve, reject })
+         flush()
+      }),
+      // return is called by break of for await loop, go stops sending the items
+      return: () => {
+         if (!finished) {
+            finished = true
+            buffer.length = 0
+            control && control.close()
+         }
+         flush()
+
+         return Promise.resolve({ value: undefined, done: true })
+      },
+      [Symbol.asyncIterator]() {
+         return this
+      },
+   }
+}
+
+/**
+ * Scale scales the value
+ */
+export async function scale(value: number, factor: number) : Promise<number> {
+   try {
+        const jsonString = await runApiCall('scale', [value, factor])
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of scale failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * Sum sums the values
+ */
+export async function sum(values?: number[], counts?: number[], flags?: boolean[]) : Promise<number> {
+   try {
+        const jsonString = await runApiCall('sum', [], { values, counts, flags })
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of sum failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * WatchReading sends readings of the sensor
+ */
+export function watchReading(sensor: string,threshold: number,callback: (e: Reading) => void ) : GoSubscription {
+   try {
+      return subribeApiCall('watchReading', [sensor, threshold], (json: string) => { callback(JSON.parse(json, reviveBinary)) })
+   } catch(error) {
+      console.warn("Call of watchReading failed", error)
+   }
+
+   return undefined
+}
+
+
+/**
+ * Average averages the values, it answers before it returns
+ */
+export async function average(values?: number[]) : Promise<number> {
+   try {
+        const jsonString = await runPureApiCall('average', [], { values })
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of average failed", error)
+        throw error
+   }
+}
+
+/**
+ * Reading of the sensor, it has only @update
+ */
+export type Reading = {  
+    value: number,  
+    average: number,  
+    valid: boolean, 
+}
+const validationRules = {
+}
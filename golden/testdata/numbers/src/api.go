@@ -0,0 +1,51 @@
+package numbers
+
+import (
+	"errors"
+
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// Reading of the sensor, it has only @update
+// @update: WatchReading
+type Reading struct {
+	Value   float32 `json:"value"`
+	Average float64 `json:"average"`
+	Valid   bool    `json:"valid"`
+}
+
+// Scale scales the value
+// @callback: number
+func Scale(value float32, factor float64, callback goapi.JsCallback) {
+	callback.OnSuccess(float64(value) * factor)
+}
+
+// Sum sums the values
+// @callback: number
+func Sum(values []float32, counts []int32, flags []bool, callback goapi.JsCallback) {
+	callback.OnSuccess(len(values) + len(counts) + len(flags))
+}
+
+// Average averages the values, it answers before it returns
+func Average(values []float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, errors.New("no values")
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values)), nil
+}
+
+type subscription struct{}
+
+func (subscription) Cancel() {}
+
+// WatchReading sends readings of the sensor
+// @subscription: Reading
+func WatchReading(sensor string, threshold float32, event goapi.EventCallback) (goapi.Subscription, error) {
+	return subscription{}, nil
+}
@@ -0,0 +1,351 @@
+/**
+ * Jest mock of users.js, generated by wand
+ * @flow
+ *
+ *    jest.mock('../users')
+ *    import { mocks, resetMocks } from '../__mocks__/users'
+ *
+ *    mocks.getUser.mockResolve(user)
+ *    mocks.watchUser.emit(user)
+ *    mocks.getUser.expectCalledWith('42')
+ *    mocks.listUsers.mockItems([user])
+ *
+ * The state of the mocks is shared by the mocked module and this file,
+ * when both are imported in the same test.
+ */
+
+import type {
+   Filter,
+   Page,
+   User,
+} from '../users';
+
+type GoSubscription = {
+   name: string,
+   args: any[],
+   subscription: { remove: () => void },
+};
+
+type MockResult<Result> = {
+   value?: Result,
+   error?: any,
+   once: boolean,
+};
+
+export class MockCall<Args, Result> {
+   name: string
+   calls: Args[] = []
+   results: MockResult<Result>[] = []
+
+   constructor(name: string) {
+      this.name = name
+   }
+
+   // mockResolve resolves every call with the value
+   mockResolve(value: Result): this {
+      this.results = [...this.results.filter(result => result.once), { value, once: false }]
+      return this
+   }
+
+   // mockResolveOnce resolves the next call with the value
+   mockResolveOnce(value: Result): this {
+      this.results = [...this.results.filter(result => result.once), { value, once: true }, ...this.results.filter(result => !result.once)]
+      return this
+   }
+
+   // mockReject rejects every call with the error
+   mockReject(error: any): this {
+      this.results = [...this.results.filter(result => result.once), { error, once: false }]
+      return this
+   }
+
+   // mockRejectOnce rejects the next call with the error
+   mockRejectOnce(error: any): this {
+      this.results = [...this.results.filter(result => result.once), { error, once: true }, ...this.results.filter(result => !result.once)]
+      return this
+   }
+
+   invoke(args: Args): Promise<Result> {
+      this.calls = [...this.calls, args]
+
+      const result = this.results[0]
+      if (result === undefined) {
+         return Promise.reject(new Error(`${this.name} is not mocked, use mocks.${this.name}.mockResolve`))
+      }
+
+      if (result.once) {
+         this.results = this.results.slice(1)
+      }
+
+      if (result.error !== undefined) {
+         return Promise.reject(result.error)
+      }
+
+      return Promise.resolve((result.value: any))
+   }
+
+   lastCall(): ?Args {
+      return this.calls[this.calls.length - 1]
+   }
+
+   expectCalled(times?: number) {
+      if (times === undefined) {
+         expect(this.calls.length).toBeGreaterThan(0)
+      } else {
+         expect(this.calls.length).toBe(times)
+      }
+   }
+
+   expectNotCalled() {
+      expect(this.calls.length).toBe(0)
+   }
+
+   expectCalledWith(...args: Args) {
+      expect(this.calls).toContainEqual(args)
+   }
+
+   expectLastCalledWith(...args: Args) {
+      expect(this.lastCall()).toEqual(args)
+   }
+
+   reset() {
+      this.calls = []
+      this.results = []
+   }
+}
+
+type MockSubscriber<Args, Event> = {
+   args: Args,
+   callback: (e: Event) => void,
+   active: boolean,
+};
+
+export class MockSubscription<Args, Event> {
+   name: string
+   subscribers: MockSubscriber<Args, Event>[] = []
+
+   constructor(name: string) {
+      this.name = name
+   }
+
+   subscribe(args: Args, callback: (e: Event) => void): GoSubscription {
+      const subscriber = { args, callback, active: true }
+      this.subscribers = [...this.subscribers, subscriber]
+
+      return {
+         name: this.name,
+         args: (args: any),
+         subscription: { remove: () => { subscriber.active = false } },
+      }
+   }
+
+   cancel(subs: GoSubscription) {
+      subs.subscription.remove()
+   }
+
+   // emit sends the event to active subscribers, to ones with the same arguments when args are given
+   emit(event: Event, args?: Args) {
+      this.active(args).forEach(subscriber => subscriber.callback(event))
+   }
+
+   active(args?: Args): MockSubscriber<Args, Event>[] {
+      return this.subscribers.filter(subscriber => subscriber.active && (args === undefined || equal(subscriber.args, args)))
+   }
+
+   expectSubscribed(times?: number) {
+      if (times === undefined) {
+         expect(this.active().length).toBeGreaterThan(0)
+      } else {
+         expect(this.active().length).toBe(times)
+      }
+   }
+
+   expectSubscribedWith(...args: Args) {
+      expect(this.active(args).length).toBeGreaterThan(0)
+   }
+
+   reset() {
+      this.subscribers = []
+   }
+}
+
+export class MockStream<Args, Item> {
+   name: string
+   calls: Args[] = []
+   items: Item[] = []
+   error: any = undefined
+   closed: number = 0
+
+   constructor(name: string) {
+      this.name = name
+   }
+
+   // mockItems makes every stream yield the items
+   mockItems(items: Item[]): this {
+      this.items = items
+      return this
+   }
+
+   // mockReject makes every stream fail with the error after the items
+   mockReject(error: any): this {
+      this.error = error
+      return this
+   }
+
+   invoke(args: Args): AsyncIterable<Item> {
+      this.calls = [...this.calls, args]
+
+      const stream = this
+      const items = this.items
+      const error = this.error
+
+      return {
+         [Symbol.asyncIterator]() {
+            let index = 0
+            let done = false
+
+            return {
+               next: () => {
+                  if (!done && index < items.length) {
+                     return Promise.resolve({ value: items[index++], done: false })
+                  }
+
+                  if (!done && error !== undefined) {
+                     done = true
+                     return Promise.reject(error)
+                  }
+
+                  done = true
+                  return Promise.resolve({ value: undefined, done: true })
+               },
+               return: () => {
+                  if (!done) {
+                     done = true
+                     stream.closed++
+                  }
+
+                  return Promise.resolve({ value: undefined, done: true })
+               },
+            }
+         },
+      }
+   }
+
+   expectCalledWith(...args: Args) {
+      expect(this.calls).toContainEqual(args)
+   }
+
+   // expectClosed checks that the reader stopped before the end of the stream
+   expectClosed(times?: number) {
+      if (times === undefined) {
+         expect(this.closed).toBeGreaterThan(0)
+      } else {
+         expect(this.closed).toBe(times)
+      }
+   }
+
+   reset() {
+      this.calls = []
+      this.items = []
+      this.error = undefined
+      this.closed = 0
+   }
+}
+
+function equal(left: any, right: any): boolean {
+   return JSON.stringify(left) === JSON.stringify(right)
+}
+
+function createMocks() {
+   return {
+      exportUsers: new MockStream<[string], User>('exportUsers'),
+      findUsers: new MockCall<[Filter, number | void, string | void, number[] | void], User[]>('findUsers'),
+      getUser: new MockCall<[string], User>('getUser'),
+      listUsers: new MockCall<[?Page<User> | void], Page<User>>('listUsers'),
+      numbers: new MockStream<[number], number>('numbers'),
+      saveUsers: new MockCall<[{ [key: string]: User} | void], boolean>('saveUsers'),
+      setStatus: new MockCall<[string, "active" | "blocked", (0 | 1)[] | void, { [key: string]: number} | void], boolean>('setStatus'),
+      watchUser: new MockSubscription<[string], User>('watchUser'),
+      add: new MockCall<[number, number], number>('add'),
+      thumbnail: new MockCall<[Uint8Array, number], Uint8Array>('thumbnail'),
+   }
+}
+
+// mocks of the functions, shared through the global object
+export const mocks: $Call<typeof createMocks> = global.__wandMocks_users || (global.__wandMocks_users = createMocks());
+
+// resetMocks forgets calls, results and subscribers of every mock
+export function resetMocks() {
+   Object.keys(mocks).forEach(name => (mocks: any)[name].reset())
+}
+
+export class ValidationError extends Error {
+   fields: { path: string, rule: string, param: string, message: string }[]
+
+   constructor(fields: { path: string, rule: string, param: string, message: string }[]) {
+      super('validation failed')
+      this.fields = fields
+   }
+}
+
+// binaryFile is the file as it is passed to go, mocks get { $file: uri }
+export function binaryFile(uri: string) : Uint8Array {
+   return ({ $file: uri }: any)
+}
+
+export function subribeApiCall(name: string, args :any[], callback: (json : String) => void) : GoSubscription {
+   return (mocks: any)[name].subscribe(args, (event) => callback(JSON.stringify(event)))
+}
+
+export async function cancelSubscriptionApiCall(subs : GoSubscription) : Promise<any> {
+   subs.subscription.remove()
+}
+
+export function streamApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterable<any> {
+   return (mocks: any)[name].invoke(args)
+}
+
+export async function runApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {
+   return JSON.stringify(await (mocks: any)[name].invoke(args))
+}
+
+export function exportUsers(prefix: string) : AsyncIterable<User> {
+   return mocks.exportUsers.invoke([prefix])
+}
+
+export function findUsers(filter: Filter, limit?: number, sort?: string, ids?: number[]) : Promise<User[]> {
+   return mocks.findUsers.invoke([filter, limit, sort, ids])
+}
+
+export function getUser(id: string) : Promise<User> {
+   return mocks.getUser.invoke([id])
+}
+
+export function listUsers(page?: ?Page<User>) : Promise<Page<User>> {
+   return mocks.listUsers.invoke([page])
+}
+
+export function numbers(n: number) : AsyncIterable<number> {
+   return mocks.numbers.invoke([n])
+}
+
+export function saveUsers(team?: { [key: string]: User}) : Promise<boolean> {
+   return mocks.saveUsers.invoke([team])
+}
+
+export function setStatus(id: string, status: "active" | "blocked", levels?: (0 | 1)[], counters?: { [key: string]: number}) : Promise<boolean> {
+   return mocks.setStatus.invoke([id, status, levels, counters])
+}
+
+export function watchUser(id: string, callback: (e: User) => void) : GoSubscription {
+   return mocks.watchUser.subscribe([id], callback)
+}
+
+export function add(a: number, b: number) : Promise<number> {
+   return mocks.add.invoke([a, b])
+}
+
+export function thumbnail(picture: Uint8Array, size: number) : Promise<Uint8Array> {
+   return mocks.thumbnail.invoke([picture, size])
+}
+
@@ -0,0 +1,882 @@
+/**
+ * GoCall library binding
+ * flow
+ */
+
+import {
+  NativeModules,
+  NativeEventEmitter,
+  DeviceEventEmitter,
+  EmitterSubscription,
+  Platform,
+} from 'react-native';
+
+type GoSubscription = {
+   subscription: EmitterSubscription,
+   name: string,
+   args: any[],
+   devId: number,
+};
+
+type StreamListener = {
+   onItem: (item: any) => void,
+   onDone: (error: any) => void,
+};
+
+// StreamControl of the opened stream, credit lets go send count more items
+type StreamControl = {
+   credit: (count: number) => void,
+   close: () => void,
+};
+
+
+class RemoveDev {
+  server = ""
+  requestId = 1
+  call = {}
+  event = {}
+  stream = {}
+  ws: WebSocket
+  pendingList = []
+  outbox = []
+
+  constructor(server : string) {
+    this.server = server
+    this.connect()
+  }
+
+  connect = () => {
+    const ws = new WebSocket(this.server)
+    ws.binaryType = 'arraybuffer'
+    ws.onmessage = this.onMessage
+
+    this.ws = ws
+    this.ws.onopen = this.sendPending
+    ws.onclose = () => {
+       // Try to reconnect in 5 seconds
+       setTimeout(() => { this.connect()  }, 1000);
+   };
+  }
+
+  onMessage = (message: any) => {
+    if (typeof message.data !== 'string') {
+      const [json, attachments] = splitFrame(message.data)
+      this.onResponse(JSON.parse(decodeUtf8(json)), attachments)
+      return
+    }
+
+    const messages = message.data.split("\n")
+    messages.forEach((content: string) => {
+      this.onResponse(JSON.parse(content), [])
+    })
+  }
+
+  // onResponse routes the response, byte slices of binary frame are attached to it
+  onResponse = (response: any, attachments: Uint8Array[]) => {
+    if (this.stream[response.ID]) {
+      this.stream[response.ID](decodeAttachments(response, attachments))
+    } else if (this.call[response.ID]) {
+      this.call[response.ID](keepAttachments(response, attachments))
+    } else  if (response.EventName) {
+      if (this.event[response.EventName]) {
+        this.notify(this.event[response.EventName], keepAttachments(response, attachments))
+      }
+    }
+  }
+
+  notify(subscribers, response) {
+      const keys = Object.keys(subscribers)
+      keys.forEach(key => {
+         subscribers[key](response)
+      })
+
+  }
+
+  sendPending = () => {
+    this.pendingList.forEach(it => this.ws.send(it))
+  }
+
+  callMethod = (name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> => {
+    return new Promise((resolve, reject) => {
+      const callData = {
+        args,
+        kwargs,
+        method: name,
+      }
+
+      const requestID = this.requestId++
+
+      this.call[requestID] = (response: any) => {
+        if (response.Error === undefined || response.Error === null) {
+          resolve(JSON.stringify(response.Success))
+        } else {
+          reject(JSON.stringify(response.Error))
+        }
+
+        this.call[requestID] = null
+      }
+
+      this.send({id: requestID, call: callData })
+    })
+  }
+
+  // send queues the request, requests of the same tick go in one frame as a batch
+  send = (request: any) => {
+    if (this.outbox.length === 0) {
+      setTimeout(this.flush, 0)
+    }
+
+    this.outbox.push(request)
+  }
+
+  flush = () => {
+    const outbox = this.outbox
+    this.outbox = []
+
+    for (let start = 0; start < outbox.length; start += maxBatchSize) {
+      const batch = outbox.slice(start, start + maxBatchSize)
+      const body = encodeRequest(batch.length === 1 ? batch[0] : batch)
+      try  {
+        this.ws.send(body)
+      } catch (err) {
+        this.pendingList = [...this.pendingList, body]
+      }
+    }
+  }
+
+  openStream = (name: string, args :any[], kwargs: ?{ [name: string]: any }, credits: number, listener: StreamListener) : StreamControl => {
+    const requestID = this.requestId++
+
+    this.stream[requestID] = (response: any) => {
+      if (response.Done) {
+        delete this.stream[requestID]
+        listener.onDone(response.Error)
+      } else {
+        listener.onItem(response.Item)
+      }
+    }
+
+    this.send({id: requestID, stream: { args, kwargs, method: name, credits }})
+
+    return {
+      credit: (count: number) => {
+        this.send({id: this.requestId++, credit: { stream: requestID, count }})
+      },
+      close: () => {
+        delete this.stream[requestID]
+        this.send({id: this.requestId++, close: { stream: requestID }})
+      },
+    }
+  }
+
+  cancel = (name: string, args :any[], eventName, requestId) : GoSubscriptions => {
+    const callData = {
+      args,
+      event: name,
+    }
+
+    delete this.event[eventName][requestId]
+
+    this.send({id: this.requestId, cancel: callData })
+
+    return { name, name, devId: this.requestId }
+  }
+
+  subscribe = (name: string, args :any[], eventName: string, callback: (json : String) => void) : GoSubscriptions => {
+    const callData = {
+      args,
+      event: name,
+    }
+
+    const requestID = this.requestId++
+
+    if (!this.event[eventName]) {
+      this.event[eventName] = {}
+    }
+
+    this.event[eventName][requestID] = (response: any) => {
+      if (response.Body) {
+        callback(JSON.stringify(response.Body))
+      }
+    }
+
+    this.send({id: requestID, subscribe: callData })
+
+    return { args, name, eventName, devId: requestID }
+  }
+}
+
+// maxBatchSize requests in one frame, the limit of the dev server
+const maxBatchSize = 64
+
+const devCall = new RemoveDev("ws://localhost:9009/ws");
+
+// encodeRequest json of the request, or binary frame when it has byte arrays,
+// the frame is uint32 length and bytes of the json and of every byte array
+function encodeRequest(request: any) : string | ArrayBuffer {
+  const attachments = []
+  const json = JSON.stringify(request, (key: string, value: any) => {
+    if (value instanceof Uint8Array) {
+      attachments.push(value)
+      return { $binary: attachments.length - 1 }
+    }
+
+    return value
+  })
+
+  if (attachments.length === 0) {
+    return json
+  }
+
+  const chunks = [encodeUtf8(json), ...attachments]
+  const frame = new Uint8Array(chunks.reduce((size, chunk) => size + 4 + chunk.length, 0))
+  const view = new DataView(frame.buffer)
+
+  let offset = 0
+  chunks.forEach((chunk) => {
+    view.setUint32(offset, chunk.length)
+    frame.set(chunk, offset + 4)
+    offset += 4 + chunk.length
+  })
+
+  return frame.buffer
+}
+
+// splitFrame json and byte arrays of binary frame
+function splitFrame(buffer: ArrayBuffer) : [Uint8Array, Uint8Array[]] {
+  const view = new DataView(buffer)
+  const chunks = []
+
+  let offset = 0
+  while (offset + 4 <= buffer.byteLength) {
+    const length = view.getUint32(offset)
+    chunks.push(new Uint8Array(buffer, offset + 4, length))
+    offset += 4 + length
+  }
+
+  return [chunks[0], chunks.slice(1)]
+}
+
+const binaryAttachments = {}
+let nextAttachment = 1
+
+// keepAttachments replaces {$binary: index} markers with keys of the kept byte arrays, the
+// response goes through JSON string and reviveBinary takes the byte arrays back
+function keepAttachments(response: any, attachments: Uint8Array[]) : any {
+  return mapAttachments(response, (index: number) => {
+    const key = nextAttachment++
+    binaryAttachments[key] = attachments[index]
+
+    return { $attachment: key }
+  })
+}
+
+// decodeAttachments replaces the markers with byte arrays
+function decodeAttachments(response: any, attachments: Uint8Array[]) : any {
+  return mapAttachments(response, (index: number) => attachments[index])
+}
+
+function mapAttachments(value: any, convert: (index: number) => any) : any {
+  if (value === null || typeof value !== 'object') {
+    return value
+  }
+
+  if (Array.isArray(value)) {
+    return value.map(item => mapAttachments(item, convert))
+  }
+
+  if (typeof value.$binary === 'number') {
+    return convert(value.$binary)
+  }
+
+  const result = {}
+  Object.keys(value).forEach((key) => {
+    result[key] = mapAttachments(value[key], convert)
+  })
+
+  return result
+}
+
+function encodeUtf8(text: string) : Uint8Array {
+  const bytes = []
+  for (let index = 0; index < text.length; index++) {
+    let code = text.charCodeAt(index)
+    if (code >= 0xd800 && code < 0xdc00 && index + 1 < text.length) {
+      code = 0x10000 + ((code - 0xd800) << 10) + (text.charCodeAt(++index) - 0xdc00)
+    }
+
+    if (code < 0x80) {
+      bytes.push(code)
+    } else if (code < 0x800) {
+      bytes.push(0xc0 | (code >> 6), 0x80 | (code & 0x3f))
+    } else if (code < 0x10000) {
+      bytes.push(0xe0 | (code >> 12), 0x80 | ((code >> 6) & 0x3f), 0x80 | (code & 0x3f))
+    } else {
+      bytes.push(0xf0 | (code >> 18), 0x80 | ((code >> 12) & 0x3f), 0x80 | ((code >> 6) & 0x3f), 0x80 | (code & 0x3f))
+    }
+  }
+
+  return new Uint8Array(bytes)
+}
+
+function decodeUtf8(bytes: Uint8Array) : string {
+  let text = ''
+  for (let index = 0; index < bytes.length;) {
+    const byte = bytes[index++]
+    let code = byte
+    if (byte >= 0xf0) {
+      code = ((byte & 0x07) << 18) | ((bytes[index++] & 0x3f) << 12) | ((bytes[index++] & 0x3f) << 6) | (bytes[index++] & 0x3f)
+    } else if (byte >= 0xe0) {
+      code = ((byte & 0x0f) << 12) | ((bytes[index++] & 0x3f) << 6) | (bytes[index++] & 0x3f)
+    } else if (byte >= 0xc0) {
+      code = ((byte & 0x1f) << 6) | (bytes[index++] & 0x3f)
+    }
+
+    text += String.fromCodePoint(code)
+  }
+
+  return text
+}
+
+
+
+// Byte slices of go are Uint8Array, they come as markers: {$base64: "..."} inline,
+// {$binary: index} attached to binary frame of the dev server, or {$file: uri, size}
+// written to the cache directory by native module when they are large
+
+// binaryFile passes the file to go function as []byte parameter, go reads the file
+// itself, so large payloads, like photos, don't go through the bridge. The file must be
+// in the cache directory of the app, go does not read other files, nor any file in dev
+export function binaryFile(uri: string) : Uint8Array {
+   return ({ $file: uri }: any)
+}
+
+// reviveBinary is JSON.parse reviver which turns the markers into Uint8Array
+export function reviveBinary(key: string, value: any) : any {
+   if (value === null || typeof value !== 'object' || Array.isArray(value)) {
+      return value
+   }
+
+   if (typeof value.$base64 === 'string') {
+      return decodeBase64(value.$base64)
+   }
+   
+   if (value.$attachment !== undefined) {
+      const data = binaryAttachments[value.$attachment]
+      delete binaryAttachments[value.$attachment]
+
+      return data
+   }
+   
+   return value
+}
+
+// parseResult parses the result of go function, byte slices written to files are
+// read before it resolves
+export async function parseResult(json: string) : Promise<any> {
+   const files = []
+   const value = JSON.parse(json, (key: string, item: any) => {
+      if (item !== null && typeof item === 'object' && typeof item.$file === 'string') {
+         const data = new Uint8Array(item.size || 0)
+         files.push(readBinaryFile(item.$file, data))
+
+         return data
+      }
+
+      return reviveBinary(key, item)
+   })
+
+   await Promise.all(files)
+   return value
+}
+
+function readBinaryFile(uri: string, data: Uint8Array) : Promise<void> {
+   return new Promise((resolve, reject) => {
+      const request = new XMLHttpRequest()
+      request.open('GET', uri)
+      request.responseType = 'arraybuffer'
+      request.onload = () => {
+         data.set(new Uint8Array(request.response))
+         resolve()
+      }
+      request.onerror = () => reject(`failed to read ${uri}`)
+      request.send()
+   })
+}
+
+// binaryReplacer is JSON.stringify replacer which turns Uint8Array into {$base64: "..."}
+function binaryReplacer(key: string, value: any) : any {
+   return value instanceof Uint8Array ? { $base64: encodeBase64(value) } : value
+}
+
+const base64Alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/'
+
+function encodeBase64(data: Uint8Array) : string {
+   let text = ''
+   for (let index = 0; index < data.length; index += 3) {
+      const chunk = (data[index] << 16) | ((data[index + 1] || 0) << 8) | (data[index + 2] || 0)
+      text += base64Alphabet[(chunk >> 18) & 63] + base64Alphabet[(chunk >> 12) & 63]
+      text += index + 1 < data.length ? base64Alphabet[(chunk >> 6) & 63] : '='
+      text += index + 2 < data.length ? base64Alphabet[chunk & 63] : '='
+   }
+
+   return text
+}
+
+function decodeBase64(text: string) : Uint8Array {
+   const clean = text.replace(/=+$/, '')
+   const data = new Uint8Array(Math.floor(clean.length * 3 / 4))
+
+   let chunk = 0
+   let bits = 0
+   let offset = 0
+   for (let index = 0; index < clean.length; index++) {
+      chunk = (chunk << 6) | base64Alphabet.indexOf(clean[index])
+      bits += 6
+      if (bits >= 8) {
+         bits -= 8
+         data[offset++] = (chunk >> bits) & 0xff
+      }
+   }
+
+   return data
+}
+
+function getName(name: string, args :any[]) : string {
+   body = args.reduce((acc:string, value: any) => {
+      if (acc == "") {
+         return value
+      }
+
+      return acc + ":" + value
+   }, "")
+
+   return `${name}:${body}`
+}
+
+type ValidationFieldError = {
+   path: string,
+   rule: string,
+   param: string,
+   message: string,
+};
+
+// ValidationError is thrown before the call when arguments break validation rules,
+// go side rejects the call with the same fields
+export class ValidationError extends Error {
+   fields: ValidationFieldError[]
+
+   constructor(fields: ValidationFieldError[]) {
+      super('validation failed: ' + fields.map(field => `${field.path} ${field.message}`).join(', '))
+      this.fields = fields
+   }
+}
+
+function isEmpty(value: any) : boolean {
+   if (value === undefined || value === null) {
+      return true
+   }
+
+   if (typeof value === 'string' || Array.isArray(value) || value instanceof Uint8Array) {
+      return value.length === 0
+   }
+
+   return false
+}
+
+// number value or length of the value, same as go validator does
+function measure(value: any) : ?number {
+   if (typeof value === 'number') {
+      return value
+   }
+
+   if (typeof value === 'string') {
+      return [...value].length
+   }
+
+   if (Array.isArray(value) || value instanceof Uint8Array) {
+      return value.length
+   }
+
+   if (typeof value === 'object') {
+      return Object.keys(value).length
+   }
+
+   return undefined
+}
+
+const emailPattern = /^[^@\s]+@[^@\s]+\.[^@\s]+$/
+
+function checkRule(value: any, [name, param]: [string, string]) : ?string {
+   if (name === 'required') {
+      return isEmpty(value) ? 'is required' : undefined
+   }
+
+   if (value === undefined || value === null) {
+      return undefined
+   }
+
+   const size = measure(value)
+
+   switch (name) {
+      case 'min':
+         return size !== undefined && size < Number(param) ? `must be at least ${param}` : undefined
+      case 'max':
+         return size !== undefined && size > Number(param) ? `must be at most ${param}` : undefined
+      case 'len':
+         return size !== undefined && size !== Number(param) ? `must be exactly ${param}` : undefined
+      case 'email':
+         return typeof value === 'string' && value.length > 0 && !emailPattern.test(value) ? 'must be an email' : undefined
+      case 'oneof':
+         return param.split(' ').filter(item => item !== '').includes(String(value)) ? undefined : `must be one of ${param}`
+   }
+
+   return undefined
+}
+
+function validateValue(errors: ValidationFieldError[], path: string, value: any, rules: [string, string][], type: ?string) {
+   rules.forEach((rule) => {
+      const message = checkRule(value, rule)
+      if (message) {
+         errors.push({ path, rule: rule[0], param: rule[1], message })
+      }
+   })
+
+   if (!type || value === undefined || value === null) {
+      return
+   }
+
+   if (Array.isArray(value)) {
+      value.forEach((item, index) => validateValue(errors, `${path}[${index}]`, item, [], type))
+      return
+   }
+
+   if (type.startsWith('{}')) {
+      const valueType = type.slice(2)
+      Object.keys(value).sort().forEach((key) => {
+         validateValue(errors, `${path}[${JSON.stringify(key)}]`, value[key], [], valueType)
+      })
+      return
+   }
+
+   const fields = validationRules[type] || {}
+   Object.keys(fields).forEach((name) => {
+      validateValue(errors, `${path}.${name}`, value[name], fields[name].rules, fields[name].type)
+   })
+}
+
+function validateArgs(args: [string, any, [string, string][], ?string][]) {
+   const errors = []
+   args.forEach(([path, value, rules, type]) => validateValue(errors, path, value, rules, type))
+
+   if (errors.length > 0) {
+      throw new ValidationError(errors)
+   }
+}
+
+export function subribeApiCall(name: string, args :any[], callback: (json : String) => void) : GoSubscription {
+   args = trimArgs(args)
+   
+   const subscriptionName = getName(name, args)
+   return devCall.subscribe(name, args, subscriptionName, callback)
+   
+}
+
+export async function cancelSubscriptionApiCall(subs : GoSubscription) : Promise<any> {
+   
+  const {name, args, requestId, eventName, subscription} = subs
+  return devCall.cancel(name, args, eventName, requestId)
+
+  
+}
+
+// trimArgs drops missing trailing arguments, go side gives them default values
+function trimArgs(args: any[]) : any[] {
+   let length = args.length
+   while (length > 0 && args[length - 1] === undefined) {
+      length--
+   }
+
+   return args.slice(0, length)
+}
+
+// runApiCall calls go function with positional args and, optionally, with
+// arguments named after go parameters
+export async function runApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {
+    
+    return devCall.callMethod(name, trimArgs(args), kwargs)
+   
+}
+
+// runPureApiCall calls go function which answers before it returns, native module
+// calls it synchronously when the bridge can, e.g. not under remote debugger
+export async function runPureApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : Promise<any> {
+   
+    return devCall.callMethod(name, trimArgs(args), kwargs)
+   
+}
+
+// streamWindow items go sends ahead of the reader, the read ones are granted back by half of the window
+const streamWindow = 16
+
+// streamApiCall opens go streaming function for every for await loop, go sends no more
+// items than the loop is ready to read and break of the loop stops the function
+export function streamApiCall(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterable<any> {
+   return {
+      [Symbol.asyncIterator]: () => createStreamIterator(name, trimArgs(args), kwargs),
+   }
+}
+
+function createStreamIterator(name: string, args :any[], kwargs: ?{ [name: string]: any }) : AsyncIterator<any> {
+   const buffer = []
+   const waiting = []
+   let finished = false
+   let failure = undefined
+   let consumed = 0
+   let control: ?StreamControl = null
+
+   const take = () => {
+      const value = buffer.shift()
+
+      consumed++
+      if (!finished && control && consumed >= streamWindow / 2) {
+         control.credit(consumed)
+         consumed = 0
+      }
+
+      return { value, done: false }
+   }
+
+   const flush = () => {
+      while (waiting.length > 0 && (buffer.length > 0 || finished)) {
+         const { resolve, reject } = waiting.shift()
+         if (buffer.length > 0) {
+            resolve(take())
+         } else if (failure !== undefined) {
+            reject(failure)
+            failure = undefined
+         } else {
+            resolve({ value: undefined, done: true })
+         }
+      }
+   }
+
+   const listener = {
+      onItem: (item: any) => {
+         buffer.push(item)
+         flush()
+      },
+      onDone: (error: any) => {
+         finished = true
+         if (error !== undefined && error !== null) {
+            failure = JSON.stringify(error)
+         }
+         flush()
+      },
+   }
+
+   
+   control = devCall.openStream(name, args, kwargs, streamWindow, listener)
+   
+
+   return {
+      next: () => new Promise((resolve, reject) => {
+         waiting.push({ resolve, reject })
+         flush()
+      }),
+      // return is called by break of for await loop, go stops sending the items
+      return: () => {
+         if (!finished) {
+            finished = true
+            buffer.length = 0
+            control && control.close()
+         }
+         flush()
+
+         return Promise.resolve({ value: undefined, done: true })
+      },
+      [Symbol.asyncIterator]() {
+         return this
+      },
+   }
+}
+
+/**
+ * ExportUsers sends the users with the name prefix
+ */
+export function exportUsers(prefix: string) : AsyncIterable<User> {
+   return streamApiCall('exportUsers', [prefix])
+}
+
+
+/**
+ * FindUsers searches users
+ */
+export async function findUsers(filter: Filter, limit?: number, sort?: string, ids?: number[]) : Promise<User[]> {
+   validateArgs([
+      ["filter", filter, [], "Filter"],
+      ["sort", sort, [["oneof", "name email"]], null],
+   ])
+   try {
+        const jsonString = await runApiCall('findUsers', [filter], { limit, sort, ids })
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of findUsers failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * GetUser returns the user by id
+ */
+export async function getUser(id: string) : Promise<User> {
+   validateArgs([
+      ["id", id, [["required", ""], ["min", "3"]], null],
+   ])
+   try {
+        const jsonString = await runApiCall('getUser', [id])
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of getUser failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * ListUsers returns the next page
+ */
+export async function listUsers(page?: ?Page<User>) : Promise<Page<User>> {
+   try {
+        const jsonString = await runApiCall('listUsers', [], { page })
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of listUsers failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * Numbers sends numbers from 1 to n
+ */
+export function numbers(n: number) : AsyncIterable<number> {
+   return streamApiCall('numbers', [n])
+}
+
+
+/**
+ * SaveUsers stores the users by their keys
+ */
+export async function saveUsers(team?: { [key: string]: User}) : Promise<boolean> {
+   validateArgs([
+      ["team", team, [], "{}User"],
+   ])
+   try {
+        const jsonString = await runApiCall('saveUsers', [], { team })
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of saveUsers failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * SetStatus changes the status of the user
+ */
+export async function setStatus(id: string, status: "active" | "blocked", levels?: (0 | 1)[], counters?: { [key: string]: number}) : Promise<boolean> {
+   try {
+        const jsonString = await runApiCall('setStatus', [id, status], { levels, counters })
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of setStatus failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * WatchUser sends the user when it changes
+ */
+export function watchUser(id: string,callback: (e: User) => void ) : GoSubscription {
+   try {
+      return subribeApiCall('watchUser', [id], (json: string) => { callback(JSON.parse(json, reviveBinary)) })
+   } catch(error) {
+      console.warn("Call of watchUser failed", error)
+   }
+
+   return undefined
+}
+
+
+/**
+ * Add adds numbers
+ */
+export async function add(a: number, b: number) : Promise<number> {
+   try {
+        const jsonString = await runPureApiCall('add', [a, b])
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of add failed", error)
+        throw error
+   }
+}
+
+
+/**
+ * Thumbnail scales the picture down
+ */
+export async function thumbnail(picture: Uint8Array, size: number) : Promise<Uint8Array> {
+   validateArgs([
+      ["picture", picture, [["required", ""]], null],
+   ])
+   try {
+        const jsonString = await runPureApiCall('thumbnail', [picture, size])
+        return await parseResult(jsonString)
+   } catch(error) {
+        console.warn("Call of thumbnail failed", error)
+        throw error
+   }
+}
+
+/**
+ * Filter of FindUsers
+ */
+export type Filter = {  
+    query: string,  
+    owners: User[],  
+    email: string, 
+}
+/**
+ * Page of items
+ */
+export type Page<T> = {  
+    items: T[],  
+    next?: string, 
+}
+/**
+ * User of the application
+ */
+export type User = {  
+    // Name of the user 
+    name: string,  
+    age: number,  
+    tags?: string[],  
+    friend?: ?User,  
+    status: "active" | "blocked",  
+    avatar?: Uint8Array, 
+}
+const validationRules = {
+  Filter: {
+    "query": { rules: [["required", ""], ["min", "2"]], type: null },
+    "owners": { rules: [], type: "User" },
+    "email": { rules: [["email", ""]], type: null },
+  },
+  User: {
+    "name": { rules: [["required", ""]], type: null },
+    "age": { rules: [["min", "0"], ["max", "150"]], type: null },
+    "friend": { rules: [], type: "User" },
+  },
+}
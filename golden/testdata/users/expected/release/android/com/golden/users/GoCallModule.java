@@ -0,0 +1,101 @@
+package com.golden.users;
+
+import com.facebook.react.bridge.Promise;
+import com.facebook.react.bridge.ReactApplicationContext;
+import com.facebook.react.bridge.ReactContextBaseJavaModule;
+import com.facebook.react.bridge.ReactMethod;
+import com.facebook.react.modules.core.DeviceEventManagerModule;
+
+import userslink.Userslink;
+import userslink.JsCallback;
+import userslink.JsEvent;
+
+/**
+ * GoCall library binding, generated by wand
+ */
+public class GoCallModule extends ReactContextBaseJavaModule {
+
+    // byte slices of the results larger than this go to JS as files of the cache directory
+    private static final long BINARY_THRESHOLD = 64 * 1024;
+
+    public GoCallModule(ReactApplicationContext reactContext) {
+        super(reactContext);
+    }
+
+    @Override
+    public String getName() {
+        return "GoCall";
+    }
+
+    @Override
+    public void initialize() {
+        super.initialize();
+
+        Userslink.setBinaryDir(getReactApplicationContext().getCacheDir().getAbsolutePath(), BINARY_THRESHOLD);
+        Userslink.registerEventCallback(new JsEvent() {
+            @Override
+            public void onEvent(String eventName, String json) {
+                getReactApplicationContext()
+                    .getJSModule(DeviceEventManagerModule.RCTDeviceEventEmitter.class)
+                    .emit(eventName, json);
+            }
+        });
+    }
+
+    @Override
+    public void onCatalystInstanceDestroy() {
+        Userslink.removeEventCallback();
+        super.onCatalystInstanceDestroy();
+    }
+
+    @ReactMethod
+    public void callMethod(String callData, final Promise promise) {
+        Userslink.callMethod(callData, new JsCallback() {
+            @Override
+            public void onSuccess(String json) {
+                promise.resolve(json);
+            }
+
+            @Override
+            public void onError(String json) {
+                promise.reject("GoCallError", json);
+            }
+        });
+    }
+
+    @ReactMethod(isBlockingSynchronousMethod = true)
+    public String callMethodSync(String callData) {
+        return Userslink.callMethodSync(callData);
+    }
+
+    @ReactMethod
+    public void openStream(String callData, String streamID, int credits) {
+        Userslink.openStream(callData, streamID, credits);
+    }
+
+    @ReactMethod
+    public void streamCredit(String streamID, int count) {
+        Userslink.streamCredit(streamID, count);
+    }
+
+    @ReactMethod
+    public void closeStream(String streamID) {
+        Userslink.closeStream(streamID);
+    }
+
+    @ReactMethod
+    public void releaseBinary(String uri) {
+        Userslink.releaseBinary(uri);
+    }
+
+    @ReactMethod
+    public void subscribe(String callData) {
+        Userslink.subscribe(callData);
+    }
+
+    @ReactMethod
+    public void cancel(String callData, Promise promise) {
+        Userslink.cancel(callData);
+        promise.resolve(null);
+    }
+}
@@ -0,0 +1,836 @@
+package userslink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/mitchellh/mapstructure"
+	"gitlab.vmassive.ru/wand/goapi"
+	"golden/users/src"
+)
+
+// Registry for all calls
+var registry goapi.JsRegistry = goapi.NewJsRegistry()
+
+// Registry - the registry of the functions, for go tests with goapi/testbridge
+func Registry() *goapi.JsRegistry {
+	return &registry
+}
+
+// Use installs middlewares of calls from JS, call it at startup before the first call
+func Use(middleware ...goapi.Middleware) {
+	registry.Use(middleware...)
+}
+
+// UseSubscription installs middlewares of subscriptions and their cancellations
+func UseSubscription(middleware ...goapi.SubscriptionMiddleware) {
+	registry.UseSubscription(middleware...)
+}
+
+// SetPanicHandler sets the handler of panics in calls, subscriptions and events
+func SetPanicHandler(handler goapi.PanicHandler) {
+	registry.SetPanicHandler(handler)
+}
+
+// binaryDir directory of the files with large byte slices of the results, see SetBinaryDir
+var binaryDir string
+
+// binaryEncoder encoder of byte slices of the results
+var binaryEncoder goapi.BinaryEncoder = goapi.InlineBinary
+
+// SetBinaryDir - byte slices of the results larger than threshold go to JS as files of the directory,
+// like the cache directory of the app, instead of inline base64. JS passes files of the directory
+// to byte slice parameters, other files are not read
+func SetBinaryDir(dir string, threshold int) {
+	binaryDir = dir
+	binaryEncoder = goapi.FileBinary(dir, threshold)
+	registry.SetBinaryDir(dir)
+}
+
+// ReleaseBinary - JS read the file with byte slice of the result, it is removed
+func ReleaseBinary(uri string) {
+	if err := goapi.ReleaseBinaryFile(binaryDir, uri); err != nil {
+		log.Printf("release of binary failed: %v", err)
+	}
+}
+
+// Metrics - json snapshot of the metrics of calls and subscriptions
+func Metrics() string {
+	bytes, _ := json.Marshal(registry.Metrics().Snapshot())
+	return string(bytes)
+}
+
+type X_____xxxx struct{ Val string }
+
+func Ping____(number int) string {
+	return strconv.Itoa(number)
+}
+
+var _ = errors.New
+
+func Ping____XXX(val interface{}) string {
+	data := X_____xxxx{}
+	mapstructure.Decode(val, &val)
+	return data.Val
+}
+
+// JsCallback the interface for any callbacks
+type JsCallback interface {
+	OnSuccess(json string)
+	OnError(json string)
+}
+
+// JsEvent the interface for any events
+type JsEvent interface {
+	OnEvent(eventName string, json string)
+}
+
+type eventerSender struct {
+	event JsEvent
+}
+
+func newEventSender(event JsEvent) goapi.JsEvent {
+	return &eventerSender{
+		event: event,
+	}
+}
+
+func (eventer eventerSender) OnEvent(eventName string, data interface{}) {
+	log.Printf(" >> + << %#v", data)
+	bytes, _ := json.Marshal(goapi.EncodeBinary(data, goapi.InlineBinary))
+	eventer.event.OnEvent(eventName, string(bytes))
+}
+
+type callbackCaller struct {
+	callback JsCallback
+}
+
+func (caller callbackCaller) OnSuccess(data interface{}) {
+	log.Printf(" >> + << %#v", data)
+	bytes, _ := json.Marshal(goapi.EncodeBinary(data, binaryEncoder))
+	caller.callback.OnSuccess(string(bytes))
+}
+
+func (caller callbackCaller) OnError(data interface{}) {
+	bytes, _ := json.Marshal(data)
+	caller.callback.OnError(string(bytes))
+}
+
+func newCaller(callback JsCallback) goapi.JsCallback {
+	return &callbackCaller{
+		callback: callback,
+	}
+}
+
+// CallMethod - call from JS
+func CallMethod(callData string, callback JsCallback) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	caller := newCaller(callback)
+	registry.Call(methodCallData, caller)
+}
+
+// CallMethodSync - blocking call from JS of the function which answers before it returns,
+// the result is {"Success": value} or {"Error": error}
+func CallMethodSync(callData string) string {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	result, err := registry.CallSync(context.Background(), methodCallData)
+	if err != nil {
+		bytes, _ := json.Marshal(map[string]interface{}{"Error": err})
+		return string(bytes)
+	}
+
+	bytes, _ := json.Marshal(map[string]interface{}{"Success": goapi.EncodeBinary(result, binaryEncoder)})
+	return string(bytes)
+}
+
+// OpenStream - open stream of streaming function from JS, its items and its end are sent
+// as "stream:<streamID>" events, credits is the number of items JS is ready to read
+func OpenStream(callData string, streamID string, credits int) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	registry.OpenStream(context.Background(), streamID, methodCallData, credits, registry.NewEventStreamSink("stream:"+streamID))
+}
+
+// StreamCredit - JS is ready to read count more items of the stream
+func StreamCredit(streamID string, count int) {
+	registry.StreamCredit(streamID, count)
+}
+
+// CloseStream - JS stopped reading the stream
+func CloseStream(streamID string) {
+	registry.CloseStream(streamID)
+}
+
+func RegisterEventCallback(callback JsEvent) {
+	registry.RegisterEventCallback(newEventSender(callback))
+}
+
+func RemoveEventCallback() {
+	registry.RegisterEventCallback(nil)
+}
+
+// Subscribe - subsribe from JS
+func Subscribe(callData string) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	registry.Subscribe(methodCallData)
+}
+
+// Cancel - cancel subscription from JS
+func Cancel(callData string) {
+	methodCallData := make(map[string]interface{})
+	json.Unmarshal([]byte(callData), &methodCallData)
+
+	registry.CancelSubscription(methodCallData)
+}
+
+func init() {
+
+	registry.RegisterStream("exportUsers", streamAdapterForExportUsers)
+	registry.RegisterFunction("findUsers", callAdapterForFindUsers)
+	registry.RegisterFunction("getUser", callAdapterForGetUser)
+	registry.RegisterFunction("listUsers", callAdapterForListUsers)
+	registry.RegisterStream("numbers", streamAdapterForNumbers)
+	registry.RegisterFunction("saveUsers", callAdapterForSaveUsers)
+	registry.RegisterFunction("setStatus", callAdapterForSetStatus)
+	registry.RegisterNamedSubscription("watchUser", subscribeToWatchUser, subscriptionTypesWatchUser)
+
+	registry.RegisterFunction("add", callAdapterForAdd)
+	registry.RegisterFunction("thumbnail", callAdapterForThumbnail)
+}
+
+/**
+ * ExportUsers sends the users with the name prefix
+ */
+func streamAdapterForExportUsers(callData map[string]interface{}, stream goapi.Stream) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "prefix", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var prefix string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		prefix, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (prefix)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	return users.ExportUsers(prefix, stream)
+}
+
+func callAdapterForFindUsers(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "filter", Optional: false, Default: ""},
+		{Name: "limit", Optional: true, Default: "10"},
+		{Name: "sort", Optional: true, Default: "\"name\""},
+		{Name: "ids", Optional: true, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var filter users.Filter
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		filter, err = func(arg interface{}) (users.Filter, error) {
+
+			var obj users.Filter
+			err := mapstructure.Decode(arg, &obj)
+
+			return obj, err
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var limit int
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		limit, err = func(arg interface{}) (int, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				return strconv.Atoi(str)
+			}
+			fl, ok := arg.(float64)
+			if ok {
+				return int(fl), nil
+			}
+
+			return arg.(int), nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var sort string
+	if ________arg, ________ok := ________args.Get(2); ________ok {
+		sort, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (sort)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var ids []int
+	if ________arg, ________ok := ________args.Get(3); ________ok {
+		ids, err = func(arg interface{}) ([]int, error) {
+			argsSlice := arg.([]interface{})
+			outArray := make([]int, 0, len(argsSlice))
+
+			for _, element := range argsSlice {
+				item, err := func(arg interface{}) (int, error) {
+
+					if arg == nil {
+						return 0, errors.New("wrong type")
+					}
+
+					str, ok := arg.(string)
+					if ok {
+						return strconv.Atoi(str)
+					}
+
+					fl, ok := arg.(float64)
+					if ok {
+						return int(fl), nil
+					}
+
+					return arg.(int), nil
+
+				}(element)
+
+				if err != nil {
+					return outArray, err
+				}
+
+				outArray = append(outArray, item)
+			}
+
+			return outArray, nil
+
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________validator := goapi.NewValidator()
+	________validator.Check("filter", filter, "")
+	________validator.Check("sort", sort, "oneof=name email")
+	if err := ________validator.Err(); err != nil {
+		return err
+	}
+
+	users.FindUsers(filter, limit, sort, ids, callback)
+	return nil
+}
+
+func callAdapterForGetUser(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "id", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var id string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		id, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (id)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________validator := goapi.NewValidator()
+	________validator.Check("id", id, "required,min=3")
+	if err := ________validator.Err(); err != nil {
+		return err
+	}
+
+	users.GetUser(id, callback)
+	return nil
+}
+
+func callAdapterForListUsers(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "page", Optional: true, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var page *users.Page[users.User]
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		page, err = func(arg interface{}) (*users.Page[users.User], error) {
+
+			var obj *users.Page[users.User]
+			err := mapstructure.Decode(arg, &obj)
+
+			return obj, err
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	users.ListUsers(page, callback)
+	return nil
+}
+
+/**
+ * Numbers sends numbers from 1 to n
+ */
+func streamAdapterForNumbers(callData map[string]interface{}, stream goapi.Stream) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "n", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var n int
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		n, err = func(arg interface{}) (int, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				return strconv.Atoi(str)
+			}
+			fl, ok := arg.(float64)
+			if ok {
+				return int(fl), nil
+			}
+
+			return arg.(int), nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________items := users.Numbers(n)
+
+	for ________item := range ________items {
+		if err := stream.Send(________item); err != nil {
+			// js stopped reading, the producer must not block on the channel
+			go func() {
+				for range ________items {
+				}
+			}()
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func callAdapterForSaveUsers(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "team", Optional: true, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var team map[string]users.User
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		team, err = func(arg interface{}) (map[string]users.User, error) {
+
+			var obj map[string]users.User
+			err := mapstructure.Decode(arg, &obj)
+
+			return obj, err
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________validator := goapi.NewValidator()
+	________validator.Check("team", team, "")
+	if err := ________validator.Err(); err != nil {
+		return err
+	}
+
+	users.SaveUsers(team, callback)
+	return nil
+}
+
+func callAdapterForSetStatus(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "id", Optional: false, Default: ""},
+		{Name: "status", Optional: false, Default: ""},
+		{Name: "levels", Optional: true, Default: ""},
+		{Name: "counters", Optional: true, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var id string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		id, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (id)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var status users.Status
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		status, err = func(arg interface{}) (users.Status, error) {
+
+			var obj users.Status
+			err := mapstructure.Decode(arg, &obj)
+
+			return obj, err
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var levels []users.Level
+	if ________arg, ________ok := ________args.Get(2); ________ok {
+		levels, err = func(arg interface{}) ([]users.Level, error) {
+			argsSlice := arg.([]interface{})
+			outArray := make([]users.Level, 0, len(argsSlice))
+
+			for _, element := range argsSlice {
+				item, err := func(arg interface{}) (users.Level, error) {
+
+					var obj users.Level
+					err := mapstructure.Decode(arg, &obj)
+
+					return obj, err
+
+				}(element)
+
+				if err != nil {
+					return outArray, err
+				}
+
+				outArray = append(outArray, item)
+			}
+
+			return outArray, nil
+
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var counters map[string]int
+	if ________arg, ________ok := ________args.Get(3); ________ok {
+		counters, err = func(arg interface{}) (map[string]int, error) {
+
+			var obj map[string]int
+			err := mapstructure.Decode(arg, &obj)
+
+			return obj, err
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	users.SetStatus(id, status, levels, counters, callback)
+	return nil
+}
+
+/**
+ * WatchUser sends the user when it changes
+ */
+func subscribeToWatchUser(callData map[string]interface{}, event goapi.EventCallback) (goapi.Subscription, error) {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "id", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		id, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (id)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return users.WatchUser(id, event)
+}
+
+/**
+ * WatchUser sends the user when it changes
+ */
+func subscriptionTypesWatchUser(callData map[string]interface{}) ([]interface{}, error) {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "id", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		id, err = func(arg interface{}) (string, error) {
+
+			if arg == nil {
+				return "", errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if !ok {
+				num, ok := arg.(float64)
+				if ok {
+					return strconv.FormatFloat((float64)(num), 'f', -1, 64), nil
+				}
+
+				return "", errors.New("wrong type, string is expected (id)")
+			}
+
+			return str, nil
+		}(________arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]interface{}, 0, 1)
+	if ________args.Passed(0) {
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+/**
+ * Add adds numbers
+ */
+func callAdapterForAdd(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "a", Optional: false, Default: ""},
+		{Name: "b", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var a int
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		a, err = func(arg interface{}) (int, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				return strconv.Atoi(str)
+			}
+			fl, ok := arg.(float64)
+			if ok {
+				return int(fl), nil
+			}
+
+			return arg.(int), nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var b int
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		b, err = func(arg interface{}) (int, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				return strconv.Atoi(str)
+			}
+			fl, ok := arg.(float64)
+			if ok {
+				return int(fl), nil
+			}
+
+			return arg.(int), nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________result := users.Add(a, b)
+
+	callback.OnSuccess(________result)
+	return nil
+}
+
+/**
+ * Thumbnail scales the picture down
+ */
+func callAdapterForThumbnail(callData map[string]interface{}, callback goapi.JsCallback) error {
+	________args, err := goapi.NewArguments(callData, []goapi.Param{
+		{Name: "picture", Optional: false, Default: ""},
+		{Name: "size", Optional: false, Default: ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	var picture []byte
+	if ________arg, ________ok := ________args.Get(0); ________ok {
+		picture, err = func(arg interface{}) ([]byte, error) {
+			return goapi.DecodeBytes(arg)
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var size int
+	if ________arg, ________ok := ________args.Get(1); ________ok {
+		size, err = func(arg interface{}) (int, error) {
+
+			if arg == nil {
+				return 0, errors.New("wrong type")
+			}
+
+			str, ok := arg.(string)
+			if ok {
+				return strconv.Atoi(str)
+			}
+			fl, ok := arg.(float64)
+			if ok {
+				return int(fl), nil
+			}
+
+			return arg.(int), nil
+		}(________arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	________validator := goapi.NewValidator()
+	________validator.Check("picture", picture, "required")
+	if err := ________validator.Err(); err != nil {
+		return err
+	}
+
+	________result, ________err := users.Thumbnail(picture, size)
+	if ________err != nil {
+		return ________err
+	}
+
+	callback.OnSuccess(________result)
+	return nil
+}
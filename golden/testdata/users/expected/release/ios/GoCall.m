@@ -0,0 +1,135 @@
+//
+// GoCall library binding, generated by wand
+//
+
+#import "GoCall.h"
+#import <Userslink/Userslink.h>
+
+static NSString *const GoCallEvent = @"GoCallEvent";
+
+// byte slices of the results larger than this go to JS as files of the caches directory
+static const long GoCallBinaryThreshold = 64 * 1024;
+
+@interface GoCallPromise : NSObject <UserslinkJsCallback>
+
+@property (nonatomic, copy) RCTPromiseResolveBlock resolve;
+@property (nonatomic, copy) RCTPromiseRejectBlock reject;
+
+@end
+
+@implementation GoCallPromise
+
+- (void)onSuccess:(NSString *)json {
+  self.resolve(json);
+}
+
+- (void)onError:(NSString *)json {
+  self.reject(@"GoCallError", json, nil);
+}
+
+@end
+
+@interface GoCallEventSender : NSObject <UserslinkJsEvent>
+
+@property (nonatomic, weak) GoCall *module;
+
+@end
+
+@implementation GoCallEventSender
+
+- (void)onEvent:(NSString *)eventName json:(NSString *)json {
+  [self.module sendGoEvent:eventName json:json];
+}
+
+@end
+
+@implementation GoCall {
+  BOOL hasListeners;
+  GoCallEventSender *eventSender;
+}
+
+RCT_EXPORT_MODULE();
+
+- (instancetype)init {
+  if (self = [super init]) {
+    NSString *caches = NSSearchPathForDirectoriesInDomains(NSCachesDirectory, NSUserDomainMask, YES).firstObject;
+    UserslinkSetBinaryDir(caches, GoCallBinaryThreshold);
+  }
+
+  return self;
+}
+
++ (BOOL)requiresMainQueueSetup {
+  return NO;
+}
+
+- (NSArray<NSString *> *)supportedEvents {
+  return @[GoCallEvent];
+}
+
+- (void)startObserving {
+  hasListeners = YES;
+
+  eventSender = [GoCallEventSender new];
+  eventSender.module = self;
+  UserslinkRegisterEventCallback(eventSender);
+}
+
+- (void)stopObserving {
+  hasListeners = NO;
+
+  UserslinkRemoveEventCallback();
+  eventSender = nil;
+}
+
+- (void)sendGoEvent:(NSString *)eventName json:(NSString *)json {
+  if (hasListeners) {
+    [self sendEventWithName:GoCallEvent body:@{@"name": eventName, @"json": json}];
+  }
+}
+
+RCT_EXPORT_METHOD(callMethod:(NSString *)callData
+                  resolver:(RCTPromiseResolveBlock)resolve
+                  rejecter:(RCTPromiseRejectBlock)reject) {
+  GoCallPromise *callback = [GoCallPromise new];
+  callback.resolve = resolve;
+  callback.reject = reject;
+
+  UserslinkCallMethod(callData, callback);
+}
+
+RCT_EXPORT_BLOCKING_SYNCHRONOUS_METHOD(callMethodSync:(NSString *)callData) {
+  return UserslinkCallMethodSync(callData);
+}
+
+RCT_EXPORT_METHOD(openStream:(NSString *)callData
+                  streamID:(NSString *)streamID
+                  credits:(NSInteger)credits) {
+  UserslinkOpenStream(callData, streamID, credits);
+}
+
+RCT_EXPORT_METHOD(streamCredit:(NSString *)streamID
+                  count:(NSInteger)count) {
+  UserslinkStreamCredit(streamID, count);
+}
+
+RCT_EXPORT_METHOD(closeStream:(NSString *)streamID) {
+  UserslinkCloseStream(streamID);
+}
+
+RCT_EXPORT_METHOD(releaseBinary:(NSString *)uri) {
+  UserslinkReleaseBinary(uri);
+}
+
+RCT_EXPORT_METHOD(subscribe:(NSString *)callData) {
+  UserslinkSubscribe(callData);
+}
+
+RCT_EXPORT_METHOD(cancel:(NSString *)callData
+                  resolver:(RCTPromiseResolveBlock)resolve
+                  rejecter:(RCTPromiseRejectBlock)reject) {
+  UserslinkCancel(callData);
+  resolve(nil);
+}
+
+@end
@@ -0,0 +1,100 @@
+/**
+ * GoCall library binding
+ * flow
+ */
+
+import React, {Component, PureComponent, type ComponentType} from 'react';
+
+import {
+  View,
+  NativeModules,
+} from 'react-native';
+
+import {
+  branch,
+  renderNothing,
+} from 'recompose';
+
+export function RenderWhenReady(fields) {
+  return function(target) {
+    return branch(
+      (props) => {
+        if (Array.isArray(fields)) {
+          return !fields.reduce((acc, val) => acc && (props[val] !== undefined), true)
+        }
+
+        return props[fields] === undefined
+      },
+      renderNothing,
+    )(target)
+  }
+}
+
+import {
+  cancelSubscriptionApiCall,
+
+  exportUsers, 
+  findUsers, 
+  getUser, 
+  listUsers, 
+  numbers, 
+  saveUsers, 
+  setStatus, 
+  watchUser, 
+
+  type Filter, 
+  type Page, 
+  type User, 
+
+} from './users'
+
+const emptyArray = []
+
+type UserProp = {
+  
+  id: string,
+}
+
+export function withUser<Props: {} & UserProp>(
+  WrappedComponent : ComponentType<UserProp & { user : User }>
+) : ComponentType<Props> {
+  return class UserDataView extends Component<Props, *> {
+    subscription = undefined 
+
+    constructor(props: Props) {
+      super(props);
+      this.state = {  user :  undefined }
+    }
+
+    componentDidMount() {
+      
+      const { id} = this.props
+      
+
+      
+      getUser(id)
+        .then(this.onValue)
+      
+      
+      this.subscription = watchUser(id,this.onValue)
+      
+    }
+
+    onValue = (user: User) => {
+      this.setState({ user })
+    }
+
+    componentWillUnmount() {
+     cancelSubscriptionApiCall(this.subscription)
+     
+    }
+
+    render() {
+      const {  user  } = this.state;
+
+      return <WrappedComponent
+                user={  user  }
+                {...this.props} />
+    }
+  }
+}
@@ -0,0 +1,122 @@
+package users
+
+import (
+	"gitlab.vmassive.ru/wand/goapi"
+)
+
+// User of the application
+// @get: GetUser
+// @update: WatchUser
+type User struct {
+	// Name of the user
+	Name   string   `json:"name" validate:"required"`
+	Age    int      `json:"age" validate:"min=0,max=150"`
+	Tags   []string `json:"tags,omitempty"`
+	Friend *User    `json:"friend,omitempty"`
+	Status Status   `json:"status"`
+	Avatar []byte   `json:"avatar,omitempty"`
+}
+
+// Filter of FindUsers
+type Filter struct {
+	Query  string `json:"query" validate:"required,min=2"`
+	Owners []User `json:"owners"`
+	Email  string `json:"email" validate:"email"`
+}
+
+// Status of the user
+type Status string
+
+const (
+	Active  Status = "active"
+	Blocked Status = "blocked"
+)
+
+// Level of access
+type Level int
+
+const (
+	Guest Level = iota
+	Admin
+)
+
+// Page of items
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Total int    `json:"-"`
+}
+
+// GetUser returns the user by id
+// @validate: id required,min=3
+// @callback: User
+func GetUser(id string, callback goapi.JsCallback) {
+	callback.OnSuccess(User{Name: id})
+}
+
+// FindUsers searches users
+// @default: limit 10
+// @default: sort name
+// @validate: sort oneof=name email
+// @callback: User[]
+func FindUsers(filter Filter, limit int, sort string, ids []int, callback goapi.JsCallback) {
+	callback.OnSuccess([]User{})
+}
+
+// SetStatus changes the status of the user
+// @callback: boolean
+func SetStatus(id string, status Status, levels []Level, counters map[string]int, callback goapi.JsCallback) {
+	callback.OnSuccess(true)
+}
+
+// SaveUsers stores the users by their keys
+// @callback: boolean
+func SaveUsers(team map[string]User, callback goapi.JsCallback) {
+	callback.OnSuccess(true)
+}
+
+// ListUsers returns the next page
+// @callback: Page<User>
+func ListUsers(page *Page[User], callback goapi.JsCallback) {
+	callback.OnSuccess(page)
+}
+
+type subscription struct{}
+
+func (subscription) Cancel() {}
+
+// WatchUser sends the user when it changes
+// @subscription: User
+func WatchUser(id string, event goapi.EventCallback) (goapi.Subscription, error) {
+	return subscription{}, nil
+}
+
+// Numbers sends numbers from 1 to n
+func Numbers(n int) <-chan int {
+	items := make(chan int)
+	go func() {
+		defer close(items)
+		for i := 1; i <= n; i++ {
+			items <- i
+		}
+	}()
+
+	return items
+}
+
+// ExportUsers sends the users with the name prefix
+// @stream: User
+func ExportUsers(prefix string, stream goapi.Stream) error {
+	return stream.Send(User{Name: prefix})
+}
+
+// Thumbnail scales the picture down
+// @validate: picture required
+func Thumbnail(picture []byte, size int) ([]byte, error) {
+	return picture, nil
+}
+
+// Add adds numbers
+func Add(a int, b int) int {
+	return a + b
+}
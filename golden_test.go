@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"gitlab.vmassive.ru/wand/golden"
+)
+
+var update = flag.Bool("update", false, "write generated code as golden files")
+
+// TestGolden compares generated code of every fixture with its golden files,
+// go vet of generated code is skipped with -short
+func TestGolden(t *testing.T) {
+	options := golden.Options{
+		Dir:       "golden/testdata",
+		Update:    *update,
+		SkipBuild: testing.Short(),
+		GoPath:    getGoPath(),
+	}
+
+	fixtures, err := golden.Fixtures(options.Dir)
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		t.Run(fixture, func(t *testing.T) {
+			results, err := golden.RunFixture(options, fixture, generateFixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, result := range results {
+				for _, diff := range result.Diffs {
+					t.Errorf("%s: generated code differs from golden files\n%s", result.Variant, diff)
+				}
+
+				for _, message := range result.Errors {
+					t.Errorf("%s: %s", result.Variant, message)
+				}
+
+				for _, skipped := range result.Skipped {
+					t.Logf("%s: skipped %s", result.Variant, skipped)
+				}
+
+				for _, name := range result.Updated {
+					t.Logf("%s: updated %s", result.Variant, name)
+				}
+			}
+		})
+	}
+}
@@ -1,60 +1,91 @@
 package js
 
 import (
+	"bytes"
 	"errors"
-	"go/ast"
 	"io"
-	"io/ioutil"
-	"os"
 	"path"
-	"strings"
-	"text/template"
 
 	"github.com/iancoleman/strcase"
 	log "github.com/sirupsen/logrus"
-	"gitlab.vmassive.ru/wand/assets"
 	"gitlab.vmassive.ru/wand/generator"
+	"gitlab.vmassive.ru/wand/rules"
 )
 
+// Field parameter of a function or field of a structure
 type Field struct {
-	Name    string
-	Type    string
+	// Name of the parameter or field
+	Name string
+	// Type flow type
+	Type string
+	// GoType go type, as it is in the source
+	GoType string
+	// Optional is true when the field may be missing
+	Optional bool
+	// Comment doc comment lines
 	Comment []string
+	// Rules validation rules
+	Rules []rules.Rule
+	// ValidateType validated structure the value is decoded to, prefixed with "{}" for every
+	// map on the way to it, empty when there is none
+	ValidateType string
+	// Validate is true when the field has rules or ValidateType
+	Validate bool
 }
 
+// Function data of func.js.tmpl
 type Function struct {
-	Name         string
-	Comments     []string
-	ReturnType   string
-	Params       []Field
+	// Name lower camel case name of the js function
+	Name string
+	// Comments doc comment lines
+	Comments []string
+	// ReturnType flow type the promise is resolved with
+	ReturnType string
+	// Params parameters without the callback
+	Params []Field
+	// Subscription flow type of events, nil for calls
 	Subscription *string
+	// Validate is true when any parameter is validated
+	Validate bool
+	// Pure is true for functions without annotations, they return the result instead of callback
+	Pure bool
+	// Stream flow type of the items of streaming function, empty for other functions
+	Stream string
 }
 
+// Structure data of struct.js.tmpl
 type Structure struct {
+	// Comments doc comment lines
 	Comments []string
-	Name     string
-	Field    []Field
+	// Name of the flow type
+	Name string
+	// TypeParams type parameters of generic type
+	TypeParams []string
+	// Field fields of the type
+	Field []Field
 }
 
 type JsCodeGenerator struct {
 	outDirectory string
 	packageName  string
+	templates    *generator.Templates
 }
 
-func New(outDirectory string, packageName string) generator.Generator {
+func New(outDirectory string, packageName string, templates *generator.Templates) generator.Generator {
 	return &JsCodeGenerator{
 		outDirectory: outDirectory,
 		packageName:  packageName,
+		templates:    templates,
 	}
 }
 
-func (generator JsCodeGenerator) CreateCode(source *generator.CodeList) error {
-	err := generator.writeGeneral(source)
+func (generator JsCodeGenerator) CreateCode(source *generator.CodeList, sink generator.Sink) error {
+	err := generator.writeGeneral(source, sink)
 	if err != nil {
 		return err
 	}
 
-	err = generator.writeWithFunctions(source)
+	err = generator.writeWithFunctions(source, sink)
 	if err != nil {
 		return err
 	}
@@ -62,7 +93,7 @@ func (generator JsCodeGenerator) CreateCode(source *generator.CodeList) error {
 	return nil
 }
 
-func (gen JsCodeGenerator) writeWithFunctions(source *generator.CodeList) error {
+func (gen JsCodeGenerator) writeWithFunctions(source *generator.CodeList, sink generator.Sink) error {
 	outFile := gen.packageName + "HOC.js"
 
 	list := gen.getAnnotatedStructures(source)
@@ -72,16 +103,13 @@ func (gen JsCodeGenerator) writeWithFunctions(source *generator.CodeList) error
 
 	log.Printf("createing (with) %s", outFile)
 
-	f, err := os.Create(path.Join(gen.outDirectory, outFile))
+	f := &bytes.Buffer{}
+
+	err := gen.writeWithHeader(f, source)
 	if err != nil {
-		log.Errorf("failed to create file %s", outFile)
 		return err
 	}
 
-	defer f.Close()
-
-	gen.writeWithHeader(f, source)
-
 	for _, item := range list {
 		var getFunc, updateFunc *generator.FunctionData
 		get := gen.getAnnotation("get", item.Annotation)
@@ -94,46 +122,40 @@ func (gen JsCodeGenerator) writeWithFunctions(source *generator.CodeList) error
 			updateFunc = gen.findFunction(*update, source.Functions)
 		}
 
-		gen.writeWithFunction(f, getFunc, updateFunc, item)
+		err = gen.writeWithFunction(f, getFunc, updateFunc, item)
+		if err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return sink.WriteFile(path.Join(gen.outDirectory, outFile), f.Bytes())
 }
 
+// WithData data of with.js.tmpl, a HOC for the structure with @get and @update
 type WithData struct {
+	// VarName lower camel case name of the structure
 	VarName string
-	Name    string
-	Props   []Field
-	Update  *Function
-	Get     *Function
+	// Name of the structure
+	Name string
+	// Props parameters of get (or update) function
+	Props []Field
+	// Update function of @update annotation
+	Update *Function
+	// Get function of @get annotation
+	Get *Function
 }
 
+// WithHeader data of headWith.js.tmpl
 type WithHeader struct {
-	Structures  []string
-	Functions   []string
+	// Structures names of all structures
+	Structures []string
+	// Functions js names of all functions
+	Functions []string
+	// PackageName name of the source package, the js module to import
 	PackageName string
 }
 
 func (gen JsCodeGenerator) writeWithHeader(f io.Writer, sourceList *generator.CodeList) error {
-	// headBytes, err := ioutil.ReadFile("./templates/headWith.js.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return err
-	// }
-
-	file, err := assets.Assets.Open("/templates/headWith.js.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
-	}
-
-	headBytes, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
-	}
-
 	header := WithHeader{
 		PackageName: gen.packageName,
 	}
@@ -146,35 +168,10 @@ func (gen JsCodeGenerator) writeWithHeader(f io.Writer, sourceList *generator.Co
 		header.Structures = append(header.Structures, s.Name)
 	}
 
-	headTemplate, err := template.New("structType").Parse(string(headBytes))
-	if err != nil {
-		log.Errorf("failed to write head with error %v", err)
-		return err
-	}
-
-	return headTemplate.Execute(f, header)
+	return gen.templates.Execute(f, "headWith.js.tmpl", header)
 }
 
 func (gen JsCodeGenerator) writeWithFunction(wr io.Writer, get *generator.FunctionData, update *generator.FunctionData, strct generator.ExportedStucture) error {
-	// headBytes, err := ioutil.ReadFile("./templates/with.js.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return err
-	// }
-
-	file, err := assets.Assets.Open("/templates/with.js.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
-	}
-
-	headBytes, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return err
-	}
-
 	funcDecl := get
 	if get == nil {
 		funcDecl = update
@@ -187,26 +184,20 @@ func (gen JsCodeGenerator) writeWithFunction(wr io.Writer, get *generator.Functi
 	withData := WithData{
 		Name:    strct.Name,
 		VarName: strcase.ToLowerCamel(strct.Name),
-		Props:   createListOfFields(funcDecl.Params),
+		Props:   createListOfFields(funcDecl.Params, false, nil),
 	}
 
 	if get != nil {
-		jsFunc := createFunction(*get)
+		jsFunc := createFunction(*get, nil)
 		withData.Get = &jsFunc
 	}
 
 	if update != nil {
-		jsFunc := createFunction(*update)
+		jsFunc := createFunction(*update, nil)
 		withData.Update = &jsFunc
 	}
 
-	headTemplate, err := template.New("with").Parse(string(headBytes))
-	if err != nil {
-		log.Errorf("failed to write head with error %v", err)
-		return err
-	}
-
-	return headTemplate.Execute(wr, withData)
+	return gen.templates.Execute(wr, "with.js.tmpl", withData)
 }
 
 func (gen JsCodeGenerator) findFunction(name string, list []generator.FunctionData) *generator.FunctionData {
@@ -233,7 +224,7 @@ func (gen JsCodeGenerator) getAnnotatedStructures(source *generator.CodeList) []
 	list := make([]generator.ExportedStucture, 0, len(source.Structures))
 
 	for _, stucture := range source.Structures {
-		if len(stucture.Annotation) > 0 {
+		if gen.getAnnotation("get", stucture.Annotation) != nil || gen.getAnnotation("update", stucture.Annotation) != nil {
 			list = append(list, stucture)
 		}
 	}
@@ -241,222 +232,190 @@ func (gen JsCodeGenerator) getAnnotatedStructures(source *generator.CodeList) []
 	return list
 }
 
-func (generator JsCodeGenerator) writeGeneral(source *generator.CodeList) error {
-	outFile := generator.packageName + ".js"
+func (gen JsCodeGenerator) writeGeneral(source *generator.CodeList, sink generator.Sink) error {
+	outFile := gen.packageName + ".js"
 	log.Printf("createing %s", outFile)
 
-	f, err := os.Create(path.Join(generator.outDirectory, outFile))
+	f := &bytes.Buffer{}
+
+	err := gen.writeHeader(f, source)
 	if err != nil {
-		log.Errorf("failed to create file %s", outFile)
 		return err
 	}
 
-	defer f.Close()
-	writeHeader(f, source)
-	writeFunctions(f, source)
-	writeStructures(f, source)
-
-	return nil
-}
-
-func writeHeader(f io.Writer, sourceList *generator.CodeList) error {
-	// headBytes, err := ioutil.ReadFile("head.js.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return err
-	// }
-
-	file, err := assets.Assets.Open("/templates/head.js.tmpl")
-	defer file.Close()
+	err = gen.writeFunctions(f, source)
 	if err != nil {
-		log.Errorf("read file error %v", err)
 		return err
 	}
 
-	headBytes, err := ioutil.ReadAll(file)
+	err = gen.writeStructures(f, source)
 	if err != nil {
-		log.Errorf("read file error %v", err)
 		return err
 	}
 
-	headTemplate, err := template.New("structType").Parse(string(headBytes))
+	err = gen.writeValidation(f, source)
 	if err != nil {
-		log.Errorf("failed to write head with error %v", err)
 		return err
 	}
 
-	return headTemplate.Execute(f, sourceList)
+	return sink.WriteFile(path.Join(gen.outDirectory, outFile), f.Bytes())
 }
 
-func writeFunctions(wr io.Writer, source *generator.CodeList) {
+func (gen JsCodeGenerator) writeHeader(f io.Writer, sourceList *generator.CodeList) error {
+	return gen.templates.Execute(f, "head.js.tmpl", sourceList)
+}
+
+func (gen JsCodeGenerator) writeFunctions(wr io.Writer, source *generator.CodeList) error {
+	validated := source.ValidatedStructures()
+
 	for _, function := range source.Functions {
-		writeFunction(wr, function)
+		err := gen.writeFunction(wr, function, validated)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, function := range source.Pure {
+		err := gen.writeFunction(wr, function, validated)
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func writeStructures(wr io.Writer, source *generator.CodeList) {
+func (gen JsCodeGenerator) writeStructures(wr io.Writer, source *generator.CodeList) error {
 	for _, strct := range source.Structures {
-		writeStructure(wr, strct)
+		err := gen.writeStructure(wr, strct)
+		if err != nil {
+			return err
+		}
 	}
-}
 
-func createJsType(tp ast.Expr) string {
-	switch x := tp.(type) {
-	case *ast.Ident:
-		return toJsName(x.Name)
-	case *ast.SelectorExpr:
-		return toJsName(x.Sel.Name)
+	return nil
+}
 
-	case *ast.MapType:
-		return "{ [key: " + createJsType(x.Key) + "]: " + createJsType(x.Value) + "}"
-	case *ast.StarExpr:
-		return "?" + createJsType(x.X)
-	case *ast.ArrayType:
-		return createJsType(x.Elt) + "[]"
-	}
+func (gen JsCodeGenerator) writeFunction(wr io.Writer, function generator.FunctionData, validated map[string]bool) error {
+	return gen.templates.Execute(wr, "func.js.tmpl", createFunction(function, validated))
+}
 
-	return ""
+// ValidationType validated fields of the structure, an entry of validationRules
+type ValidationType struct {
+	// Name of the structure
+	Name string
+	// Field validated fields with json names
+	Field []Field
 }
 
-func writeFunction(wr io.Writer, function generator.FunctionData) {
-	// b, err := ioutil.ReadFile("func.js.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return
-	// }
+func (gen JsCodeGenerator) writeValidation(wr io.Writer, source *generator.CodeList) error {
+	validated := source.ValidatedStructures()
+	types := make([]ValidationType, 0, len(validated))
 
-	file, err := assets.Assets.Open("/templates/func.js.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
-	}
+	for _, structure := range source.Structures {
+		if !validated[structure.Name] {
+			continue
+		}
 
-	b, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
-	}
+		validationType := ValidationType{Name: structure.Name}
+		for _, field := range createListOfFields(structure.Field, true, validated) {
+			if field.Validate {
+				validationType.Field = append(validationType.Field, field)
+			}
+		}
 
-	t, err := template.New("structType").Parse(string(b))
-	if err != nil {
-		log.Errorf("failed with error %v", err)
-		return
+		types = append(types, validationType)
 	}
 
-	err = t.Execute(wr, createFunction(function))
-	if err != nil {
-		log.Errorf("template failed with error %v", err)
-	}
+	return gen.templates.Execute(wr, "validation.js.tmpl", types)
 }
 
-func writeStructure(wr io.Writer, structType generator.ExportedStucture) {
-	// b, err := ioutil.ReadFile("struct.js.tmpl") // just pass the file name
-	// if err != nil {
-	// 	log.Errorf("read file error %v", err)
-	// 	return
-	// }
+func (gen JsCodeGenerator) writeStructure(wr io.Writer, structType generator.ExportedStucture) error {
+	return gen.templates.Execute(wr, "struct.js.tmpl", createStructure(structType))
+}
 
-	file, err := assets.Assets.Open("/templates/struct.js.tmpl")
-	defer file.Close()
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
-	}
+func createFunction(function generator.FunctionData, validated map[string]bool) Function {
+	params := createListOfFields(function.Params, false, validated)
 
-	b, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Errorf("read file error %v", err)
-		return
+	validate := false
+	for _, param := range params {
+		validate = validate || param.Validate
 	}
 
-	t, err := template.New("structType").Parse(string(b))
-	if err != nil {
-		log.Errorf("failed with error %v", err)
-		return
+	returnType := generator.JsTypeName(function.ReturnType)
+	pure := function.Kind() == "pure"
+	if pure {
+		returnType = "void"
+		if result := function.Result(); result != nil {
+			returnType = generator.JsType(result.Type)
+		}
 	}
 
-	err = t.Execute(wr, createStructure(structType))
-	if err != nil {
-		log.Errorf("template failed with error %v", err)
+	stream := ""
+	if function.Stream != nil {
+		stream = generator.JsType(function.Stream)
 	}
-}
 
-func createFunction(function generator.FunctionData) Function {
 	return Function{
 		Name:         strcase.ToLowerCamel(function.Name),
 		Comments:     function.Comments,
-		ReturnType:   toJsName(function.ReturnType),
-		Params:       createListOfFields(function.Params),
+		ReturnType:   returnType,
+		Params:       params,
 		Subscription: function.Subscription,
+		Validate:     validate,
+		Pure:         pure,
+		Stream:       stream,
 	}
 }
 
 func createStructure(structType generator.ExportedStucture) Structure {
 	return Structure{
-		Name:     structType.Name,
-		Field:    createListOfFields(structType.Field),
-		Comments: structType.Comments,
+		Name:       structType.Name,
+		TypeParams: structType.TypeParams,
+		Field:      createListOfFields(structType.Field, true, nil),
+		Comments:   structType.Comments,
 	}
 }
 
-func createListOfFields(list *ast.FieldList) []Field {
-	fields := make([]Field, 0, 100)
-	for _, field := range list.List {
-		typeName := createJsType(field.Type)
-		if typeName == "" {
-			typeName = "any"
-		}
-
-		// Skip callback type
-		if typeName == "JsCallback" || typeName == "EventCallback" {
-			continue
-		}
-
-		for _, name := range field.Names {
-			fieldInfo := Field{
-				Name:    name.Name,
-				Type:    typeName,
-				Comment: getComments(field.Doc),
-			}
-
-			fields = append(fields, fieldInfo)
+// createListOfFields - js fields, structures use json names of the fields
+// validateTypeName structure name for validateValue of js, "{}" tells that values of the map are
+// validated, arrays and pointers are followed by js itself
+func validateTypeName(tp *generator.Type) string {
+	prefix := ""
+	for tp != nil && (tp.Kind == generator.KindPointer || tp.Kind == generator.KindSlice || tp.Kind == generator.KindMap) {
+		if tp.Kind == generator.KindMap {
+			prefix += "{}"
 		}
+		tp = tp.Elem
 	}
 
-	return fields
+	return prefix + generator.StructureName(tp)
 }
 
-func toJsName(name string) string {
-	switch name {
-	case "float32":
-		fallthrough
-	case "float64":
-		fallthrough
-	case "int":
-		fallthrough
-	case "int16":
-		fallthrough
-	case "int64":
-		fallthrough
-	case "int8":
-		fallthrough
-	case "int32":
-		return "number"
-	case "bool":
-		return "boolean"
-	}
-
-	return name
-}
+func createListOfFields(list []generator.Field, jsonNames bool, validated map[string]bool) []Field {
+	fields := make([]Field, 0, len(list))
+	for _, field := range list {
+		name := field.Name
+		if jsonNames {
+			name = field.JSONName
+		}
 
-func getComments(commGroup *ast.CommentGroup) []string {
-	comments := make([]string, 0, 6)
-	if commGroup != nil {
-		for _, comment := range commGroup.List {
-			comments = append(comments, strings.TrimLeft(strings.TrimPrefix(comment.Text, "//"), " "))
+		validateType := ""
+		if validated[generator.StructureName(field.Type)] {
+			validateType = validateTypeName(field.Type)
 		}
+
+		fields = append(fields, Field{
+			Name:         name,
+			Type:         generator.JsType(field.Type),
+			GoType:       generator.GoType(field.Type, ""),
+			Optional:     field.Optional,
+			Comment:      field.Comments,
+			Rules:        field.Rules,
+			ValidateType: validateType,
+			Validate:     generator.NeedsValidation(field, validated),
+		})
 	}
 
-	return comments
+	return fields
 }
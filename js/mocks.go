@@ -0,0 +1,59 @@
+package js
+
+import (
+	"bytes"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+// MockData data of mock.js.tmpl, jest mock of the generated module
+type MockData struct {
+	// PackageName name of the source package, the js module to mock
+	PackageName string
+	// Structures names of all structures, they are imported as types
+	Structures []string
+	// Functions calls, pure functions and subscriptions
+	Functions []Function
+}
+
+type JsMockGenerator struct {
+	outDirectory string
+	packageName  string
+	templates    *generator.Templates
+}
+
+// NewMocks generator of __mocks__/<package>.js for jest.mock
+func NewMocks(outDirectory string, packageName string, templates *generator.Templates) generator.Generator {
+	return &JsMockGenerator{
+		outDirectory: outDirectory,
+		packageName:  packageName,
+		templates:    templates,
+	}
+}
+
+func (gen JsMockGenerator) CreateCode(source *generator.CodeList, sink generator.Sink) error {
+	outFile := path.Join("__mocks__", gen.packageName+".js")
+	log.Printf("createing %s", outFile)
+
+	data := MockData{
+		PackageName: gen.packageName,
+	}
+
+	for _, structure := range source.Structures {
+		data.Structures = append(data.Structures, structure.Name)
+	}
+
+	for _, function := range append(append([]generator.FunctionData{}, source.Functions...), source.Pure...) {
+		data.Functions = append(data.Functions, createFunction(function, nil))
+	}
+
+	f := &bytes.Buffer{}
+	err := gen.templates.Execute(f, "mock.js.tmpl", data)
+	if err != nil {
+		return err
+	}
+
+	return sink.WriteFile(path.Join(gen.outDirectory, outFile), f.Bytes())
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+// listApplication prints exported and skipped functions of the source with the reasons
+func listApplication(configName string) error {
+	codeList, err := createCodeList(configName, false)
+	if err != nil {
+		return err
+	}
+
+	err = ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tKIND\tNAME\tREASON")
+
+	exported := append(append([]generator.FunctionData{}, codeList.Functions...), codeList.Pure...)
+	for _, function := range exported {
+		fmt.Fprintf(w, "exported\t%s\t%s\t\n", function.Kind(), function.Name)
+	}
+
+	for _, function := range codeList.Skipped {
+		fmt.Fprintf(w, "skipped\t%s\t%s\t%s (%s:%d)\n", function.Kind, function.Name, function.Reason, filepath.Base(function.Position.Filename), function.Position.Line)
+	}
+
+	return w.Flush()
+}
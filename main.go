@@ -3,13 +3,17 @@ package main
 import (
 	"go/build"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
 	"os/signal"
 	"path"
+	"strconv"
 	"syscall"
 
 	"gitlab.vmassive.ru/wand/config"
 	"gitlab.vmassive.ru/wand/generator"
+	"gitlab.vmassive.ru/wand/native"
 	"gitlab.vmassive.ru/wand/reload"
 	"gitlab.vmassive.ru/wand/web"
 
@@ -33,13 +37,30 @@ func main() {
 			Name:  "release",
 			Usage: "contruct release",
 		},
+		cli.StringFlag{
+			Name:  "record",
+			Usage: "record requests, responses and events of the dev server to the file",
+		},
+		cli.BoolFlag{
+			Name:  "strict",
+			Usage: "fail generation when the parser reports any warning or error",
+		},
+	}
+
+	app.Before = func(c *cli.Context) error {
+		strict = c.Bool("strict")
+		return nil
 	}
 
 	app.Name = "wand"
 	app.Usage = "magic link beetween go and js"
 	app.Action = func(c *cli.Context) error {
-		runApplication(c.String("config"), !c.Bool("release"))
-		return nil
+		err := recordSession(c.String("record"))
+		if err != nil {
+			return err
+		}
+
+		return runApplication(c.String("config"), !c.Bool("release"))
 	}
 
 	app.Commands = []cli.Command{
@@ -53,6 +74,156 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "generate",
+			Usage: "parse the source once and generate all the code",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dev",
+					Usage: "generate code for development server",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "only list the files which would be changed",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				sink := generator.NewDiskSink()
+				if c.Bool("dry-run") {
+					sink = generator.NewDryRunSink(os.Stdout)
+				}
+
+				return generateApplication(c.GlobalString("config"), c.Bool("dev"), sink)
+			},
+		},
+		{
+			Name:  "check",
+			Usage: "check that generated code is up to date with the source",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dev",
+					Usage: "check code for development server",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return checkApplication(c.GlobalString("config"), c.Bool("dev"))
+			},
+		},
+		{
+			Name:  "schema",
+			Usage: "write json description of the api exposed to js",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "out",
+					Usage: "output file, <js>/<package>.schema.json by default, - for stdout",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return schemaApplication(c.GlobalString("config"), c.String("out"))
+			},
+		},
+		{
+			Name:  "dump",
+			Usage: "print parsed source as json, for debugging",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dev",
+					Usage: "parse for development server",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return dumpApplication(c.GlobalString("config"), c.Bool("dev"))
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "print which functions are exported and which are skipped and why",
+			Action: func(c *cli.Context) error {
+				return listApplication(c.GlobalString("config"))
+			},
+		},
+		{
+			Name:      "replay",
+			Usage:     "serve recorded session to js instead of the dev server",
+			ArgsUsage: "<session.jsonl>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "match",
+					Value: "args",
+					Usage: "match calls by method and arguments (args) or by method only (method)",
+				},
+				cli.StringFlag{
+					Name:  "timing",
+					Value: "realtime",
+					Usage: "send responses and events with recorded delays (realtime) or at once (immediate)",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.NewExitError("session file is required", 1)
+				}
+
+				return replayApplication(c.GlobalString("config"), c.Args().First(), c.String("match"), c.String("timing"))
+			},
+		},
+		{
+			Name:  "golden",
+			Usage: "compare generated code of the fixtures with golden files",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dir",
+					Value: "golden/testdata",
+					Usage: "directory with the fixtures",
+				},
+				cli.BoolFlag{
+					Name:  "update",
+					Usage: "write generated code as golden files",
+				},
+				cli.BoolFlag{
+					Name:  "skip-build",
+					Usage: "do not run go vet on generated go code",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return goldenApplication(c.String("dir"), c.Bool("update"), c.Bool("skip-build"))
+			},
+		},
+		{
+			Name:  "templates",
+			Usage: "work with code templates",
+			Subcommands: []cli.Command{
+				{
+					Name:  "eject",
+					Usage: "copy embedded templates to the templates directory",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "dir",
+							Usage: "target directory, templates from wand.yaml by default",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "overwrite existing templates",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return ejectTemplates(c.GlobalString("config"), c.String("dir"), c.Bool("force"))
+					},
+				},
+			},
+		},
+		{
+			Name:  "bind",
+			Usage: "generate release code and build native libraries with gomobile",
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "target",
+					Usage: "android or ios, all configured targets by default",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return bindApplication(c.GlobalString("config"), c.StringSlice("target"))
+			},
+		},
 	}
 
 	err := app.Run(os.Args)
@@ -70,10 +241,103 @@ func getGoPath() string {
 	return gopath
 }
 
-func runApplication(configName string, dev bool) {
+func runApplication(configName string, dev bool) error {
+	codeList, err := createCodeList(configName, dev)
+	if err != nil {
+		return err
+	}
+
+	if dev {
+		watchGo(codeList)
+		return nil
+	}
+
+	return Parse(codeList, nil)
+}
+
+func generateApplication(configName string, dev bool, sink generator.Sink) error {
+	codeList, err := createCodeList(configName, dev)
+	if err != nil {
+		return err
+	}
+
+	err = ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	return Generate(codeList, sink)
+}
+
+func bindApplication(configName string, targets []string) error {
+	codeList, err := createCodeList(configName, false)
+	if err != nil {
+		return err
+	}
+
+	err = ParseSource(codeList)
+	if err != nil {
+		return err
+	}
+
+	err = Generate(codeList, generator.NewDiskSink())
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		targets = bindTargets(codeList.Config)
+	}
+
+	for _, target := range targets {
+		err = native.Bind(codeList.Config, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ejectTemplates(configName string, dir string, force bool) error {
+	if dir == "" {
+		configuration, err := config.ReadConfig(configName)
+		if err == nil {
+			dir = configuration.Templates
+		}
+	}
+
+	if dir == "" {
+		dir = "templates"
+	}
+
+	return generator.EjectTemplates(dir, force)
+}
+
+func bindTargets(configuration *config.Configuration) []string {
+	targets := make([]string, 0, 2)
+	if configuration.Native.Android.Path != "" {
+		targets = append(targets, native.TargetAndroid)
+	}
+
+	if configuration.Native.Ios.Path != "" {
+		targets = append(targets, native.TargetIos)
+	}
+
+	if len(targets) == 0 {
+		targets = append(targets, native.TargetAndroid, native.TargetIos)
+	}
+
+	return targets
+}
+
+// strict fails parsing on any diagnostic, set by the global --strict flag
+var strict bool
+
+func createCodeList(configName string, dev bool) (*generator.CodeList, error) {
 	configuration, err := config.ReadConfig(configName)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	goPath := getGoPath()
@@ -81,9 +345,6 @@ func runApplication(configName string, dev bool) {
 	fullGoSourcePath := path.Join(goPath, "src", configuration.Source.Package)
 	targetGoCallPath := path.Join(goPath, "src", configuration.Wrapper.Package)
 
-	createDirectory(targetGoCallPath)
-	createDirectory(configuration.Js.Path)
-
 	pathMap := generator.PathMap{
 		Source: fullGoSourcePath,
 		Target: targetGoCallPath,
@@ -99,20 +360,19 @@ func runApplication(configName string, dev bool) {
 		Package:       goPackageName,
 		Dev:           dev,
 		Port:          configuration.Wrapper.Port,
+		Bridge:        configuration.Wrapper.Bridge,
 		SourcePackage: configuration.Source.Package,
 		PathMap:       pathMap,
 		Config:        configuration,
+		Strict:        strict,
 	}
 
-	if dev {
-		watchGo(codeList)
-	} else {
-		Parse(codeList)
-	}
+	return codeList, nil
 }
 
 func watchGo(codeList *generator.CodeList) {
-	Parse(codeList)
+	cache := generator.NewCache()
+	Parse(codeList, cache)
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -128,7 +388,7 @@ func watchGo(codeList *generator.CodeList) {
 	shutdown(rel)
 
 	go rel.Run()
-	go webFace()
+	go webFace(codeList.Port)
 
 	done := make(chan bool)
 	go func() {
@@ -150,7 +410,7 @@ func watchGo(codeList *generator.CodeList) {
 					event.Op&fsnotify.Rename == fsnotify.Rename ||
 					event.Op&fsnotify.Create == fsnotify.Create {
 					log.Println("modified file:", event.Name)
-					Parse(codeList)
+					Parse(codeList, cache)
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
@@ -168,15 +428,14 @@ func watchGo(codeList *generator.CodeList) {
 	<-done
 }
 
-func createDirectory(dir string) {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		os.Mkdir(dir, os.ModePerm)
-	}
-}
-
-func webFace() {
+func webFace(port int16) {
 	fs := http.FileServer(web.Assets)
 	http.Handle("/", http.StripPrefix("", fs))
+
+	// metrics of the dev server for the metrics.html page of the devtools
+	target := &url.URL{Scheme: "http", Host: "localhost:" + strconv.Itoa(int(port))}
+	http.Handle("/metrics", httputil.NewSingleHostReverseProxy(target))
+
 	http.ListenAndServe(":9000", nil)
 }
 
@@ -0,0 +1,69 @@
+package native
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.vmassive.ru/wand/config"
+)
+
+const (
+	TargetAndroid = "android"
+	TargetIos     = "ios"
+)
+
+// BindOutput file created by gomobile for the target
+func BindOutput(configuration *config.Configuration, target string) string {
+	goPackage := path.Base(configuration.Wrapper.Package)
+
+	switch target {
+	case TargetAndroid:
+		if configuration.Native.Android.Output != "" {
+			return configuration.Native.Android.Output
+		}
+		return goPackage + ".aar"
+
+	case TargetIos:
+		if configuration.Native.Ios.Output != "" {
+			return configuration.Native.Ios.Output
+		}
+		return strings.Title(goPackage) + ".framework"
+	}
+
+	return ""
+}
+
+// BindArgs arguments of gomobile for the target
+func BindArgs(configuration *config.Configuration, target string) ([]string, error) {
+	output := BindOutput(configuration, target)
+	if output == "" {
+		return nil, fmt.Errorf("unknown bind target %s", target)
+	}
+
+	return []string{"bind", "-target", target, "-o", output, configuration.Wrapper.Package}, nil
+}
+
+// Bind runs gomobile bind on the wrapper package
+func Bind(configuration *config.Configuration, target string) error {
+	args, err := BindArgs(configuration, target)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("gomobile %v", args)
+
+	cmd := exec.Command("gomobile", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err != nil {
+		log.Errorf("gomobile bind for %s failed: %v", target, err)
+	}
+
+	return err
+}
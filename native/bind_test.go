@@ -0,0 +1,89 @@
+package native
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.vmassive.ru/wand/config"
+)
+
+func bindConfiguration(android string, ios string) *config.Configuration {
+	configuration := &config.Configuration{}
+	configuration.Wrapper.Package = "gitlab.vmassive.ru/demo/demolink"
+	configuration.Native.Android.Output = android
+	configuration.Native.Ios.Output = ios
+
+	return configuration
+}
+
+func TestBindOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration *config.Configuration
+		target        string
+		output        string
+	}{
+		{"android default", bindConfiguration("", ""), TargetAndroid, "demolink.aar"},
+		{"android configured", bindConfiguration("libs/demo.aar", ""), TargetAndroid, "libs/demo.aar"},
+		{"ios default", bindConfiguration("", ""), TargetIos, "Demolink.framework"},
+		{"ios configured", bindConfiguration("", "ios/Demo.framework"), TargetIos, "ios/Demo.framework"},
+		{"unknown target", bindConfiguration("", ""), "windows", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if output := BindOutput(test.configuration, test.target); output != test.output {
+				t.Errorf("BindOutput() = %q, want %q", output, test.output)
+			}
+		})
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration *config.Configuration
+		target        string
+		args          []string
+		fails         bool
+	}{
+		{
+			name:          "android",
+			configuration: bindConfiguration("", ""),
+			target:        TargetAndroid,
+			args:          []string{"bind", "-target", "android", "-o", "demolink.aar", "gitlab.vmassive.ru/demo/demolink"},
+		},
+		{
+			name:          "ios with output",
+			configuration: bindConfiguration("", "out/Demo.framework"),
+			target:        TargetIos,
+			args:          []string{"bind", "-target", "ios", "-o", "out/Demo.framework", "gitlab.vmassive.ru/demo/demolink"},
+		},
+		{
+			name:          "unknown target",
+			configuration: bindConfiguration("", ""),
+			target:        "windows",
+			fails:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args, err := BindArgs(test.configuration, test.target)
+			if test.fails {
+				if err == nil {
+					t.Fatalf("BindArgs() = %v, want an error", args)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("BindArgs() failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("BindArgs() = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package native
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.vmassive.ru/wand/config"
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+// ModuleData data for native module templates
+type ModuleData struct {
+	// JavaPackage package of the generated react native module
+	JavaPackage string
+	// GoPackage package created by gomobile for the wrapper
+	GoPackage string
+	// GoClass class (java) or prefix (objc) created by gomobile for the wrapper
+	GoClass string
+}
+
+type NativeCodeGenerator struct {
+	native      config.Native
+	packageName string
+	templates   *generator.Templates
+}
+
+func New(native config.Native, packageName string, templates *generator.Templates) generator.Generator {
+	return &NativeCodeGenerator{
+		native:      native,
+		packageName: packageName,
+		templates:   templates,
+	}
+}
+
+// NewModuleData builds template data for the wrapper package
+func NewModuleData(wrapperPackage string, javaPackage string) ModuleData {
+	goPackage := path.Base(wrapperPackage)
+
+	return ModuleData{
+		JavaPackage: javaPackage,
+		GoPackage:   goPackage,
+		GoClass:     strings.Title(goPackage),
+	}
+}
+
+func (gen NativeCodeGenerator) CreateCode(source *generator.CodeList, sink generator.Sink) error {
+	data := NewModuleData(gen.packageName, gen.native.Android.Package)
+
+	if gen.native.Android.Path != "" {
+		err := gen.writeAndroid(data, sink)
+		if err != nil {
+			return err
+		}
+	}
+
+	if gen.native.Ios.Path != "" {
+		err := gen.writeIos(data, sink)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AndroidDirectory directory of the java sources for the module
+func AndroidDirectory(android config.Android) string {
+	return path.Join(android.Path, strings.Replace(android.Package, ".", "/", -1))
+}
+
+func (gen NativeCodeGenerator) writeAndroid(data ModuleData, sink generator.Sink) error {
+	if gen.native.Android.Package == "" {
+		log.Errorf("android package is not set, skipping android module")
+		return nil
+	}
+
+	outDirectory := AndroidDirectory(gen.native.Android)
+
+	err := gen.writeFile(sink, path.Join(outDirectory, "GoCallModule.java"), "module.java.tmpl", data)
+	if err != nil {
+		return err
+	}
+
+	return gen.writeFile(sink, path.Join(outDirectory, "GoCallPackage.java"), "package.java.tmpl", data)
+}
+
+func (gen NativeCodeGenerator) writeIos(data ModuleData, sink generator.Sink) error {
+	outDirectory := gen.native.Ios.Path
+
+	err := gen.writeFile(sink, path.Join(outDirectory, "GoCall.h"), "module.h.tmpl", data)
+	if err != nil {
+		return err
+	}
+
+	return gen.writeFile(sink, path.Join(outDirectory, "GoCall.m"), "module.m.tmpl", data)
+}
+
+func (gen NativeCodeGenerator) writeFile(sink generator.Sink, outFile string, templateName string, data ModuleData) error {
+	log.Printf("createing %s", outFile)
+
+	f := &bytes.Buffer{}
+
+	err := WriteModule(f, gen.templates, templateName, data)
+	if err != nil {
+		return err
+	}
+
+	return sink.WriteFile(outFile, f.Bytes())
+}
+
+// WriteModule renders native module template
+func WriteModule(wr io.Writer, templates *generator.Templates, templateName string, data ModuleData) error {
+	return templates.Execute(wr, templateName, data)
+}
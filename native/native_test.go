@@ -0,0 +1,107 @@
+package native
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.vmassive.ru/wand/config"
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+func TestNewModuleData(t *testing.T) {
+	data := NewModuleData("gitlab.vmassive.ru/demo/demolink", "com.demoapp.gocall")
+
+	expected := ModuleData{JavaPackage: "com.demoapp.gocall", GoPackage: "demolink", GoClass: "Demolink"}
+	if data != expected {
+		t.Errorf("NewModuleData() = %+v, want %+v", data, expected)
+	}
+}
+
+func TestCreateCode(t *testing.T) {
+	templates, err := generator.NewTemplates("")
+	if err != nil {
+		t.Fatalf("templates: %v", err)
+	}
+
+	native := config.Native{
+		Android: config.Android{Path: "android/java", Package: "com.demoapp.gocall"},
+		Ios:     config.Ios{Path: "ios/GoCall"},
+	}
+
+	sink := generator.NewMemorySink()
+	err = New(native, "gitlab.vmassive.ru/demo/demolink", templates).CreateCode(&generator.CodeList{}, sink)
+	if err != nil {
+		t.Fatalf("CreateCode() failed: %v", err)
+	}
+
+	expected := map[string][]string{
+		"android/java/com/demoapp/gocall/GoCallModule.java": {
+			"package com.demoapp.gocall;",
+			"import demolink.Demolink;",
+			"Demolink.callMethod(callData",
+		},
+		"android/java/com/demoapp/gocall/GoCallPackage.java": {
+			"package com.demoapp.gocall;",
+			"public class GoCallPackage implements ReactPackage",
+		},
+		"ios/GoCall/GoCall.h": {},
+		"ios/GoCall/GoCall.m": {
+			"#import <Demolink/Demolink.h>",
+			"<DemolinkJsCallback>",
+		},
+	}
+
+	if names := sink.Names(); len(names) != len(expected) {
+		t.Fatalf("CreateCode() wrote %v", names)
+	}
+
+	for name, parts := range expected {
+		content, ok := sink.Files[name]
+		if !ok {
+			t.Errorf("%s is not written", name)
+			continue
+		}
+
+		for _, part := range parts {
+			if !strings.Contains(string(content), part) {
+				t.Errorf("%s has no %q", name, part)
+			}
+		}
+	}
+}
+
+func TestCreateCodeWithoutAndroidPackage(t *testing.T) {
+	templates, err := generator.NewTemplates("")
+	if err != nil {
+		t.Fatalf("templates: %v", err)
+	}
+
+	native := config.Native{Android: config.Android{Path: "android/java"}}
+
+	sink := generator.NewMemorySink()
+	err = New(native, "demolink", templates).CreateCode(&generator.CodeList{}, sink)
+	if err != nil {
+		t.Fatalf("CreateCode() failed: %v", err)
+	}
+
+	if len(sink.Files) != 0 {
+		t.Errorf("CreateCode() wrote %v without android package", sink.Names())
+	}
+}
+
+func TestWriteModule(t *testing.T) {
+	templates, err := generator.NewTemplates("")
+	if err != nil {
+		t.Fatalf("templates: %v", err)
+	}
+
+	out := &strings.Builder{}
+	err = WriteModule(out, templates, "package.java.tmpl", NewModuleData("demolink", "com.demoapp.gocall"))
+	if err != nil {
+		t.Fatalf("WriteModule() failed: %v", err)
+	}
+
+	if !strings.HasPrefix(out.String(), "package com.demoapp.gocall;") {
+		t.Errorf("WriteModule() = %q", out.String())
+	}
+}
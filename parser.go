@@ -1,11 +1,18 @@
 package main
 
 import (
+	"encoding/json"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io/ioutil"
+	"math"
+	"path"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/iancoleman/strcase"
 	log "github.com/sirupsen/logrus"
@@ -13,6 +20,9 @@ import (
 	"gitlab.vmassive.ru/wand/generator"
 	"gitlab.vmassive.ru/wand/gocall"
 	"gitlab.vmassive.ru/wand/js"
+	"gitlab.vmassive.ru/wand/native"
+	"gitlab.vmassive.ru/wand/rules"
+	"gitlab.vmassive.ru/wand/schema"
 )
 
 func restoreCommentForType(commentMap *ast.CommentMap, fileSet *token.FileSet, typeSpec *ast.TypeSpec) {
@@ -36,208 +46,729 @@ func restoreCommentForType(commentMap *ast.CommentMap, fileSet *token.FileSet, t
 	typeSpec.Doc = &ast.CommentGroup{List: *list}
 }
 
-// ParseFile - Parse file
-func Parse(codeList *generator.CodeList) error {
+// Parse - parse source and generate the code for the outputs which inputs were changed
+func Parse(codeList *generator.CodeList, cache *generator.Cache) error {
+	started := time.Now()
+
+	stats, err := parseSource(codeList, cache)
+	if err != nil {
+		return err
+	}
+
+	apiFingerprint := generator.Fingerprint(stats.apiFingerprints)
+	if !cache.Changed("api", apiFingerprint) {
+		log.Printf("parsed %d files (%d from cache), no exported changes, skipping [%v]", stats.files, stats.cached, time.Since(started))
+		return nil
+	}
+
+	generated, err := generate(codeList, generator.NewDiskSink(), cache)
+	if err != nil {
+		return err
+	}
+
+	cache.Generated("api", apiFingerprint)
+
+	log.Printf("parsed %d files (%d from cache), generated %s [%v]", stats.files, stats.cached, strings.Join(generated, ", "), time.Since(started))
+	return nil
+}
+
+// ParseSource - fill code list with exported declarations of the source package
+func ParseSource(codeList *generator.CodeList) error {
+	_, err := parseSource(codeList, nil)
+	return err
+}
+
+type parseStats struct {
+	files           int
+	cached          int
+	apiFingerprints []string
+}
+
+func parseSource(codeList *generator.CodeList, cache *generator.Cache) (parseStats, error) {
+	stats := parseStats{}
+
 	src := codeList.PathMap.Source
 	log.Printf("parsing files in %s", src)
 
-	fset := token.NewFileSet() // positions are relative to fset
-
-	pkgs, err := parser.ParseDir(fset, src, nil, parser.ParseComments|parser.AllErrors)
+	files, err := sourceFiles(src)
 	if err != nil {
 		log.Errorf("parse file error %s : %v", src, err)
-		return err
+		return stats, err
 	}
 
-	oldState := *codeList
+	exports, err := newExportControl(codeList.Config)
+	if err != nil {
+		return stats, err
+	}
+
+	codeList.Enums = make([]generator.ExportedEnum, 0, len(codeList.Enums)+8)
 	codeList.Functions = make([]generator.FunctionData, 0, len(codeList.Functions)+8)
 	codeList.Structures = make([]generator.ExportedStucture, 0, len(codeList.Functions)+8)
 	codeList.Pure = make([]generator.FunctionData, 0, len(codeList.Functions)+8)
-	packageName := "unknown"
+	codeList.Skipped = make([]generator.SkippedFunction, 0)
+	packageNames := make([]string, 0, 1)
+	constants := make([]generator.EnumValue, 0)
+	diagnostics := make([]generator.Diagnostic, 0)
+
+	used := make(map[string]bool)
+	for _, name := range files {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			log.Errorf("read file error %s : %v", name, err)
+			return stats, err
+		}
 
-	for name, pkg := range pkgs {
-		packageName = name
+		hash := generator.ContentHash(data)
+		used[hash] = true
+		stats.files++
 
-		for name, file := range pkg.Files {
+		declarations := cache.File(hash)
+		if declarations != nil {
+			stats.cached++
+		} else {
 			log.Printf("file %s", name)
-			cmap := ast.NewCommentMap(fset, file, file.Comments)
-			file.Comments = cmap.Comments()
+			declarations, err = parseFile(name, data, exports)
+			if err != nil {
+				log.Errorf("parse file error %s : %v", name, err)
+				return stats, err
+			}
 
-			ast.Inspect(file, func(n ast.Node) bool {
-				switch x := n.(type) {
-				case *ast.Package:
-					packageName = x.Name
+			cache.StoreFile(hash, declarations)
+		}
 
-				case *ast.TypeSpec:
-					restoreCommentForType(&cmap, fset, x)
-					createType(codeList, x)
+		codeList.Enums = append(codeList.Enums, declarations.Enums...)
+		constants = append(constants, declarations.Constants...)
+		codeList.Structures = append(codeList.Structures, declarations.Structures...)
+		codeList.Functions = append(codeList.Functions, declarations.Functions...)
+		codeList.Pure = append(codeList.Pure, declarations.Pure...)
+		codeList.Skipped = append(codeList.Skipped, declarations.Skipped...)
+		packageNames = append(packageNames, declarations.PackageName)
+		diagnostics = append(diagnostics, declarations.Diagnostics...)
+		stats.apiFingerprints = append(stats.apiFingerprints, declarations.ApiFingerprint())
+	}
 
-				case *ast.FuncDecl:
-					createFuction(codeList, x)
-				}
+	cache.Prune(used)
 
-				return true
-			})
-		}
+	codeList.PackageName = "unknown"
+	if len(packageNames) > 0 {
+		sort.Strings(packageNames)
+		codeList.PackageName = packageNames[len(packageNames)-1]
 	}
 
-	codeList.PackageName = packageName
+	codeList.Sort()
+	codeList.ResolveEnums(constants)
 
-	if hasChanges(codeList, &oldState) {
-		jsGen := js.New(codeList.PathMap.Js, packageName)
-		jsGen.CreateCode(codeList)
+	generator.SortDiagnostics(diagnostics)
+	codeList.Diagnostics = diagnostics
 
-		goGen := gocall.New(codeList.PathMap.Target, packageName)
-		goGen.CreateCode(codeList)
-	} else {
-		log.Printf("no changes, skipping")
+	return stats, reportDiagnostics(codeList)
+}
+
+// parseFile - exported declarations of one source file
+func parseFile(name string, data []byte, exports *exportControl) (*generator.FileDeclarations, error) {
+	fset := token.NewFileSet() // positions are relative to fset
+
+	file, err := parser.ParseFile(fset, name, data, parser.ParseComments|parser.AllErrors)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
-}
+	fileList := &generator.CodeList{}
+	constants := make([]generator.EnumValue, 0)
+	diag := newDiagnostics(fset)
+	cmap := ast.NewCommentMap(fset, file, file.Comments)
+	file.Comments = cmap.Comments()
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.TypeSpec:
+			restoreCommentForType(&cmap, fset, x)
+			createType(fileList, x, diag)
+
+		case *ast.FuncDecl:
+			createFuction(fileList, x, diag, exports)
+
+		case *ast.GenDecl:
+			if x.Tok == token.CONST {
+				constants = append(constants, createConstants(x)...)
+			}
+		}
 
-func hasChanges(newState *generator.CodeList, oldState *generator.CodeList) bool {
-	if len(newState.Functions) != len(oldState.Functions) {
 		return true
+	})
+
+	return &generator.FileDeclarations{
+		PackageName: file.Name.Name,
+		Enums:       fileList.Enums,
+		Constants:   constants,
+		Structures:  fileList.Structures,
+		Functions:   fileList.Functions,
+		Pure:        fileList.Pure,
+		Skipped:     fileList.Skipped,
+		Diagnostics: diag.list,
+	}, nil
+}
+
+// Generate - create go and js code for the parsed source
+func Generate(codeList *generator.CodeList, sink generator.Sink) error {
+	_, err := generate(codeList, sink, nil)
+	return err
+}
+
+// generate - run generators which inputs were changed since they were cached
+func generate(codeList *generator.CodeList, sink generator.Sink, cache *generator.Cache) ([]string, error) {
+	generated := make([]string, 0, 4)
+
+	templates, err := generator.NewTemplates(codeList.Config.Templates)
+	if err != nil {
+		return generated, err
 	}
 
-	if len(newState.Structures) != len(oldState.Structures) {
-		return true
+	outputs := []struct {
+		name        string
+		gen         generator.Generator
+		fingerprint string
+	}{
+		{
+			name: "js",
+			gen:  js.New(codeList.PathMap.Js, codeList.PackageName, templates),
+			fingerprint: generator.Fingerprint(
+				templates.Fingerprint(),
+				codeList.PackageName, codeList.Dev, codeList.Port, codeList.Bridge,
+				codeList.Functions, codeList.Pure, codeList.Structures, codeList.Enums,
+			),
+		},
+		{
+			name: "go",
+			gen:  gocall.New(codeList.PathMap.Target, codeList.PackageName, templates),
+			fingerprint: generator.Fingerprint(
+				templates.Fingerprint(),
+				codeList.Package, codeList.PackageName, codeList.SourcePackage, codeList.Dev, codeList.Port, codeList.Bridge,
+				codeList.Functions, codeList.Pure,
+			),
+		},
+		{
+			name: "schema",
+			gen:  schema.New(codeList.PathMap.Js, codeList.PackageName),
+			fingerprint: generator.Fingerprint(
+				codeList.PackageName,
+				codeList.Functions, codeList.Pure, codeList.Structures, codeList.Enums,
+			),
+		},
 	}
 
-	if len(newState.Pure) != len(oldState.Pure) {
-		return true
+	if codeList.Config.Js.Mocks {
+		outputs = append(outputs, struct {
+			name        string
+			gen         generator.Generator
+			fingerprint string
+		}{
+			name: "mocks",
+			gen:  js.NewMocks(codeList.PathMap.Js, codeList.PackageName, templates),
+			fingerprint: generator.Fingerprint(
+				templates.Fingerprint(),
+				codeList.PackageName, codeList.Functions, codeList.Pure, codeList.Structures, codeList.Enums,
+			),
+		})
 	}
 
-	if reflect.DeepEqual(newState.Functions, oldState.Functions) &&
-		reflect.DeepEqual(newState.Pure, oldState.Pure) &&
-		reflect.DeepEqual(newState.Structures, oldState.Structures) {
-		return true
+	if !codeList.Dev {
+		outputs = append(outputs, struct {
+			name        string
+			gen         generator.Generator
+			fingerprint string
+		}{
+			name: "native",
+			gen:  native.New(codeList.Config.Native, codeList.Config.Wrapper.Package, templates),
+			fingerprint: generator.Fingerprint(
+				templates.Fingerprint(),
+				codeList.Config.Native, codeList.Config.Wrapper.Package,
+			),
+		})
 	}
 
-	return false
+	for _, output := range outputs {
+		if !cache.Changed(output.name, output.fingerprint) {
+			continue
+		}
+
+		err = output.gen.CreateCode(codeList, sink)
+		if err != nil {
+			return generated, err
+		}
+
+		cache.Generated(output.name, output.fingerprint)
+		generated = append(generated, output.name)
+	}
+
+	return generated, nil
 }
 
-func createFunctionParameters(funcDecl *ast.FuncDecl) (*generator.FunctionData, *generator.FunctionData) {
-	comments := getComments(funcDecl.Doc)
-	comments, subscription := getSubriptionAnnotatedType(comments)
-	comments, returnType := getCallbackAnnotatedType(comments)
+// sourceFiles - sorted go files of the source package without tests
+func sourceFiles(src string) ([]string, error) {
+	infos, err := ioutil.ReadDir(src)
+	if err != nil {
+		return nil, err
+	}
 
-	if subscription == nil && returnType == "any" {
-		return nil, &generator.FunctionData{
-			Subscription: subscription,
-			Comments:     comments,
-			ReturnType:   returnType,
-			Name:         funcDecl.Name.Name,
-			Params:       funcDecl.Type.Params,
-			CallName:     strcase.ToLowerCamel(funcDecl.Name.Name),
+	files := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() && path.Ext(info.Name()) == ".go" && !strings.HasSuffix(info.Name(), "_test.go") {
+			files = append(files, path.Join(src, info.Name()))
 		}
 	}
 
-	return &generator.FunctionData{
+	sort.Strings(files)
+	return files, nil
+}
+
+func createFunctionParameters(funcDecl *ast.FuncDecl, diag *diagnostics) (*generator.FunctionData, *generator.FunctionData) {
+	comments, annotations := parseAnnotations(diag, funcDecl.Doc)
+
+	var subscription *string
+	if annotation := findAnnotation(annotations, "subscription"); annotation != nil {
+		value := annotation.Value
+		subscription = &value
+	}
+
+	returnType := "any"
+	if annotation := findAnnotation(annotations, "callback"); annotation != nil {
+		returnType = annotation.Value
+	}
+
+	function := &generator.FunctionData{
 		Subscription: subscription,
 		Comments:     comments,
 		ReturnType:   returnType,
 		Name:         funcDecl.Name.Name,
-		Params:       funcDecl.Type.Params,
+		Params:       createFields(funcDecl.Type.Params, true, diag),
+		Results:      createFields(funcDecl.Type.Results, false, diag),
+		Annotation:   annotations,
 		CallName:     strcase.ToLowerCamel(funcDecl.Name.Name),
-	}, nil
-}
+	}
+
+	addParameterAnnotations(function)
+	addParameterRules(function, funcDecl.Name.Pos(), diag)
+	addParameterDefaults(function, funcDecl.Name.Pos(), diag)
+	addStream(function, funcDecl, diag)
 
-func getCallbackAnnotatedType(comments []string) ([]string, string) {
-	if comments == nil || len(comments) == 0 {
-		return comments, "any"
+	if subscription == nil && returnType == "any" && function.Stream == nil {
+		return nil, function
 	}
 
-	lastString := comments[len(comments)-1]
+	return function, nil
+}
 
-	if strings.HasPrefix(lastString, "@callback:") {
-		callbackType := strings.TrimPrefix(lastString, "@callback:")
-		otherComments := comments[0 : len(comments)-1]
-		return otherComments, strings.TrimSpace(callbackType)
+// addStream - item type of streaming function, of "@stream: T" annotation or of <-chan T result
+func addStream(function *generator.FunctionData, funcDecl *ast.FuncDecl, diag *diagnostics) {
+	if annotation := findAnnotation(function.Annotation, "stream"); annotation != nil {
+		function.Stream = generator.ParseTypeString(annotation.Value)
+		if function.Stream == nil {
+			diag.warnf(funcDecl.Name.Pos(), generator.DiagWrongAnnotation, "%s: @stream: %s is not a go type", function.Name, annotation.Value)
+		}
 	}
 
-	return comments, "any"
+	if results := funcDecl.Type.Results; results != nil && len(results.List) > 0 {
+		if channel, ok := results.List[0].Type.(*ast.ChanType); ok && channel.Dir != ast.SEND {
+			function.StreamChannel = true
+			if function.Stream == nil {
+				function.Stream = generator.ParseType(channel.Value)
+			}
+		}
+	}
 }
 
-var annotationList = []string{
-	"subscription",
-	"get",
-	"update",
-	"callback",
+// addParameterRules - rules of @validate annotations, like "@validate: id required,min=3"
+func addParameterRules(function *generator.FunctionData, pos token.Pos, diag *diagnostics) {
+	for _, annotation := range function.Annotation {
+		if annotation.Name != "validate" {
+			continue
+		}
+
+		// rules may have spaces, like "oneof=active blocked"
+		fields := strings.SplitN(strings.TrimSpace(annotation.Value), " ", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+			diag.warnf(pos, generator.DiagWrongAnnotation, "%s: wrong @validate annotation \"%s\", expected \"@validate: param rules\"", function.Name, annotation.Value)
+			continue
+		}
+
+		found := false
+		for i, param := range function.Params {
+			if param.Name == fields[0] {
+				function.Params[i].Rules = append(function.Params[i].Rules, parseRules(function.Name, strings.TrimSpace(fields[1]), pos, diag)...)
+				found = true
+			}
+		}
+
+		if !found {
+			diag.warnf(pos, generator.DiagUnknownParameter, "%s: @validate for unknown parameter %s", function.Name, fields[0])
+		}
+	}
 }
 
-func getAnnotation(comments []string) ([]string, *generator.Annotation) {
-	if comments == nil || len(comments) == 0 {
-		return comments, nil
+// addParameterDefaults - values of @default annotations, like "@default: limit 10", and optional trailing parameters
+func addParameterDefaults(function *generator.FunctionData, pos token.Pos, diag *diagnostics) {
+	for _, annotation := range function.Annotation {
+		if annotation.Name != "default" {
+			continue
+		}
+
+		fields := strings.SplitN(annotation.Value, " ", 2)
+		if len(fields) != 2 {
+			diag.warnf(pos, generator.DiagWrongAnnotation, "%s: wrong @default annotation \"%s\", expected \"@default: param value\"", function.Name, annotation.Value)
+			continue
+		}
+
+		value := strings.TrimSpace(fields[1])
+		if !json.Valid([]byte(value)) {
+			// not a json value, take it as a string
+			value = strconv.Quote(value)
+		}
+
+		found := false
+		for i, param := range function.Params {
+			if param.Name != fields[0] {
+				continue
+			}
+
+			found = true
+			if !defaultFits(param.Type, value) {
+				diag.warnf(pos, generator.DiagWrongAnnotation, "%s: @default %s does not fit %s parameter %s", function.Name, value, generator.GoType(param.Type, ""), param.Name)
+				continue
+			}
+
+			function.Params[i].Default = value
+		}
+
+		if !found {
+			diag.warnf(pos, generator.DiagUnknownParameter, "%s: @default for unknown parameter %s", function.Name, fields[0])
+		}
 	}
 
-	lastString := comments[len(comments)-1]
+	for i := len(function.Params) - 1; i >= 0; i-- {
+		param := function.Params[i]
+		switch {
+		case param.Default != "":
+		case param.Type.Kind == generator.KindPointer, param.Type.Kind == generator.KindSlice, param.Type.Kind == generator.KindMap:
+		default:
+			return
+		}
 
-	for _, annotationName := range annotationList {
-		keyword := "@" + annotationName + ":"
-		if strings.HasPrefix(lastString, keyword) {
+		function.Params[i].Optional = true
+	}
+}
 
-			callbackType := strings.TrimPrefix(lastString, keyword)
-			otherComments := comments[0 : len(comments)-1]
-			value := strings.TrimSpace(callbackType)
+// defaultFits - tells if json value of @default fits the type of the parameter, values of
+// named types are checked only by go when they are decoded
+func defaultFits(tp *generator.Type, text string) bool {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return false
+	}
 
-			annotation := generator.Annotation{
-				Name:  annotationName,
-				Value: value,
+	return jsonFits(tp, value)
+}
+
+func jsonFits(tp *generator.Type, value interface{}) bool {
+	switch tp.Kind {
+	case generator.KindBasic:
+		switch x := value.(type) {
+		case string:
+			return tp.Name == "string"
+		case bool:
+			return tp.Name == "bool"
+		case float64:
+			if strings.HasPrefix(tp.Name, "float") {
+				return true
 			}
 
-			return otherComments, &annotation
+			integer := x == math.Trunc(x)
+			if strings.HasPrefix(tp.Name, "uint") || tp.Name == "byte" {
+				return integer && x >= 0
+			}
+
+			return integer && (strings.HasPrefix(tp.Name, "int") || tp.Name == "rune")
+		}
+
+		return false
+
+	case generator.KindPointer:
+		return value == nil || jsonFits(tp.Elem, value)
+
+	case generator.KindSlice:
+		if generator.IsBytes(tp) {
+			_, ok := value.(string)
+			return ok || value == nil
+		}
+
+		items, ok := value.([]interface{})
+		for _, item := range items {
+			ok = ok && jsonFits(tp.Elem, item)
 		}
+
+		return ok || value == nil
+
+	case generator.KindMap:
+		_, ok := value.(map[string]interface{})
+		return ok || value == nil
 	}
 
-	return comments, nil
+	return true
 }
 
-func GetAnnotations(comments []string) ([]string, []generator.Annotation) {
-	annotations := make([]generator.Annotation, 0, 2)
-	outList, annotation := getAnnotation(comments)
-	if annotation != nil {
-		outList, annotationList := GetAnnotations(outList)
-		list := append(annotationList, *annotation)
+// parseRules - rules of validate tag, unknown rules are skipped
+func parseRules(name string, text string, pos token.Pos, diag *diagnostics) []rules.Rule {
+	list := make([]rules.Rule, 0)
+
+	for _, rule := range rules.Parse(text) {
+		known := false
+		for _, knownRule := range rules.Known {
+			known = known || knownRule == rule.Name
+		}
+
+		if !known {
+			diag.warnf(pos, generator.DiagUnknownRule, "%s: unknown validation rule %s", name, rule.Name)
+			continue
+		}
 
-		return outList, list
+		list = append(list, rule)
 	}
 
-	return outList, annotations
+	return list
 }
 
-func getSubriptionAnnotatedType(comments []string) ([]string, *string) {
-	if comments == nil || len(comments) == 0 {
-		return comments, nil
+// createFields - fields of the list, callbacks of goapi are skipped for parameters
+func createFields(list *ast.FieldList, skipCallbacks bool, diag *diagnostics) []generator.Field {
+	fields := make([]generator.Field, 0)
+	if list == nil {
+		return fields
+	}
+
+	for _, field := range list.List {
+		tp := generator.ParseType(field.Type)
+		if skipCallbacks && generator.IsCallback(tp) {
+			continue
+		}
+
+		comments, annotations := parseAnnotations(diag, field.Doc)
+
+		if len(field.Names) == 0 {
+			fields = append(fields, generator.Field{
+				Type:       tp,
+				Comments:   comments,
+				Annotation: annotations,
+			})
+		}
+
+		for _, name := range field.Names {
+			fields = append(fields, generator.Field{
+				Name:       name.Name,
+				JSONName:   name.Name,
+				Type:       tp,
+				Comments:   comments,
+				Annotation: annotations,
+			})
+		}
 	}
 
-	lastString := comments[len(comments)-1]
+	return fields
+}
+
+// createStructureFields - fields of the struct as they are marshaled to json
+func createStructureFields(list *ast.FieldList, structName string, typeParams map[string]bool, diag *diagnostics) []generator.Field {
+	fields := make([]generator.Field, 0)
+
+	for _, field := range list.List {
+		tag := ""
+		if field.Tag != nil {
+			tag = field.Tag.Value
+		}
+
+		structTag := reflect.StructTag(strings.Trim(tag, "`"))
+		jsonTag := structTag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		comments, annotations := parseAnnotations(diag, field.Doc)
+		tp := generator.ParseType(field.Type)
 
-	if strings.HasPrefix(lastString, "@subscription:") {
-		callbackType := strings.TrimPrefix(lastString, "@subscription:")
-		otherComments := comments[0 : len(comments)-1]
-		subName := strings.TrimSpace(callbackType)
-		return otherComments, &subName
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			checkType(diag, field.Type, "field "+structName+"."+name.Name, typeParams)
+
+			fields = append(fields, generator.Field{
+				Name:       name.Name,
+				JSONName:   generator.JsonName(name.Name, tag),
+				Type:       tp,
+				Optional:   strings.Contains(jsonTag, ",omitempty"),
+				Tag:        tag,
+				Comments:   comments,
+				Annotation: annotations,
+				Rules:      parseRules(name.Name, structTag.Get("validate"), name.Pos(), diag),
+			})
+		}
 	}
 
-	return comments, nil
+	return fields
 }
 
-func createFuction(codeList *generator.CodeList, funcDecl *ast.FuncDecl) {
-	if !funcDecl.Name.IsExported() {
+// createConstants - typed exported constants of the const declaration, values of enums
+func createConstants(genDecl *ast.GenDecl) []generator.EnumValue {
+	values := make([]generator.EnumValue, 0)
+
+	typeName := ""
+	var lastValues []ast.Expr
+
+	for index, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		// constants without type and values repeat the previous ones
+		if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			typeName = ""
+			if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+				typeName = ident.Name
+			}
+
+			lastValues = valueSpec.Values
+		}
+
+		if typeName == "" {
+			continue
+		}
+
+		for i, name := range valueSpec.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			value := ""
+			if i < len(lastValues) {
+				value = constantValue(lastValues[i], index)
+			}
+
+			values = append(values, generator.EnumValue{
+				Name:  name.Name,
+				Type:  typeName,
+				Value: value,
+			})
+		}
+	}
+
+	return values
+}
+
+// constantValue - json literal of the constant expression, iota is the index of the spec
+func constantValue(expr ast.Expr, iota int) string {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		return generator.ConstantValue(x)
+
+	case *ast.Ident:
+		if x.Name == "iota" {
+			return strconv.Itoa(iota)
+		}
+	}
+
+	return ""
+}
+
+func createFuction(codeList *generator.CodeList, funcDecl *ast.FuncDecl, diag *diagnostics, exports *exportControl) {
+	if !funcDecl.Name.IsExported() || funcDecl.Recv != nil {
+		return
+	}
+
+	function, pure := createFunctionParameters(funcDecl, diag)
+
+	data := function
+	if data == nil {
+		data = pure
+	}
+
+	if reason := exports.reason(*data); reason != "" {
+		codeList.Skipped = append(codeList.Skipped, generator.SkippedFunction{
+			Name:     data.Name,
+			Kind:     data.Kind(),
+			Reason:   reason,
+			Position: diag.fset.Position(funcDecl.Name.Pos()),
+		})
 		return
 	}
 
-	function, pure := createFunctionParameters(funcDecl)
 	if function != nil {
+		checkFunction(funcDecl, *function, diag)
 		codeList.AddFunction(*function)
 	} else {
+		checkFunction(funcDecl, *pure, diag)
 		codeList.AddPureFunction(*pure)
 	}
 }
 
-func createType(codeList *generator.CodeList, typeSpec *ast.TypeSpec) {
+// checkFunction reports annotations and types of the function which can not be bridged
+func checkFunction(funcDecl *ast.FuncDecl, function generator.FunctionData, diag *diagnostics) {
+	callbacks := make([]string, 0, 1)
+	params := funcDecl.Type.Params.List
+	for index, field := range params {
+		tp := generator.ParseType(field.Type)
+		if !generator.IsCallback(tp) {
+			checkType(diag, field.Type, "parameter of "+function.Name, nil)
+			continue
+		}
+
+		callbacks = append(callbacks, tp.Name)
+		if index != len(params)-1 || len(field.Names) > 1 {
+			diag.errorf(field.Pos(), generator.DiagMissingCallback, "%s: goapi.%s must be the last parameter", function.Name, tp.Name)
+		}
+	}
+
+	switch {
+	case function.Stream != nil && (function.Subscription != nil || function.ReturnType != "any"):
+		diag.errorf(funcDecl.Name.Pos(), generator.DiagMissingCallback, "%s: streaming function can't be @callback or @subscription", function.Name)
+
+	case function.Stream != nil && !function.StreamChannel && !hasString(callbacks, "Stream"):
+		diag.errorf(funcDecl.Name.Pos(), generator.DiagMissingCallback, "%s: @stream function has no goapi.Stream parameter and doesn't return <-chan", function.Name)
+
+	case function.Stream == nil && hasString(callbacks, "Stream"):
+		diag.errorf(funcDecl.Name.Pos(), generator.DiagMissingCallback, "%s: function with goapi.Stream has no @stream annotation", function.Name)
+
+	case function.Stream != nil:
+		if function.StreamChannel {
+			checkType(diag, funcDecl.Type.Results.List[0].Type.(*ast.ChanType).Value, "stream of "+function.Name, nil)
+		}
+
+	case function.Subscription != nil && !hasString(callbacks, "EventCallback"):
+		diag.errorf(funcDecl.Name.Pos(), generator.DiagMissingCallback, "%s: @subscription function has no goapi.EventCallback parameter", function.Name)
+
+	case function.Subscription == nil && function.ReturnType != "any" && !hasString(callbacks, "JsCallback"):
+		diag.errorf(funcDecl.Name.Pos(), generator.DiagMissingCallback, "%s: @callback function has no goapi.JsCallback parameter", function.Name)
+
+	case function.Subscription == nil && function.ReturnType == "any" && len(callbacks) > 0:
+		diag.warnf(funcDecl.Name.Pos(), generator.DiagMissingCallback, "%s: function with goapi.%s has no @callback or @subscription annotation", function.Name, callbacks[0])
+
+	case function.Subscription == nil && function.ReturnType == "any" && funcDecl.Type.Results != nil:
+		for _, field := range funcDecl.Type.Results.List {
+			checkType(diag, field.Type, "result of "+function.Name, nil)
+		}
+	}
+}
+
+func hasString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func createType(codeList *generator.CodeList, typeSpec *ast.TypeSpec, diag *diagnostics) {
 	if !typeSpec.Name.IsExported() {
 		log.Warnf("skipping %s", typeSpec.Name.Name)
 		return
@@ -245,33 +776,52 @@ func createType(codeList *generator.CodeList, typeSpec *ast.TypeSpec) {
 
 	switch x := typeSpec.Type.(type) {
 	case *ast.StructType:
-		strct := createStructure(x, typeSpec.Name.Name, typeSpec.Doc)
+		typeParams := createTypeParams(typeSpec.TypeParams)
+		strct := createStructure(x, typeSpec.Name.Name, typeSpec.Doc, typeParams, diag)
+		strct.TypeParams = typeParams
+		for _, name := range []string{"export", "ignore"} {
+			if findAnnotation(strct.Annotation, name) != nil {
+				diag.warnf(typeSpec.Name.Pos(), generator.DiagWrongAnnotation, "%s: //wand:%s applies only to functions", strct.Name, name)
+			}
+		}
 		codeList.AddStructure(strct)
 
-	case *ast.InterfaceType:
-	}
-}
-
-func getComments(commGroup *ast.CommentGroup) []string {
-	comments := make([]string, 0, 6)
-	if commGroup != nil {
-		for _, comment := range commGroup.List {
-			comments = append(comments, strings.TrimLeft(strings.TrimPrefix(comment.Text, "//"), " "))
+	case *ast.Ident:
+		if generator.IsBasic(x.Name) {
+			comments, _ := parseAnnotations(diag, typeSpec.Doc)
+			codeList.AddEnum(generator.ExportedEnum{
+				Comments: comments,
+				Name:     typeSpec.Name.Name,
+				Type:     generator.ParseType(x),
+			})
 		}
-	}
 
-	return comments
+	case *ast.InterfaceType:
+	}
 }
 
-func createStructure(structType *ast.StructType, name string, commGroup *ast.CommentGroup) generator.ExportedStucture {
-	comments := getComments(commGroup)
-
-	comments, annotations := GetAnnotations(comments)
+func createStructure(structType *ast.StructType, name string, commGroup *ast.CommentGroup, typeParams []string, diag *diagnostics) generator.ExportedStucture {
+	comments, annotations := parseAnnotations(diag, commGroup)
 
 	return generator.ExportedStucture{
 		Comments:   comments,
 		Name:       name,
-		Field:      structType.Fields,
+		Field:      createStructureFields(structType.Fields, name, stringSet(typeParams), diag),
 		Annotation: annotations,
 	}
 }
+
+func createTypeParams(list *ast.FieldList) []string {
+	if list == nil {
+		return nil
+	}
+
+	params := make([]string, 0, len(list.List))
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			params = append(params, name.Name)
+		}
+	}
+
+	return params
+}
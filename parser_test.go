@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.vmassive.ru/wand/generator"
+	"gitlab.vmassive.ru/wand/golden"
+)
+
+func TestGenerateChangedOutputs(t *testing.T) {
+	codeList := golden.CodeList("golden/testdata", "users", false)
+	if err := ParseSource(codeList); err != nil {
+		t.Fatalf("ParseSource() failed: %v", err)
+	}
+
+	cache := generator.NewCache()
+	generated, err := generate(codeList, generator.NewMemorySink(), cache)
+	if err != nil {
+		t.Fatalf("generate() failed: %v", err)
+	}
+
+	expected := []string{"js", "go", "schema", "mocks", "native"}
+	if !reflect.DeepEqual(generated, expected) {
+		t.Errorf("first generate() = %v, want %v", generated, expected)
+	}
+
+	generated, _ = generate(codeList, generator.NewMemorySink(), cache)
+	if len(generated) != 0 {
+		t.Errorf("generate() without changes = %v, want nothing", generated)
+	}
+
+	codeList.Pure[0].Comments = append(codeList.Pure[0].Comments, "changed")
+	generated, _ = generate(codeList, generator.NewMemorySink(), cache)
+
+	expected = []string{"js", "go", "schema", "mocks"}
+	if !reflect.DeepEqual(generated, expected) {
+		t.Errorf("generate() of changed pure function = %v, want %v", generated, expected)
+	}
+
+	codeList.Enums[0].Comments = append(codeList.Enums[0].Comments, "changed")
+	generated, _ = generate(codeList, generator.NewMemorySink(), cache)
+
+	expected = []string{"js", "schema", "mocks"}
+	if !reflect.DeepEqual(generated, expected) {
+		t.Errorf("generate() of changed enums = %v, want %v", generated, expected)
+	}
+}
+
+func TestDefaultFits(t *testing.T) {
+	tests := []struct {
+		tp    string
+		value string
+		fits  bool
+	}{
+		{"int", "10", true},
+		{"int", `"ten"`, false},
+		{"int", "1.5", false},
+		{"uint8", "-1", false},
+		{"float32", "1.5", true},
+		{"string", `"name"`, true},
+		{"string", "10", false},
+		{"bool", "true", true},
+		{"bool", "null", false},
+		{"*int", "null", true},
+		{"[]int", "[1, 2]", true},
+		{"[]int", `["a"]`, false},
+		{"[]byte", `"AQI="`, true},
+		{"map[string]int", `{"a": 1}`, true},
+		{"map[string]int", "[]", false},
+		{"Status", `"active"`, true},
+	}
+
+	for _, test := range tests {
+		if fits := defaultFits(generator.ParseTypeString(test.tp), test.value); fits != test.fits {
+			t.Errorf("defaultFits(%s, %s) = %v, want %v", test.tp, test.value, fits, test.fits)
+		}
+	}
+}
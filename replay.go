@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.vmassive.ru/wand/config"
+	"gitlab.vmassive.ru/wand/goapi/remgo"
+)
+
+// recordSession makes the dev server record its session, the server inherits the environment
+func recordSession(fileName string) error {
+	if fileName == "" {
+		return nil
+	}
+
+	fullName, err := filepath.Abs(fileName)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("recording session to %s", fullName)
+	return os.Setenv(remgo.RecordEnv, fullName)
+}
+
+func replayApplication(configName string, session string, match string, timing string) error {
+	configuration, err := config.ReadConfig(configName)
+	if err != nil {
+		return err
+	}
+
+	options := remgo.ReplayOptions{Match: remgo.Match(match), Timing: remgo.Timing(timing)}
+	if options.Match != remgo.MatchArgs && options.Match != remgo.MatchMethod {
+		return fmt.Errorf("unknown match %s, use %s or %s", match, remgo.MatchArgs, remgo.MatchMethod)
+	}
+
+	if options.Timing != remgo.TimingRealTime && options.Timing != remgo.TimingImmediate {
+		return fmt.Errorf("unknown timing %s, use %s or %s", timing, remgo.TimingRealTime, remgo.TimingImmediate)
+	}
+
+	records, err := remgo.ReadSession(session)
+	if err != nil {
+		return err
+	}
+
+	port := configuration.Wrapper.Port
+	if port == 0 {
+		port = 9009
+	}
+
+	replayer := remgo.NewReplayer(records, options)
+	http.HandleFunc("/ws", replayer.ServeWs)
+
+	log.Printf("replaying %d records of %s on port %d", len(records), session, port)
+	return http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", port), nil)
+}
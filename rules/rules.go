@@ -0,0 +1,41 @@
+// Package rules describes validation rules shared by the generator and the validator of goapi
+package rules
+
+import "strings"
+
+// Known rules understood by the validator of goapi, the same rules are checked by generated js
+//
+//	required    value is present and not empty (nil, empty string or slice)
+//	min=N       number is at least N, string, slice or map has at least N items
+//	max=N       number is at most N, string, slice or map has at most N items
+//	len=N       number is N, string, slice or map has exactly N items
+//	email       string is an email
+//	oneof=A B   value is one of space separated values
+var Known = []string{"required", "min", "max", "len", "email", "oneof"}
+
+// Rule validation rule like min=1
+type Rule struct {
+	Name  string
+	Param string
+}
+
+// Parse parses rules of validate tag, like "required,min=1,max=100"
+func Parse(text string) []Rule {
+	rules := make([]Rule, 0)
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rule := Rule{Name: item}
+		if index := strings.Index(item, "="); index >= 0 {
+			rule.Name = strings.TrimSpace(item[:index])
+			rule.Param = strings.TrimSpace(item[index+1:])
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
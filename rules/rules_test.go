@@ -0,0 +1,22 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string][]Rule{
+		"":                        {},
+		"required":                {{Name: "required"}},
+		"required, min=1,max=100": {{Name: "required"}, {Name: "min", Param: "1"}, {Name: "max", Param: "100"}},
+		"oneof=name email":        {{Name: "oneof", Param: "name email"}},
+		" len = 3 ,,":             {{Name: "len", Param: "3"}},
+	}
+
+	for text, expected := range tests {
+		if parsed := Parse(text); !reflect.DeepEqual(parsed, expected) {
+			t.Errorf("Parse(%q) = %v, want %v", text, parsed, expected)
+		}
+	}
+}
@@ -0,0 +1,477 @@
+package schema
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.vmassive.ru/wand/generator"
+)
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema JSON Schema of a value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []json.RawMessage  `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// Param parameter of a function or subscription
+type Param struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Function function which can be called from js
+type Function struct {
+	// Name of the go function
+	Name string `json:"name"`
+	// CallName name of the js function
+	CallName    string  `json:"callName"`
+	Description string  `json:"description,omitempty"`
+	Params      []Param `json:"params"`
+	// Result schema of the value the call is resolved with
+	Result *Schema `json:"result,omitempty"`
+	// Pure is true for functions without annotations
+	Pure bool `json:"pure,omitempty"`
+	// Stream schema of the items of streaming function, js reads them as AsyncIterable
+	Stream *Schema `json:"stream,omitempty"`
+}
+
+// Subscription function which sends events to js
+type Subscription struct {
+	Name        string  `json:"name"`
+	CallName    string  `json:"callName"`
+	Description string  `json:"description,omitempty"`
+	Params      []Param `json:"params"`
+	// Event schema of the event payload
+	Event *Schema `json:"event"`
+}
+
+// Document description of everything the source package exposes to js
+type Document struct {
+	Schema        string             `json:"$schema"`
+	Title         string             `json:"title"`
+	Functions     []Function         `json:"functions"`
+	Subscriptions []Subscription     `json:"subscriptions"`
+	Definitions   map[string]*Schema `json:"definitions"`
+}
+
+type SchemaGenerator struct {
+	outDirectory string
+	packageName  string
+}
+
+func New(outDirectory string, packageName string) generator.Generator {
+	return &SchemaGenerator{
+		outDirectory: outDirectory,
+		packageName:  packageName,
+	}
+}
+
+// FileName name of the schema file for the package
+func FileName(outDirectory string, packageName string) string {
+	return path.Join(outDirectory, packageName+".schema.json")
+}
+
+func (gen SchemaGenerator) CreateCode(source *generator.CodeList, sink generator.Sink) error {
+	outFile := FileName(gen.outDirectory, gen.packageName)
+	log.Printf("createing %s", outFile)
+
+	data, err := Marshal(Build(source))
+	if err != nil {
+		log.Errorf("failed to generate %s: %v", outFile, err)
+		return err
+	}
+
+	return sink.WriteFile(outFile, data)
+}
+
+// Marshal formats the document as indented json
+func Marshal(document *Document) ([]byte, error) {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Build describes the parsed source package
+func Build(source *generator.CodeList) *Document {
+	builder := newBuilder(source)
+
+	document := &Document{
+		Schema:        draft,
+		Title:         source.PackageName,
+		Functions:     make([]Function, 0, len(source.Functions)+len(source.Pure)),
+		Subscriptions: make([]Subscription, 0),
+		Definitions:   builder.definitions,
+	}
+
+	for _, enum := range source.Enums {
+		builder.definitions[enum.Name] = builder.enumSchema(enum)
+	}
+
+	for _, structure := range source.Structures {
+		if len(structure.TypeParams) == 0 {
+			builder.definitions[structure.Name] = builder.structSchema(structure, nil)
+		}
+	}
+
+	for _, function := range source.Functions {
+		if function.Subscription != nil {
+			document.Subscriptions = append(document.Subscriptions, Subscription{
+				Name:        function.Name,
+				CallName:    function.CallName,
+				Description: description(function.Comments),
+				Params:      builder.params(function.Params),
+				Event:       builder.annotationSchema(*function.Subscription),
+			})
+			continue
+		}
+
+		if function.Stream != nil {
+			document.Functions = append(document.Functions, Function{
+				Name:        function.Name,
+				CallName:    function.CallName,
+				Description: description(function.Comments),
+				Params:      builder.params(function.Params),
+				Stream:      builder.typeSchema(function.Stream, nil),
+			})
+			continue
+		}
+
+		document.Functions = append(document.Functions, Function{
+			Name:        function.Name,
+			CallName:    function.CallName,
+			Description: description(function.Comments),
+			Params:      builder.params(function.Params),
+			Result:      builder.annotationSchema(function.ReturnType),
+		})
+	}
+
+	for _, function := range source.Pure {
+		document.Functions = append(document.Functions, Function{
+			Name:        function.Name,
+			CallName:    function.CallName,
+			Description: description(function.Comments),
+			Params:      builder.params(function.Params),
+			Result:      builder.resultSchema(function.Results),
+			Pure:        true,
+		})
+	}
+
+	return document
+}
+
+func description(comments []string) string {
+	return strings.TrimSpace(strings.Join(comments, "\n"))
+}
+
+type builder struct {
+	structures  map[string]generator.ExportedStucture
+	enums       map[string]generator.ExportedEnum
+	definitions map[string]*Schema
+}
+
+func newBuilder(source *generator.CodeList) *builder {
+	b := &builder{
+		structures:  make(map[string]generator.ExportedStucture),
+		enums:       make(map[string]generator.ExportedEnum),
+		definitions: make(map[string]*Schema),
+	}
+
+	for _, structure := range source.Structures {
+		b.structures[structure.Name] = structure
+	}
+
+	for _, enum := range source.Enums {
+		b.enums[enum.Name] = enum
+	}
+
+	return b
+}
+
+func (b *builder) params(fields []generator.Field) []Param {
+	params := make([]Param, 0, len(fields))
+	for _, field := range fields {
+		params = append(params, Param{
+			Name:        field.Name,
+			Description: description(field.Comments),
+			Schema:      b.typeSchema(field.Type, nil),
+		})
+	}
+
+	return params
+}
+
+// resultSchema schema of the first result which is not an error
+func (b *builder) resultSchema(results []generator.Field) *Schema {
+	for _, result := range results {
+		if generator.IsError(result.Type) {
+			continue
+		}
+
+		return b.typeSchema(result.Type, nil)
+	}
+
+	return nil
+}
+
+func (b *builder) enumSchema(enum generator.ExportedEnum) *Schema {
+	schema := basicSchema(enum.Type.Name)
+	schema.Description = description(enum.Comments)
+
+	for _, value := range enum.Values {
+		if value.Value == "" {
+			schema.Enum = nil
+			break
+		}
+
+		schema.Enum = append(schema.Enum, json.RawMessage(value.Value))
+	}
+
+	return schema
+}
+
+// structSchema object schema of the structure, args are the types of type parameters
+func (b *builder) structSchema(structure generator.ExportedStucture, args map[string]*Schema) *Schema {
+	schema := &Schema{
+		Type:        "object",
+		Description: description(structure.Comments),
+		Properties:  make(map[string]*Schema),
+		Required:    make([]string, 0, len(structure.Field)),
+	}
+
+	for _, field := range structure.Field {
+		property := b.typeSchema(field.Type, args)
+		if comment := description(field.Comments); comment != "" {
+			property = withDescription(property, comment)
+		}
+
+		schema.Properties[field.JSONName] = property
+		if !field.Optional {
+			schema.Required = append(schema.Required, field.JSONName)
+		}
+	}
+
+	return schema
+}
+
+// withDescription copies the schema, references can not have siblings
+func withDescription(schema *Schema, text string) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AnyOf: []*Schema{schema}, Description: text}
+	}
+
+	described := *schema
+	described.Description = text
+	return &described
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/definitions/" + name}
+}
+
+func nullable(schema *Schema) *Schema {
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
+
+func basicSchema(name string) *Schema {
+	switch name {
+	case "string", "error":
+		return &Schema{Type: "string"}
+	case "bool", "boolean":
+		return &Schema{Type: "boolean"}
+	case "float32", "float64", "number":
+		return &Schema{Type: "number"}
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"byte", "rune":
+		return &Schema{Type: "integer"}
+	}
+
+	return &Schema{}
+}
+
+// bytesSchema markers byte slices are sent with, js gets them as Uint8Array
+func bytesSchema() *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: "bytes, Uint8Array in js",
+		Properties: map[string]*Schema{
+			"$base64": {Type: "string", ContentEncoding: "base64"},
+			"$binary": {Type: "integer", Description: "index of the attachment of binary frame"},
+			"$file":   {Type: "string", Format: "uri"},
+			"size":    {Type: "integer"},
+		},
+	}
+}
+
+func (b *builder) typeSchema(tp *generator.Type, args map[string]*Schema) *Schema {
+	if tp == nil {
+		return &Schema{}
+	}
+
+	switch tp.Kind {
+	case generator.KindBasic:
+		return basicSchema(tp.Name)
+
+	case generator.KindEnum:
+		return ref(tp.Name)
+
+	case generator.KindNamed:
+		return b.namedSchema(tp, args)
+
+	case generator.KindPointer:
+		return nullable(b.typeSchema(tp.Elem, args))
+
+	case generator.KindSlice:
+		if generator.IsBytes(tp) {
+			return bytesSchema()
+		}
+
+		return &Schema{Type: "array", Items: b.typeSchema(tp.Elem, args)}
+
+	case generator.KindMap:
+		return &Schema{Type: "object", AdditionalProperties: b.typeSchema(tp.Elem, args)}
+
+	case generator.KindGeneric:
+		argSchemas := make([]*Schema, 0, len(tp.Args))
+		for _, arg := range tp.Args {
+			argSchemas = append(argSchemas, b.typeSchema(arg, args))
+		}
+
+		return b.genericSchema(tp.Name, tp.Package, argSchemas)
+	}
+
+	return &Schema{}
+}
+
+func (b *builder) namedSchema(tp *generator.Type, args map[string]*Schema) *Schema {
+	if tp.Package == "" {
+		if arg, ok := args[tp.Name]; ok {
+			return arg
+		}
+
+		if _, ok := b.structures[tp.Name]; ok {
+			return ref(tp.Name)
+		}
+
+		if _, ok := b.enums[tp.Name]; ok {
+			return ref(tp.Name)
+		}
+	}
+
+	if tp.Package == "time" && tp.Name == "Time" {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	return &Schema{Description: generator.GoType(tp, "")}
+}
+
+// genericSchema reference to the definition of the generic structure instance, like Page[User]
+func (b *builder) genericSchema(name string, pkg string, args []*Schema) *Schema {
+	structure, ok := b.structures[name]
+	if pkg != "" || !ok || len(structure.TypeParams) != len(args) {
+		return &Schema{Description: name}
+	}
+
+	names := make([]string, 0, len(args))
+	typeArgs := make(map[string]*Schema)
+	for i, arg := range args {
+		names = append(names, schemaName(arg))
+		typeArgs[structure.TypeParams[i]] = arg
+	}
+
+	definition := name + "[" + strings.Join(names, ",") + "]"
+	if _, ok := b.definitions[definition]; !ok {
+		// reserve the name first, so recursive structures terminate
+		b.definitions[definition] = &Schema{}
+		b.definitions[definition] = b.structSchema(structure, typeArgs)
+	}
+
+	return ref(definition)
+}
+
+// schemaName short name of the schema used in names of generic instances
+func schemaName(schema *Schema) string {
+	switch {
+	case schema.Ref != "":
+		return strings.TrimPrefix(schema.Ref, "#/definitions/")
+	case schema.Items != nil:
+		return schemaName(schema.Items) + "[]"
+	case len(schema.AnyOf) == 2:
+		return "?" + schemaName(schema.AnyOf[0])
+	case schema.Type != "":
+		return schema.Type
+	}
+
+	return "any"
+}
+
+// annotationSchema schema of flow type of @callback or @subscription annotation, like User[] or Page<User>
+func (b *builder) annotationSchema(text string) *Schema {
+	text = strings.TrimSpace(text)
+
+	switch {
+	case text == "" || text == "any":
+		return &Schema{}
+
+	case strings.HasPrefix(text, "?"):
+		return nullable(b.annotationSchema(text[1:]))
+
+	case strings.HasSuffix(text, "[]"):
+		return &Schema{Type: "array", Items: b.annotationSchema(strings.TrimSuffix(text, "[]"))}
+
+	case strings.HasSuffix(text, ">") && strings.Contains(text, "<"):
+		start := strings.Index(text, "<")
+		args := make([]*Schema, 0)
+		for _, arg := range splitArgs(text[start+1 : len(text)-1]) {
+			args = append(args, b.annotationSchema(arg))
+		}
+
+		return b.genericSchema(text[:start], "", args)
+	}
+
+	if schema := basicSchema(text); schema.Type != "" {
+		return schema
+	}
+
+	return b.namedSchema(&generator.Type{Kind: generator.KindNamed, Name: text}, nil)
+}
+
+// splitArgs splits type arguments by commas which are not nested in other arguments
+func splitArgs(text string) []string {
+	args := make([]string, 0, 1)
+	depth := 0
+	start := 0
+
+	for i, char := range text {
+		switch char {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(args, text[start:])
+}